@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// Imports exchange rates from a rate file:
+//
+//	rateimport [-format ecb|csv] [-dry-run] [-config path] file
+//
+// Without -format, the format is inferred from the file extension.
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		log.Fatalf("run: %v", err)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rateimport", flag.ContinueOnError)
+	format := fs.String("format", "", "rate file format: ecb or csv (default inferred from extension)")
+	dryRun := fs.Bool("dry-run", false, "validate rows without importing")
+	configPath := fs.String("config", "./configs/service.json", "path to config file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one rate file, but got %d", fs.NArg())
+	}
+	path := fs.Arg(0)
+
+	read, err := reader(*format, path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open rate file: %w", err)
+	}
+	defer f.Close()
+	rows, err := read(f)
+	if err != nil {
+		return err
+	}
+
+	config, err := infrastructure.LoadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	dispatcher := infrastructure.NewDispatcher(ctx, config)
+	defer dispatcher.Close()
+
+	report, err := dispatcher.ImportExchangeRates(ctx, core.ImportExchangeRatesCommand{
+		Rows:   rows,
+		DryRun: *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	printReport(out, report)
+	return nil
+}
+
+func reader(format, path string) (func(io.Reader) ([]core.ExchangeRateImportRow, error), error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".xml":
+			format = "ecb"
+		case ".csv":
+			format = "csv"
+		}
+	}
+	switch format {
+	case "ecb":
+		return infrastructure.ReadECBExchangeRates, nil
+	case "csv":
+		return infrastructure.ReadCSVExchangeRates, nil
+	default:
+		return nil, fmt.Errorf("format must be ecb or csv, but was %q", format)
+	}
+}
+
+func printReport(w io.Writer, report *core.ImportExchangeRatesReport) {
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(w, "%s %d rows, rejected %d rows.\n", verb, len(report.Accepted), len(report.Rejected))
+
+	if len(report.Accepted) > 0 {
+		fmt.Fprintln(w, "\nAccepted:")
+		for _, a := range report.Accepted {
+			fmt.Fprintf(w, "  line %d: %s %s from %s\n", a.Line, a.Code, a.Rate, a.From)
+		}
+	}
+	if len(report.Rejected) > 0 {
+		fmt.Fprintln(w, "\nRejected:")
+		for _, r := range report.Rejected {
+			fields := make([]string, 0, len(r.FieldErrors))
+			for f := range r.FieldErrors {
+				fields = append(fields, f)
+			}
+			slices.Sort(fields)
+			for _, f := range fields {
+				for _, m := range r.FieldErrors[f] {
+					fmt.Fprintf(w, "  line %d: %s %s: %s\n", r.Line, r.Code, f, m)
+				}
+			}
+		}
+	}
+}
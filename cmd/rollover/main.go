@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// Rolls over resellers and clusters from last fiscal year, like the service
+// does on its rollover schedule:
+//
+//	rollover [-config path]
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		log.Fatalf("run: %v", err)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rollover", flag.ContinueOnError)
+	configPath := fs.String("config", "./configs/service.json", "path to config file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("expected no arguments, but got %d", fs.NArg())
+	}
+
+	config, err := infrastructure.LoadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	dispatcher := infrastructure.NewDispatcher(ctx, config)
+	defer dispatcher.Close()
+
+	report, err := dispatcher.RollOverYear(ctx, core.RollOverYearCommand{ID: uuid.New()})
+	if err != nil {
+		return err
+	}
+	printReport(out, report)
+	return nil
+}
+
+func printReport(w io.Writer, report *core.RolloverReport) {
+	fmt.Fprintf(w, "Rolled over %d resellers and %d clusters from %d, skipped %d resellers and %d clusters, failed %d clusters.\n",
+		report.ResellersRolled, len(report.Clusters), report.FiscalYear, report.ResellersSkipped, len(report.ClustersSkipped), len(report.ClustersFailed))
+
+	if len(report.Clusters) > 0 {
+		fmt.Fprintln(w, "\nRolled over:")
+		for _, c := range report.Clusters {
+			lastYear := "none"
+			if c.CalculatedNetRevenueLastYear != nil {
+				lastYear = c.CalculatedNetRevenueLastYear.Amount.String() + " " + c.CalculatedNetRevenueLastYear.Code
+			}
+			minimum := "none"
+			if c.CalculatedResellerTierMinimum != nil {
+				minimum = string(*c.CalculatedResellerTierMinimum)
+			}
+			fmt.Fprintf(w, "  cluster %s: last year %s, minimum tier %s\n", c.ClusterID, lastYear, minimum)
+		}
+	}
+	if len(report.ClustersFailed) > 0 {
+		fmt.Fprintln(w, "\nFailed:")
+		for _, f := range report.ClustersFailed {
+			fmt.Fprintf(w, "  cluster %s: %s\n", f.ClusterID, f.Reason)
+		}
+	}
+	if !report.Recorded {
+		fmt.Fprintln(w, "\nThe rollover isn't recorded and must be run again.")
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// createClusterRequest carries the struct tags that decode can't infer from
+// the command, as external_id and so on don't case-insensitively match
+// ExternalID and so on.
+type createClusterRequest struct {
+	ID             uuid.UUID `json:"id"`
+	ExternalID     uuid.UUID `json:"external_id"`
+	RevenueGroupID uuid.UUID `json:"revenue_group_id"`
+	HeadResellerID uuid.UUID `json:"head_reseller_id"`
+}
+
+func handleCreateCluster(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decode[createClusterRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.CreateClusterCommand(req)
+		if _, err := d.CreateCluster(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/clusters/"+cmd.ID.String())
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetCluster(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		res, err := d.GetCluster(r.Context(), core.GetClusterQuery{ID: id})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleGetClusterTierProjection(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		res, err := d.GetClusterTierProjection(r.Context(), core.GetClusterTierProjectionQuery{ClusterID: id})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+// addClusterMemberRequest carries the struct tag that decode can't infer from
+// the command, as reseller_id doesn't case-insensitively match ResellerID.
+type addClusterMemberRequest struct {
+	ResellerID uuid.UUID `json:"reseller_id"`
+}
+
+func handleAddClusterMember(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		req, err := decode[addClusterMemberRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.AddClusterMemberCommand{
+			ClusterID:       id,
+			ResellerID:      req.ResellerID,
+			ExpectedVersion: version,
+		}
+		if _, err := d.AddClusterMember(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleRemoveClusterMember(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		resellerID, err := pathUUID(r, "reseller_id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveClusterMemberCommand{
+			ClusterID:       id,
+			ResellerID:      resellerID,
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveClusterMember(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// changeClusterHeadRequest carries the struct tag that decode can't infer from
+// the command, as reseller_id doesn't case-insensitively match ResellerID.
+type changeClusterHeadRequest struct {
+	ResellerID uuid.UUID `json:"reseller_id"`
+}
+
+func handleChangeClusterHead(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		req, err := decode[changeClusterHeadRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.ChangeClusterHeadCommand{
+			ClusterID:       id,
+			ResellerID:      req.ResellerID,
+			ExpectedVersion: version,
+		}
+		if _, err := d.ChangeClusterHead(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleRemoveCluster(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveClusterCommand{
+			ID:              id,
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveCluster(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+func handleCreateCurrency(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cmd, err := decode[core.CreateCurrencyCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if _, err := d.CreateCurrency(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/currencies/"+url.PathEscape(cmd.Code))
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetCurrency(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		qry := core.GetCurrencyQuery{Code: r.PathValue("code")}
+		res, err := d.GetCurrency(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleListCurrencies(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hasFuture, err := queryBool(r, "has_future_exchange_rates")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		limit, err := queryInt(r, "limit")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.ListCurrenciesQuery{
+			HasFutureExchangeRates: hasFuture,
+			Cursor:                 r.URL.Query().Get("cursor"),
+			Limit:                  limit,
+		}
+		res, err := d.ListCurrencies(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, newPage(r, res.Items, res.NextCursor))
+	})
+}
+
+func handleRemoveCurrency(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveCurrencyCommand{
+			Code:            r.PathValue("code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveCurrency(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleAddExchangeRate(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.AddExchangeRateCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// The path identifies the currency. A code in the body is ignored
+		// rather than compared to avoid two sources of truth.
+		cmd.Code = r.PathValue("code")
+		cmd.ExpectedVersion = version
+		if _, err := d.AddExchangeRate(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleUpdateExchangeRate(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.UpdateExchangeRateCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd.ID = id
+		cmd.Code = r.PathValue("code")
+		cmd.ExpectedVersion = version
+		if _, err := d.UpdateExchangeRate(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleRemoveExchangeRate(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveExchangeRateCommand{
+			ID:              id,
+			Code:            r.PathValue("code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveExchangeRate(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// replaceFutureExchangeRatesRequest carries the struct tag that decode can't
+// infer from the command, as exchange_rates doesn't case-insensitively match
+// ExchangeRates.
+type replaceFutureExchangeRatesRequest struct {
+	ExchangeRates []core.ScheduledExchangeRate `json:"exchange_rates"`
+}
+
+func handleReplaceFutureExchangeRates(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		req, err := decode[replaceFutureExchangeRatesRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.ReplaceFutureExchangeRatesCommand{
+			Code:            r.PathValue("code"),
+			ExchangeRates:   req.ExchangeRates,
+			ExpectedVersion: version,
+		}
+		if _, err := d.ReplaceFutureExchangeRates(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleGetEffectiveExchangeRate(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		on, err := queryDate(r, "on")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetEffectiveExchangeRateQuery{Code: r.PathValue("code")}
+		if on != nil {
+			qry.On = *on
+		}
+		res, err := d.GetEffectiveExchangeRate(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleGetExchangeRateTimeline(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requiredFrom, err := queryDate(r, "required_from")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetExchangeRateTimelineQuery{
+			Code:         r.PathValue("code"),
+			RequiredFrom: requiredFrom,
+		}
+		res, err := d.GetExchangeRateTimeline(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleConvertMoney(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		amount, err := queryDecimal(r, "amount")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		on, err := queryDate(r, "on")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.ConvertMoneyQuery{
+			Amount:   amount,
+			FromCode: r.URL.Query().Get("from_code"),
+			ToCode:   r.URL.Query().Get("to_code"),
+		}
+		if on != nil {
+			qry.On = *on
+		}
+		res, err := d.ConvertMoney(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
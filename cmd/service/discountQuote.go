@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+func handleGetDiscountQuote(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resellerExternalID, err := queryUUID(r, "reseller_external_id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		on, err := queryDate(r, "on")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetDiscountQuoteQuery{
+			ResellerExternalID: resellerExternalID,
+			ProductCode:        r.URL.Query().Get("product_code"),
+		}
+		if on != nil {
+			qry.On = *on
+		}
+		res, err := d.GetDiscountQuote(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+// getDiscountQuotesRequest carries the struct tags that decode can't infer
+// from the query, as reseller_external_id and product_code don't
+// case-insensitively match ResellerExternalID and ProductCode.
+type getDiscountQuotesRequest struct {
+	Lines []getDiscountQuoteLineRequest `json:"lines"`
+}
+
+type getDiscountQuoteLineRequest struct {
+	ResellerExternalID uuid.UUID `json:"reseller_external_id"`
+	ProductCode        string    `json:"product_code"`
+	On                 core.Date `json:"on"`
+}
+
+func handleGetDiscountQuotes(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decode[getDiscountQuotesRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		lines := make([]core.DiscountQuoteLineInput, len(req.Lines))
+		for i, l := range req.Lines {
+			lines[i] = core.DiscountQuoteLineInput(l)
+		}
+		res, err := d.GetDiscountQuotes(r.Context(), core.GetDiscountQuotesQuery{Lines: lines})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDiscountQuotesDecodesSnakeCase(t *testing.T) {
+	resellerExternalID := uuid.New()
+	var got core.GetDiscountQuotesQuery
+	d := &infrastructure.Dispatcher{
+		GetDiscountQuotes: func(_ context.Context, qry core.GetDiscountQuotesQuery) (*core.DiscountQuotesResponse, error) {
+			got = qry
+			return &core.DiscountQuotesResponse{Quotes: []*core.DiscountQuoteResponse{nil}, Failed: []core.FailedDiscountQuote{}}, nil
+		},
+	}
+	body := `{"lines": [{"reseller_external_id": "` + resellerExternalID.String() + `", "product_code": "P1", "on": "2026-10-16"}]}`
+	r := httptest.NewRequest("POST", "/discount-quotes", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	NewServer(d).ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	expected := core.GetDiscountQuotesQuery{Lines: []core.DiscountQuoteLineInput{{
+		ResellerExternalID: resellerExternalID,
+		ProductCode:        "P1",
+		On:                 core.NewDate(2026, 10, 16),
+	}}}
+	assert.Equal(t, expected, got)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+)
+
+// badRequestError signals that a request couldn't be turned into a command or
+// query, i.e., it never reached core.
+type badRequestError struct {
+	message string
+}
+
+func (e *badRequestError) Error() string {
+	return e.message
+}
+
+func encode[T any](w http.ResponseWriter, status int, v T) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	return nil
+}
+
+// decode reads a JSON body into a command or query struct. Core structs
+// carry no struct tags, but encoding/json matches keys case-insensitively, so
+// a snake_case body such as {"rate": 1.5} populates Rate.
+func decode[T any](r *http.Request) (T, error) {
+	var v T
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return v, &badRequestError{message: fmt.Sprintf("decode json: %s", err)}
+	}
+	return v, nil
+}
+
+func pathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	v := r.PathValue(name)
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil(), &badRequestError{message: fmt.Sprintf("path parameter %s must be a UUID, but was %s", name, v)}
+	}
+	return id, nil
+}
+
+func queryUUID(r *http.Request, name string) (uuid.UUID, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return uuid.Nil(), nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil(), &badRequestError{message: fmt.Sprintf("query parameter %s must be a UUID, but was %s", name, v)}
+	}
+	return id, nil
+}
+
+func queryBool(r *http.Request, name string) (*bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, &badRequestError{message: fmt.Sprintf("query parameter %s must be true or false, but was %s", name, v)}
+	}
+	return &b, nil
+}
+
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &badRequestError{message: fmt.Sprintf("query parameter %s must be an integer, but was %s", name, v)}
+	}
+	return i, nil
+}
+
+func queryDecimal(r *http.Request, name string) (core.Decimal, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return core.Decimal{}, nil
+	}
+	d, err := core.ParseDecimal(v)
+	if err != nil {
+		return core.Decimal{}, &badRequestError{message: fmt.Sprintf("query parameter %s must be a number, but was %s", name, v)}
+	}
+	return d, nil
+}
+
+func queryDate(r *http.Request, name string) (*core.Date, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	d, err := core.ParseDate(v)
+	if err != nil {
+		return nil, &badRequestError{message: fmt.Sprintf("query parameter %s must be a YYYY-MM-DD date, but was %s", name, v)}
+	}
+	return &d, nil
+}
+
+// page is the Zalando response page object. Next is a link rather than a bare
+// cursor so that clients don't have to reassemble filters.
+type page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+func newPage[T any](r *http.Request, items []T, cursor string) page[T] {
+	p := page[T]{Items: items}
+	if cursor != "" {
+		u := *r.URL
+		q := u.Query()
+		q.Set("cursor", cursor)
+		u.RawQuery = q.Encode()
+		p.Next = u.RequestURI()
+	}
+	return p
+}
+
+// etag derives a strong entity tag from an aggregate version. The version is
+// the store's concurrency token, so the tag changes with every applied change.
+func etag(version int32) string {
+	return `"` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+// ifMatch extracts the expected aggregate version from the If-Match header. A
+// missing header or "*" yields nil, meaning the caller doesn't ask for the
+// version to be checked.
+func ifMatch(r *http.Request) (*int32, error) {
+	h := strings.TrimSpace(r.Header.Get("If-Match"))
+	if h == "" || h == "*" {
+		return nil, nil
+	}
+	if strings.HasPrefix(h, "W/") {
+		return nil, &badRequestError{message: fmt.Sprintf("If-Match requires a strong entity tag, but was %s", h)}
+	}
+	v, ok := strings.CutPrefix(h, `"`)
+	if ok {
+		v, ok = strings.CutSuffix(v, `"`)
+	}
+	version, err := strconv.ParseInt(v, 10, 32)
+	if !ok || err != nil {
+		return nil, &badRequestError{message: fmt.Sprintf("If-Match must be a single entity tag from a previous ETag, but was %s", h)}
+	}
+	expected := int32(version)
+	return &expected, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		expected *int32
+		invalid  bool
+	}{
+		"absent":   {"", nil, false},
+		"wildcard": {"*", nil, false},
+		"strong":   {`"3"`, new(int32(3)), false},
+		"weak":     {`W/"3"`, nil, true},
+		"unquoted": {"3", nil, true},
+		"list":     {`"3", "4"`, nil, true},
+		"nonsense": {`"abc"`, nil, true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("DELETE", "/currencies/USD", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			v, err := ifMatch(r)
+
+			if tt.invalid {
+				var e *badRequestError
+				require.ErrorAs(t, err, &e)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestETagRoundTrip(t *testing.T) {
+	r := httptest.NewRequest("DELETE", "/currencies/USD", nil)
+	r.Header.Set("If-Match", etag(42))
+
+	v, err := ifMatch(r)
+
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	assert.Equal(t, int32(42), *v)
+}
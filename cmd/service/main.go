@@ -10,6 +10,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"uuid"
 
 	"github.com/ronnieholm/resellerloyalty/internal/build"
@@ -30,41 +34,140 @@ func main() {
 	}
 	fmt.Printf("Version %s build at %s.\n\n", version, buildTime)
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx); err != nil {
+		log.Fatalf("run: %v", err)
+	}
+}
+
+// run is separate from main so that every dependency is passed explicitly and
+// deferred cleanup runs before the process exits.
+func run(ctx context.Context) error {
 	config, err := infrastructure.LoadConfig("./configs/service.json")
 	if err != nil {
-		log.Fatalf("error loading config: %v", err)
+		return fmt.Errorf("error loading config: %w", err)
 	}
 
-	ctx := context.Background()
-	dispatcher := infrastructure.NewDispatcher(ctx, config)
-	cmd := core.CreateCurrencyCommand{
-		ID:   uuid.MustParse("282490d5-4ca1-4b07-9955-6c8bbbc4ea00"),
-		Code: "USD",
+	tieringSchedule, err := infrastructure.ParseSchedule(config.DailyTieringSchedule)
+	if err != nil {
+		return fmt.Errorf("error parsing daily tiering schedule: %w", err)
+	}
+	rolloverSchedule, err := infrastructure.ParseSchedule(config.RolloverSchedule)
+	if err != nil {
+		return fmt.Errorf("error parsing rollover schedule: %w", err)
 	}
-	_, err = dispatcher.CreateCurrency(ctx, cmd)
+	purgeSchedule, err := infrastructure.ParseSchedule(config.IdempotencyKeyPurgeSchedule)
 	if err != nil {
-		log.Fatalf("Create currency: %s", err)
+		return fmt.Errorf("error parsing idempotency key purge schedule: %w", err)
 	}
+
+	dispatcher := infrastructure.NewDispatcher(ctx, config)
+	defer dispatcher.Close()
+
+	srv := &http.Server{
+		Addr:              config.HTTPAddr,
+		Handler:           NewServer(&dispatcher),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("listen and serve: %w", err)
+		}
+		close(errs)
+	}()
+
+	stopTiering := runSchedule(ctx, "daily tiering", tieringSchedule, func(ctx context.Context) { tierClusters(ctx, &dispatcher) })
+	defer stopTiering()
+	stopRollover := runSchedule(ctx, "rollover", rolloverSchedule, func(ctx context.Context) { rollOverYear(ctx, &dispatcher) })
+	defer stopRollover()
+	stopPurge := runSchedule(ctx, "idempotency key purge", purgeSchedule, func(ctx context.Context) { purgeIdempotencyKeys(ctx, &dispatcher) })
+	defer stopPurge()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Give in-flight requests a chance to complete before the connection pool
+	// is closed by the deferred dispatcher.Close.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
 }
 
-func MapErrorToHTTP(err error) (int, string) {
-	var conflict *core.ConflictError
-	var notFound *core.NotFoundError
-	var domainErr *core.DomainError
+// runSchedule runs job on schedule until stopped. Stopping waits for a run in
+// progress to complete, as it must before the connection pool is closed by the
+// deferred dispatcher.Close.
+func runSchedule(ctx context.Context, name string, schedule infrastructure.Schedule, job func(context.Context)) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := infrastructure.RunSchedule(ctx, schedule, job); err != nil {
+			log.Printf("%s: %v", name, err)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
 
+// tierClusters runs on every scheduled time, not only once a day. Until a
+// tiering is recorded for today, each run picks up clusters that are yet to be
+// tiered. Afterwards, runs are rejected as conflicting.
+func tierClusters(ctx context.Context, d *infrastructure.Dispatcher) {
+	report, err := d.TierClusters(ctx, core.TierClustersCommand{ID: uuid.New()})
+	var conflict *core.ConflictError
 	switch {
 	case errors.As(err, &conflict):
-		return http.StatusConflict, conflict.Error()
+		return
+	case err != nil:
+		log.Printf("daily tiering: %v", err)
+		return
+	}
+
+	log.Printf("daily tiering %s: %d tiered, %d skipped, %d failed", report.TierAt, len(report.Tiered), len(report.Skipped), len(report.Failed))
+	for _, f := range report.Failed {
+		log.Printf("daily tiering %s: cluster %s: %s", report.TierAt, f.ClusterID, f.Reason)
+	}
+}
 
-	case errors.As(err, &notFound):
-		return http.StatusNotFound, notFound.Error()
+// rollOverYear runs on every scheduled time, like tierClusters. Until a
+// rollover is recorded for last year, each run picks up clusters that are yet
+// to be rolled over.
+func rollOverYear(ctx context.Context, d *infrastructure.Dispatcher) {
+	report, err := d.RollOverYear(ctx, core.RollOverYearCommand{ID: uuid.New()})
+	var conflict *core.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return
+	case err != nil:
+		log.Printf("rollover: %v", err)
+		return
+	}
 
-	case errors.As(err, &domainErr):
-		// Generic domain rule violation fallback
-		return http.StatusUnprocessableEntity, domainErr.Error()
+	log.Printf("rollover %d: %d resellers rolled over, %d clusters rolled over, %d skipped, %d failed",
+		report.FiscalYear, report.ResellersRolled, len(report.Clusters), len(report.ClustersSkipped), len(report.ClustersFailed))
+	for _, f := range report.ClustersFailed {
+		log.Printf("rollover %d: cluster %s: %s", report.FiscalYear, f.ClusterID, f.Reason)
+	}
+}
 
-	default:
-		// Internal server errors / infrastructure issues should never leak details
-		return http.StatusInternalServerError, "an unexpected error occurred"
+func purgeIdempotencyKeys(ctx context.Context, d *infrastructure.Dispatcher) {
+	n, err := d.PurgeIdempotencyKeys(ctx)
+	if err != nil {
+		log.Printf("idempotency key purge: %v", err)
+		return
 	}
+	log.Printf("idempotency key purge: %d deleted", n)
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// Problem is an RFC 9457 Problem Details response. Type is a stable URI
+// reference that clients may switch on, whereas Title and Detail are for
+// humans and may change.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extension members.
+	Code          int            `json:"code,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam correlates a request field with one reason it failed parsing.
+// A field failing multiple validations is reported once per reason so a UI
+// can list every message next to the form field.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+const (
+	problemTypeMalformedRequest = "/problems/malformed-request"
+	problemTypeValidation       = "/problems/validation"
+	problemTypeConflict         = "/problems/conflict"
+	problemTypeNotFound         = "/problems/not-found"
+	problemTypeDataStale        = "/problems/data-stale"
+	problemTypeNoExchangeRate   = "/problems/no-exchange-rate"
+	problemTypeNoWeight         = "/problems/no-product-group-weight"
+	problemTypeNoLimit          = "/problems/no-revenue-group-limit"
+	problemTypeNoTierDiscount   = "/problems/no-tier-discount"
+	problemTypeIdempotencyKey   = "/problems/idempotency-key-reused"
+	problemTypeInternal         = "about:blank"
+)
+
+// domainProblemType maps a DomainError code to its type. Codes are unique
+// across core, so the code itself makes the type stable.
+func domainProblemType(code int) string {
+	return fmt.Sprintf("/problems/domain-rule/%d", code)
+}
+
+func MapErrorToHTTP(err error) Problem {
+	var badRequest *badRequestError
+	var keyReused *infrastructure.IdempotencyKeyReusedError
+	var parse *core.RequestParseCollector
+	var conflict *core.ConflictError
+	var notFound *core.NotFoundError
+	var stale *core.DataStaleError
+	var noRate *core.NoExchangeRateError
+	var noWeight *core.NoProductGroupWeightError
+	var noLimit *core.NoRevenueGroupLimitError
+	var noTierDiscount *core.NoTierDiscountError
+	var domainErr *core.DomainError
+
+	switch {
+	case errors.As(err, &badRequest):
+		return Problem{
+			Type:   problemTypeMalformedRequest,
+			Title:  "Malformed request",
+			Status: http.StatusBadRequest,
+			Detail: badRequest.Error(),
+		}
+
+	case errors.As(err, &keyReused):
+		return Problem{
+			Type:   problemTypeIdempotencyKey,
+			Title:  "Idempotency key reused with a different request",
+			Status: http.StatusUnprocessableEntity,
+			Detail: keyReused.Error(),
+		}
+
+	case errors.As(err, &parse):
+		return Problem{
+			Type:          problemTypeValidation,
+			Title:         "Request failed validation",
+			Status:        http.StatusBadRequest,
+			Detail:        "one or more fields are invalid",
+			InvalidParams: invalidParams(parse),
+		}
+
+	case errors.As(err, &conflict):
+		return Problem{
+			Type:   problemTypeConflict,
+			Title:  "Resource conflict",
+			Status: http.StatusConflict,
+			Detail: conflict.Error(),
+		}
+
+	case errors.As(err, &notFound):
+		return Problem{
+			Type:   problemTypeNotFound,
+			Title:  "Resource not found",
+			Status: http.StatusNotFound,
+			Detail: notFound.Error(),
+		}
+
+	case errors.As(err, &stale):
+		// Either the If-Match version no longer matches or another request
+		// changed the aggregate between read and write. Without If-Match, the
+		// latter is a conflict rather than a failed precondition. See
+		// writeError.
+		return Problem{
+			Type:   problemTypeDataStale,
+			Title:  "Resource changed by another request",
+			Status: http.StatusPreconditionFailed,
+			Detail: stale.Error(),
+		}
+
+	case errors.As(err, &noRate):
+		// The currency exists, so unlike not found, the request is well-formed
+		// but can't be served for the date asked about.
+		return Problem{
+			Type:   problemTypeNoExchangeRate,
+			Title:  "No exchange rate in effect",
+			Status: http.StatusUnprocessableEntity,
+			Detail: noRate.Error(),
+		}
+
+	case errors.As(err, &noWeight):
+		return Problem{
+			Type:   problemTypeNoWeight,
+			Title:  "No product group weight in effect",
+			Status: http.StatusUnprocessableEntity,
+			Detail: noWeight.Error(),
+		}
+
+	case errors.As(err, &noLimit):
+		return Problem{
+			Type:   problemTypeNoLimit,
+			Title:  "No revenue group limit in effect",
+			Status: http.StatusUnprocessableEntity,
+			Detail: noLimit.Error(),
+		}
+
+	case errors.As(err, &noTierDiscount):
+		return Problem{
+			Type:   problemTypeNoTierDiscount,
+			Title:  "No tier discount in effect",
+			Status: http.StatusUnprocessableEntity,
+			Detail: noTierDiscount.Error(),
+		}
+
+	case errors.As(err, &domainErr):
+		// Generic domain rule violation fallback
+		return Problem{
+			Type:   domainProblemType(domainErr.Code),
+			Title:  "Domain rule violated",
+			Status: http.StatusUnprocessableEntity,
+			Detail: domainErr.Message,
+			Code:   domainErr.Code,
+		}
+
+	default:
+		// Internal server errors / infrastructure issues should never leak details
+		return Problem{
+			Type:   problemTypeInternal,
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+			Detail: "an unexpected error occurred",
+		}
+	}
+}
+
+// invalidParams flattens the collector's map into a slice ordered by field
+// name. Map iteration order is random, and a response should be stable across
+// identical requests.
+func invalidParams(c *core.RequestParseCollector) []InvalidParam {
+	fields := make([]string, 0, len(c.FieldErrors))
+	for f := range c.FieldErrors {
+		fields = append(fields, f)
+	}
+	slices.Sort(fields)
+
+	var params []InvalidParam
+	for _, f := range fields {
+		for _, reason := range c.FieldErrors[f] {
+			params = append(params, InvalidParam{Name: f, Reason: reason})
+		}
+	}
+	return params
+}
+
+// hasIfMatch tells whether the request asked for a version to be checked, so
+// that a stale write is a failed precondition.
+func hasIfMatch(r *http.Request) bool {
+	version, err := ifMatch(r)
+	return err == nil && version != nil
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	p := MapErrorToHTTP(err)
+	if p.Type == problemTypeDataStale && !hasIfMatch(r) {
+		p.Status = http.StatusConflict
+	}
+	p.Instance = r.URL.Path
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapErrorToHTTP(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		status int
+		type_  string
+	}{
+		"malformed":        {&badRequestError{message: "bad"}, http.StatusBadRequest, problemTypeMalformedRequest},
+		"parse":            {&core.RequestParseCollector{FieldErrors: map[string][]string{"Code": {"x"}}}, http.StatusBadRequest, problemTypeValidation},
+		"conflict":         {core.NewConflictError("Currency", "Code", "USD"), http.StatusConflict, problemTypeConflict},
+		"not found":        {core.NewNotFoundError("Currency", "Code", "USD"), http.StatusNotFound, problemTypeNotFound},
+		"stale":            {core.NewDataStaleError("Currency", uuid.Nil()), http.StatusPreconditionFailed, problemTypeDataStale},
+		"domain":           {core.NewDomainError(core.CurrencyAddRequiresFutureFrom, "x"), http.StatusUnprocessableEntity, "/problems/domain-rule/1600"},
+		"wrapped":          {fmt.Errorf("wrap: %w", core.NewDomainError(core.CurrencyUpdateRequiresChange, "x")), http.StatusUnprocessableEntity, "/problems/domain-rule/1603"},
+		"no rate":          {core.NewNoExchangeRateError(core.MustParseCurrencyCode("USD"), core.NewDate(2026, 1, 1)), http.StatusUnprocessableEntity, problemTypeNoExchangeRate},
+		"no weight":        {core.NewNoProductGroupWeightError(core.MustParseProductGroupCode("HW"), core.NewDate(2026, 1, 1)), http.StatusUnprocessableEntity, problemTypeNoWeight},
+		"no limit":         {core.NewNoRevenueGroupLimitError(core.MustParseCountryCode("DK"), core.NewDate(2026, 1, 1)), http.StatusUnprocessableEntity, problemTypeNoLimit},
+		"no tier discount": {core.NewNoTierDiscountError(core.NewDate(2026, 1, 1)), http.StatusUnprocessableEntity, problemTypeNoTierDiscount},
+		"key reused":       {&infrastructure.IdempotencyKeyReusedError{Key: "k"}, http.StatusUnprocessableEntity, problemTypeIdempotencyKey},
+		"internal":         {fmt.Errorf("connection refused"), http.StatusInternalServerError, problemTypeInternal},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := MapErrorToHTTP(tt.err)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.type_, p.Type)
+		})
+	}
+}
+
+func TestMapErrorToHTTPInvalidParams(t *testing.T) {
+	err := &core.RequestParseCollector{FieldErrors: map[string][]string{
+		"Rate": {"must be between 1 and 100 inclusive, but was 0", "decimal places must be between 0 and 6 inclusive"},
+		"Code": {"must be one of the allowed currency codes, but was ABC"},
+	}}
+
+	p := MapErrorToHTTP(err)
+
+	require.Len(t, p.InvalidParams, 3)
+	assert.Equal(t, InvalidParam{"Code", err.FieldErrors["Code"][0]}, p.InvalidParams[0])
+	assert.Equal(t, InvalidParam{"Rate", err.FieldErrors["Rate"][0]}, p.InvalidParams[1])
+	assert.Equal(t, InvalidParam{"Rate", err.FieldErrors["Rate"][1]}, p.InvalidParams[2])
+}
+
+func TestWriteErrorDataStale(t *testing.T) {
+	tests := map[string]struct {
+		ifMatch string
+		status  int
+	}{
+		"with if-match":    {`"3"`, http.StatusPreconditionFailed},
+		"without if-match": {"", http.StatusConflict},
+		"any version":      {"*", http.StatusConflict},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/currencies/USD/exchange-rates/1", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			writeError(w, r, core.NewDataStaleError("Currency", uuid.Nil()))
+
+			assert.Equal(t, tt.status, w.Code)
+			var p Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, problemTypeDataStale, p.Type)
+		})
+	}
+}
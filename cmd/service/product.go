@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+func handleCreateProduct(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cmd, err := decode[core.CreateProductCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if _, err := d.CreateProduct(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/products/"+cmd.Code)
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetProduct(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		qry := core.GetProductQuery{Code: r.PathValue("code")}
+		res, err := d.GetProduct(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleListProducts(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, err := queryInt(r, "limit")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.ListProductsQuery{
+			Cursor: r.URL.Query().Get("cursor"),
+			Limit:  limit,
+		}
+		if v := r.URL.Query().Get("product_group_code"); v != "" {
+			qry.ProductGroupCode = &v
+		}
+		res, err := d.ListProducts(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, newPage(r, res.Items, res.NextCursor))
+	})
+}
+
+func handleRemoveProduct(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveProductCommand{
+			Code:            r.PathValue("code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveProduct(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// assignProductGroupRequest carries the struct tag that decode can't infer
+// from the command, as product_group_code doesn't case-insensitively match
+// ProductGroupCode.
+type assignProductGroupRequest struct {
+	ProductGroupCode string `json:"product_group_code"`
+}
+
+func handleAssignProductGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		req, err := decode[assignProductGroupRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.AssignProductGroupCommand{
+			Code:             r.PathValue("code"),
+			ProductGroupCode: req.ProductGroupCode,
+			ExpectedVersion:  version,
+		}
+		if _, err := d.AssignProductGroup(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleUnassignProductGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.UnassignProductGroupCommand{
+			Code:            r.PathValue("code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.UnassignProductGroup(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+func handleCreateProductGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cmd, err := decode[core.CreateProductGroupCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if _, err := d.CreateProductGroup(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/product-groups/"+cmd.Code)
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetProductGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		qry := core.GetProductGroupQuery{Code: r.PathValue("code")}
+		res, err := d.GetProductGroup(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleListProductGroups(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, err := queryInt(r, "limit")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.ListProductGroupsQuery{
+			Cursor: r.URL.Query().Get("cursor"),
+			Limit:  limit,
+		}
+		res, err := d.ListProductGroups(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, newPage(r, res.Items, res.NextCursor))
+	})
+}
+
+func handleRemoveProductGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveProductGroupCommand{
+			Code:            r.PathValue("code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveProductGroup(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleAddProductGroupWeight(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.AddProductGroupWeightCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		// The path identifies the product group. A code in the body is
+		// ignored rather than compared to avoid two sources of truth.
+		cmd.Code = r.PathValue("code")
+		cmd.ExpectedVersion = version
+		if _, err := d.AddProductGroupWeight(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleUpdateProductGroupWeight(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.UpdateProductGroupWeightCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd.ID = id
+		cmd.Code = r.PathValue("code")
+		cmd.ExpectedVersion = version
+		if _, err := d.UpdateProductGroupWeight(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleRemoveProductGroupWeight(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveProductGroupWeightCommand{
+			ID:              id,
+			Code:            r.PathValue("code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveProductGroupWeight(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleGetEffectiveProductGroupWeight(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		on, err := queryDate(r, "on")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetEffectiveProductGroupWeightQuery{Code: r.PathValue("code")}
+		if on != nil {
+			qry.On = *on
+		}
+		res, err := d.GetEffectiveProductGroupWeight(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
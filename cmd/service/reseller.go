@@ -0,0 +1,187 @@
+package main
+
+import (
+	"net/http"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// enrollResellerRequest carries the struct tags that decode can't infer from
+// the command, as external_id, country_code, and so on, don't
+// case-insensitively match ExternalID, CountryCode, and so on.
+type enrollResellerRequest struct {
+	ID           uuid.UUID `json:"id"`
+	ExternalID   uuid.UUID `json:"external_id"`
+	CountryCode  string    `json:"country_code"`
+	CurrencyCode string    `json:"currency_code"`
+	EnrolledAt   core.Date `json:"enrolled_at"`
+}
+
+func handleEnrollReseller(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decode[enrollResellerRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.EnrollResellerCommand(req)
+		if _, err := d.EnrollReseller(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/resellers/"+cmd.ID.String())
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetReseller(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		res, err := d.GetReseller(r.Context(), core.GetResellerQuery{ID: id})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleGetResellerByExternalID(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		externalID, err := pathUUID(r, "external_id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		res, err := d.GetResellerByExternalID(r.Context(), core.GetResellerByExternalIDQuery{ExternalID: externalID})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+// changeResellerCurrencyRequest carries the struct tag that decode can't infer
+// from the command, as currency_code doesn't case-insensitively match
+// CurrencyCode.
+type changeResellerCurrencyRequest struct {
+	CurrencyCode string `json:"currency_code"`
+}
+
+func handleChangeResellerCurrency(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		req, err := decode[changeResellerCurrencyRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.ChangeResellerCurrencyCommand{
+			ID:              id,
+			CurrencyCode:    req.CurrencyCode,
+			ExpectedVersion: version,
+		}
+		if _, err := d.ChangeResellerCurrency(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleUnenrollReseller(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.UnenrollResellerCommand{
+			ID:              id,
+			ExpectedVersion: version,
+		}
+		if _, err := d.UnenrollReseller(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// recordBillingRequest carries the struct tags that decode can't infer from
+// the command, as reseller_external_id, document_number, and so on, don't
+// case-insensitively match ResellerExternalID, DocumentNumber, and so on.
+type recordBillingRequest struct {
+	ID                 uuid.UUID                  `json:"id"`
+	ResellerExternalID uuid.UUID                  `json:"reseller_external_id"`
+	DocumentNumber     string                     `json:"document_number"`
+	BookedAt           core.Date                  `json:"booked_at"`
+	Kind               string                     `json:"kind"`
+	CurrencyCode       string                     `json:"currency_code"`
+	Items              []recordBillingItemRequest `json:"items"`
+}
+
+type recordBillingItemRequest struct {
+	ID           uuid.UUID    `json:"id"`
+	ProductCode  string       `json:"product_code"`
+	GrossRevenue core.Decimal `json:"gross_revenue"`
+}
+
+// handleRecordBilling responds without a Location as billings are read
+// through the reseller's net revenue rather than by themselves.
+func handleRecordBilling(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decode[recordBillingRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		items := make([]core.RecordBillingItemInput, len(req.Items))
+		for i, item := range req.Items {
+			items[i] = core.RecordBillingItemInput(item)
+		}
+		cmd := core.RecordBillingCommand{
+			ID:                 req.ID,
+			ResellerExternalID: req.ResellerExternalID,
+			DocumentNumber:     req.DocumentNumber,
+			BookedAt:           req.BookedAt,
+			Kind:               req.Kind,
+			CurrencyCode:       req.CurrencyCode,
+			Items:              items,
+		}
+		if _, err := d.RecordBilling(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
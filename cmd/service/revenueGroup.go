@@ -0,0 +1,148 @@
+package main
+
+import (
+	"net/http"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// createRevenueGroupRequest carries the struct tags that decode can't infer
+// from the command, as country_code and currency_code don't
+// case-insensitively match CountryCode and CurrencyCode.
+type createRevenueGroupRequest struct {
+	ID           uuid.UUID `json:"id"`
+	CountryCode  string    `json:"country_code"`
+	CurrencyCode string    `json:"currency_code"`
+}
+
+func handleCreateRevenueGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decode[createRevenueGroupRequest](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.CreateRevenueGroupCommand(req)
+		if _, err := d.CreateRevenueGroup(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/revenue-groups/"+cmd.CountryCode)
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetRevenueGroup(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		qry := core.GetRevenueGroupQuery{CountryCode: r.PathValue("country_code")}
+		res, err := d.GetRevenueGroup(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleAddRevenueGroupLimit(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.AddRevenueGroupLimitCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd.CountryCode = r.PathValue("country_code")
+		cmd.ExpectedVersion = version
+		if _, err := d.AddRevenueGroupLimit(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleUpdateRevenueGroupLimit(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.UpdateRevenueGroupLimitCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd.ID = id
+		cmd.CountryCode = r.PathValue("country_code")
+		cmd.ExpectedVersion = version
+		if _, err := d.UpdateRevenueGroupLimit(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleRemoveRevenueGroupLimit(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveRevenueGroupLimitCommand{
+			ID:              id,
+			CountryCode:     r.PathValue("country_code"),
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveRevenueGroupLimit(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleGetEffectiveRevenueGroupLimit(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		on, err := queryDate(r, "on")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetEffectiveRevenueGroupLimitQuery{CountryCode: r.PathValue("country_code")}
+		if on != nil {
+			qry.On = *on
+		}
+		res, err := d.GetEffectiveRevenueGroupLimit(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
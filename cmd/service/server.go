@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+// NewServer returns the HTTP API of the service. Like the Dispatcher, the
+// server is constructed once and every dependency is passed as an argument
+// rather than held in globals.
+func NewServer(dispatcher *infrastructure.Dispatcher) http.Handler {
+	mux := http.NewServeMux()
+	addRoutes(mux, dispatcher)
+	return withIdempotencyKey(mux)
+}
+
+const maxIdempotencyKeyLength = 255
+
+// withIdempotencyKey hands the Idempotency-Key header to the dispatcher. It's
+// applied to every route, but only commands consult the key.
+func withIdempotencyKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(w, r, &badRequestError{
+				message: fmt.Sprintf("Idempotency-Key must be at most %d characters, but was %d", maxIdempotencyKeyLength, len(key)),
+			})
+			return
+		}
+		ctx := infrastructure.WithIdempotencyKey(r.Context(), key)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// addRoutes maps the entire API surface in one place. Resources follow the
+// Zalando API guidelines: plural nouns, kebab-case path segments, and
+// snake_case JSON properties.
+func addRoutes(mux *http.ServeMux, d *infrastructure.Dispatcher) {
+	// Currency
+	mux.Handle("GET /currencies", handleListCurrencies(d))
+	mux.Handle("POST /currencies", handleCreateCurrency(d))
+	mux.Handle("GET /currencies/{code}", handleGetCurrency(d))
+	mux.Handle("DELETE /currencies/{code}", handleRemoveCurrency(d))
+	mux.Handle("POST /currencies/{code}/exchange-rates", handleAddExchangeRate(d))
+	mux.Handle("PUT /currencies/{code}/exchange-rates/{id}", handleUpdateExchangeRate(d))
+	mux.Handle("DELETE /currencies/{code}/exchange-rates/{id}", handleRemoveExchangeRate(d))
+	mux.Handle("PUT /currencies/{code}/future-exchange-rates", handleReplaceFutureExchangeRates(d))
+	mux.Handle("GET /currencies/{code}/effective-exchange-rate", handleGetEffectiveExchangeRate(d))
+	mux.Handle("GET /currencies/{code}/exchange-rate-timeline", handleGetExchangeRateTimeline(d))
+	mux.Handle("GET /money-conversions", handleConvertMoney(d))
+
+	// TierDiscount
+	mux.Handle("GET /tier-discounts", handleListTierDiscounts(d))
+	mux.Handle("POST /tier-discounts", handleCreateTierDiscount(d))
+	mux.Handle("GET /tier-discounts/{id}", handleGetTierDiscount(d))
+	mux.Handle("PUT /tier-discounts/{id}", handleUpdateTierDiscount(d))
+	mux.Handle("DELETE /tier-discounts/{id}", handleRemoveTierDiscount(d))
+	mux.Handle("GET /effective-tier-discount", handleGetEffectiveTierDiscount(d))
+	mux.Handle("GET /tier-discount-timeline", handleGetTierDiscountTimeline(d))
+
+	// Product
+	mux.Handle("GET /products", handleListProducts(d))
+	mux.Handle("POST /products", handleCreateProduct(d))
+	mux.Handle("GET /products/{code}", handleGetProduct(d))
+	mux.Handle("DELETE /products/{code}", handleRemoveProduct(d))
+	mux.Handle("PUT /products/{code}/product-group", handleAssignProductGroup(d))
+	mux.Handle("DELETE /products/{code}/product-group", handleUnassignProductGroup(d))
+
+	// ProductGroup
+	mux.Handle("GET /product-groups", handleListProductGroups(d))
+	mux.Handle("POST /product-groups", handleCreateProductGroup(d))
+	mux.Handle("GET /product-groups/{code}", handleGetProductGroup(d))
+	mux.Handle("DELETE /product-groups/{code}", handleRemoveProductGroup(d))
+	mux.Handle("POST /product-groups/{code}/weights", handleAddProductGroupWeight(d))
+	mux.Handle("PUT /product-groups/{code}/weights/{id}", handleUpdateProductGroupWeight(d))
+	mux.Handle("DELETE /product-groups/{code}/weights/{id}", handleRemoveProductGroupWeight(d))
+	mux.Handle("GET /product-groups/{code}/effective-weight", handleGetEffectiveProductGroupWeight(d))
+
+	// RevenueGroup
+	mux.Handle("POST /revenue-groups", handleCreateRevenueGroup(d))
+	mux.Handle("GET /revenue-groups/{country_code}", handleGetRevenueGroup(d))
+	mux.Handle("POST /revenue-groups/{country_code}/limits", handleAddRevenueGroupLimit(d))
+	mux.Handle("PUT /revenue-groups/{country_code}/limits/{id}", handleUpdateRevenueGroupLimit(d))
+	mux.Handle("DELETE /revenue-groups/{country_code}/limits/{id}", handleRemoveRevenueGroupLimit(d))
+	mux.Handle("GET /revenue-groups/{country_code}/effective-limit", handleGetEffectiveRevenueGroupLimit(d))
+
+	// Reseller
+	mux.Handle("POST /resellers", handleEnrollReseller(d))
+	mux.Handle("GET /resellers/{id}", handleGetReseller(d))
+	mux.Handle("DELETE /resellers/{id}", handleUnenrollReseller(d))
+	mux.Handle("PUT /resellers/{id}/currency", handleChangeResellerCurrency(d))
+	mux.Handle("GET /resellers/by-external-id/{external_id}", handleGetResellerByExternalID(d))
+	mux.Handle("POST /billings", handleRecordBilling(d))
+
+	// Cluster
+	mux.Handle("POST /clusters", handleCreateCluster(d))
+	mux.Handle("GET /clusters/{id}", handleGetCluster(d))
+	mux.Handle("GET /clusters/{id}/tier-projection", handleGetClusterTierProjection(d))
+	mux.Handle("POST /clusters/{id}/members", handleAddClusterMember(d))
+	mux.Handle("DELETE /clusters/{id}/members/{reseller_id}", handleRemoveClusterMember(d))
+	mux.Handle("PUT /clusters/{id}/head", handleChangeClusterHead(d))
+	mux.Handle("DELETE /clusters/{id}", handleRemoveCluster(d))
+
+	// DiscountQuote
+	mux.Handle("GET /discount-quote", handleGetDiscountQuote(d))
+	// Quoting many lines is a query, but the lines don't fit a URL.
+	mux.Handle("POST /discount-quotes", handleGetDiscountQuotes(d))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+)
+
+func handleCreateTierDiscount(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cmd, err := decode[core.CreateTierDiscountCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if _, err := d.CreateTierDiscount(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Location", "/tier-discounts/"+cmd.ID.String())
+		w.WriteHeader(http.StatusCreated)
+	})
+}
+
+func handleGetTierDiscount(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		res, err := d.GetTierDiscount(r.Context(), core.GetTierDiscountQuery{ID: id})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag(res.Version))
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleListTierDiscounts(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromMin, err := queryDate(r, "from_min")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		fromMax, err := queryDate(r, "from_max")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		limit, err := queryInt(r, "limit")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.ListTierDiscountsQuery{
+			FromMin: fromMin,
+			FromMax: fromMax,
+			Cursor:  r.URL.Query().Get("cursor"),
+			Limit:   limit,
+		}
+		res, err := d.ListTierDiscounts(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, newPage(r, res.Items, res.NextCursor))
+	})
+}
+
+func handleUpdateTierDiscount(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		cmd, err := decode[core.UpdateTierDiscountCommand](r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd.ID = id
+		cmd.ExpectedVersion = version
+		if _, err := d.UpdateTierDiscount(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleRemoveTierDiscount(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathUUID(r, "id")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		version, err := ifMatch(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		cmd := core.RemoveTierDiscountCommand{
+			ID:              id,
+			ExpectedVersion: version,
+		}
+		if _, err := d.RemoveTierDiscount(r.Context(), cmd); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func handleGetEffectiveTierDiscount(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		on, err := queryDate(r, "on")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetEffectiveTierDiscountQuery{}
+		if on != nil {
+			qry.On = *on
+		}
+		res, err := d.GetEffectiveTierDiscount(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
+
+func handleGetTierDiscountTimeline(d *infrastructure.Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requiredFrom, err := queryDate(r, "required_from")
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		qry := core.GetTierDiscountTimelineQuery{RequiredFrom: requiredFrom}
+		res, err := d.GetTierDiscountTimeline(r.Context(), qry)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		_ = encode(w, http.StatusOK, res)
+	})
+}
@@ -1 +1,866 @@
 package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"uuid"
+)
+
+// Domain
+
+type ClusterStore interface {
+	ExistByID(context.Context, ClusterID) (bool, error)
+	ExistByExternalID(context.Context, ClusterExternalID) (bool, error)
+	GetByID(context.Context, ClusterID) (*Cluster, error)
+	List(context.Context) ([]*Cluster, error)
+	// GetTierOn returns the cluster's tiers from the latest tiering or rollover
+	// on or before a date, or nil if the cluster wasn't tiered by then.
+	GetTierOn(context.Context, ClusterID, Date) (*ClusterTier, error)
+}
+
+type ClusterCreatedEvent struct {
+	domainEventCommon
+	ID             uuid.UUID `json:"id"`
+	ExternalID     uuid.UUID `json:"external_id"`
+	RevenueGroupID uuid.UUID `json:"revenue_group_id"`
+	HeadResellerID uuid.UUID `json:"head_reseller_id"`
+	FiscalYear     int       `json:"fiscal_year"`
+}
+
+type ClusterMemberAddedEvent struct {
+	domainEventCommon
+	ClusterID  uuid.UUID    `json:"cluster_id"`
+	ResellerID uuid.UUID    `json:"reseller_id"`
+	Role       ResellerRole `json:"role"`
+}
+
+type ClusterMemberRemovedEvent struct {
+	domainEventCommon
+	ClusterID  uuid.UUID `json:"cluster_id"`
+	ResellerID uuid.UUID `json:"reseller_id"`
+}
+
+type ClusterRemovedEvent struct {
+	domainEventCommon
+	ID uuid.UUID `json:"id"`
+}
+
+// ClusterHeadChangedEvent makes the previous head a member.
+type ClusterHeadChangedEvent struct {
+	domainEventCommon
+	ClusterID          uuid.UUID `json:"cluster_id"`
+	PreviousResellerID uuid.UUID `json:"previous_reseller_id"`
+	ResellerID         uuid.UUID `json:"reseller_id"`
+}
+
+type ClusterTieredEvent struct {
+	domainEventCommon
+	ID                               uuid.UUID     `json:"id"`
+	TierAt                           Date          `json:"tier_at"`
+	CalculatedNetRevenueYearToDate   Decimal       `json:"calculated_net_revenue_year_to_date"`
+	CalculatedResellerTierYearToDate *ResellerTier `json:"calculated_reseller_tier_year_to_date"`
+	CalculatedResellerTierMinimum    *ResellerTier `json:"calculated_reseller_tier_minimum"`
+}
+
+type ClusterTierProjectedEvent struct {
+	domainEventCommon
+	ID                              uuid.UUID     `json:"id"`
+	CalculatedNetRevenueProjected   Decimal       `json:"calculated_net_revenue_projected"`
+	CalculatedResellerTierProjected *ResellerTier `json:"calculated_reseller_tier_projected"`
+}
+
+// ClusterRolledOverEvent leaves year-to-date and projected net revenue and
+// tiers not calculated.
+type ClusterRolledOverEvent struct {
+	domainEventCommon
+	ID                            uuid.UUID     `json:"id"`
+	FiscalYear                    int           `json:"fiscal_year"`
+	CalculatedNetRevenueLastYear  *Decimal      `json:"calculated_net_revenue_last_year"`
+	CalculatedResellerTierMinimum *ResellerTier `json:"calculated_reseller_tier_minimum"`
+}
+
+const (
+	ClusterExpectedNonMember          = 1300
+	ClusterExpectedMember             = 1301
+	ClusterExpectedNonHeadForRemoval  = 1302
+	ClusterExpectedTierAtAfterLast    = 1303
+	ClusterExpectedTieredForProject   = 1304
+	ClusterExpectedFiscalYear         = 1305
+	ClusterExpectedLaterYear          = 1306
+	ClusterExpectedNonHeadForHead     = 1307
+	ClusterExpectedBookedInFiscalYear = 1308
+)
+
+// ClusterID
+
+type ClusterID struct {
+	v uuid.UUID
+}
+
+func (c ClusterID) V() uuid.UUID   { return c.v }
+func (c ClusterID) String() string { return c.v.String() }
+
+func ParseClusterID(v uuid.UUID) (ClusterID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ClusterID{}, err
+	}
+	return ClusterID{v}, nil
+}
+
+func MustParseClusterID(v uuid.UUID) ClusterID {
+	v1, err := ParseClusterID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ClusterExternalID identifies a cluster in the system of record for
+// resellers, like ResellerExternalID does for a reseller.
+type ClusterExternalID struct {
+	v uuid.UUID
+}
+
+func (c ClusterExternalID) V() uuid.UUID   { return c.v }
+func (c ClusterExternalID) String() string { return c.v.String() }
+
+func ParseClusterExternalID(v uuid.UUID) (ClusterExternalID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ClusterExternalID{}, err
+	}
+	return ClusterExternalID{v}, nil
+}
+
+func MustParseClusterExternalID(v uuid.UUID) ClusterExternalID {
+	v1, err := ParseClusterExternalID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ClusterTier holds a cluster's tiers as of a tiering or rollover. They stay in
+// force until the cluster's next tiering or rollover.
+type ClusterTier struct {
+	ClusterID                        uuid.UUID
+	TierAt                           Date
+	CalculatedResellerTierYearToDate *ResellerTier
+	CalculatedResellerTierMinimum    *ResellerTier
+}
+
+func (t ClusterTier) ResellerTier() (*ResellerTier, TierBasis) {
+	return higherResellerTier(t.CalculatedResellerTierYearToDate, t.CalculatedResellerTierMinimum)
+}
+
+// ClusterMember is the cluster's view of a reseller. The reseller aggregate
+// owns membership, but the cluster needs members and their roles to enforce
+// its invariants.
+type ClusterMember struct {
+	ResellerID uuid.UUID
+	Role       ResellerRole
+}
+
+// Cluster groups resellers under a single head for tiering. Net revenue of
+// members adds up to the cluster's net revenue, and every member gets the
+// cluster's tier. Calculated net revenue is in the revenue group's currency.
+type Cluster struct {
+	AggregateRoot
+	ExternalID                       ClusterExternalID
+	RevenueGroupID                   uuid.UUID
+	Members                          []ClusterMember
+	CalculatedResellerTierMinimum    *ResellerTier
+	CalculatedResellerTierYearToDate *ResellerTier
+	CalculatedNetRevenueYearToDate   *Money
+	CalculatedResellerTierProjected  *ResellerTier
+	CalculatedNetRevenueProjected    *Money
+	CalculatedNetRevenueLastYear     *Money
+	LastTieredAt                     *Date
+	// FiscalYear is the year of year-to-date net revenue.
+	FiscalYear int
+}
+
+func NewCluster(id ClusterID, externalID ClusterExternalID, revenueGroupID RevenueGroupID, headResellerID ResellerID, createdAt time.Time) Cluster {
+	c := Cluster{
+		ID:             id.V(),
+		CreatedAt:      createdAt,
+		ExternalID:     externalID,
+		RevenueGroupID: revenueGroupID.V(),
+		Members:        []ClusterMember{{ResellerID: headResellerID.V(), Role: ResellerRoleHead}},
+		FiscalYear:     createdAt.Year(),
+	}
+
+	c.AddDomainEvent(ClusterCreatedEvent{
+		OccurredAt:     createdAt,
+		ID:             id.V(),
+		ExternalID:     externalID.V(),
+		RevenueGroupID: revenueGroupID.V(),
+		HeadResellerID: headResellerID.V(),
+		FiscalYear:     c.FiscalYear,
+	})
+	return c
+}
+
+func (c *Cluster) Equal(other *Cluster) bool {
+	return EntityEqual(c, other)
+}
+
+func (c *Cluster) memberIndex(resellerID ResellerID) int {
+	return slices.IndexFunc(c.Members, func(m ClusterMember) bool {
+		return m.ResellerID == resellerID.V()
+	})
+}
+
+// Head returns the head, which a cluster always has exactly one of.
+func (c *Cluster) Head() ResellerID {
+	i := slices.IndexFunc(c.Members, func(m ClusterMember) bool { return m.Role == ResellerRoleHead })
+	Assert(i != -1, "cluster %s has no head", c.ID)
+	return MustParseResellerID(c.Members[i].ResellerID)
+}
+
+// AddMember adds a reseller as a member. The head is set when the cluster is
+// created, so a reseller added later is always a member.
+func (c *Cluster) AddMember(resellerID ResellerID, updatedAt time.Time) error {
+	if c.memberIndex(resellerID) != -1 {
+		return NewDomainError(
+			ClusterExpectedNonMember,
+			fmt.Sprintf("add member requires reseller %s not a member", resellerID))
+	}
+
+	c.Members = append(c.Members, ClusterMember{ResellerID: resellerID.V(), Role: ResellerRoleMember})
+	c.UpdatedAt = &updatedAt
+	c.AddDomainEvent(ClusterMemberAddedEvent{
+		OccurredAt: updatedAt,
+		ClusterID:  c.ID,
+		ResellerID: resellerID.V(),
+		Role:       ResellerRoleMember,
+	})
+	return nil
+}
+
+// RemoveMember keeps the head, as a cluster must have exactly one. To remove
+// the head, first change the head to another member. A cluster of only its
+// head keeps it, as there's no member to take over, until the cluster is
+// removed.
+func (c *Cluster) RemoveMember(resellerID ResellerID, updatedAt time.Time) error {
+	i := c.memberIndex(resellerID)
+	if i == -1 {
+		return NewDomainError(
+			ClusterExpectedMember,
+			fmt.Sprintf("remove member requires reseller %s a member", resellerID))
+	}
+	if c.Members[i].Role == ResellerRoleHead {
+		return NewDomainError(
+			ClusterExpectedNonHeadForRemoval,
+			fmt.Sprintf("remove member requires reseller %s not the head", resellerID))
+	}
+
+	c.Members = slices.Delete(c.Members, i, i+1)
+	c.UpdatedAt = &updatedAt
+	c.AddDomainEvent(ClusterMemberRemovedEvent{
+		OccurredAt: updatedAt,
+		ClusterID:  c.ID,
+		ResellerID: resellerID.V(),
+	})
+	return nil
+}
+
+// Remove dissolves the cluster. Every member, the head included, is removed
+// first, so its resellers become orphans.
+func (c *Cluster) Remove(removeAt time.Time) {
+	for _, m := range c.Members {
+		c.AddDomainEvent(ClusterMemberRemovedEvent{
+			OccurredAt: removeAt,
+			ClusterID:  c.ID,
+			ResellerID: m.ResellerID,
+		})
+	}
+	c.Members = nil
+	c.AddDomainEvent(ClusterRemovedEvent{
+		OccurredAt: removeAt,
+		ID:         c.ID,
+	})
+}
+
+// ChangeHead makes a member the head and the previous head a member, so the
+// cluster keeps exactly one head.
+func (c *Cluster) ChangeHead(resellerID ResellerID, updatedAt time.Time) error {
+	i := c.memberIndex(resellerID)
+	if i == -1 {
+		return NewDomainError(
+			ClusterExpectedMember,
+			fmt.Sprintf("change head requires reseller %s a member", resellerID))
+	}
+	if c.Members[i].Role == ResellerRoleHead {
+		return NewDomainError(
+			ClusterExpectedNonHeadForHead,
+			fmt.Sprintf("change head requires reseller %s not the head", resellerID))
+	}
+
+	previous := c.Head()
+	c.Members[c.memberIndex(previous)].Role = ResellerRoleMember
+	c.Members[i].Role = ResellerRoleHead
+	c.UpdatedAt = &updatedAt
+	c.AddDomainEvent(ClusterHeadChangedEvent{
+		OccurredAt:         updatedAt,
+		ClusterID:          c.ID,
+		PreviousResellerID: previous.V(),
+		ResellerID:         resellerID.V(),
+	})
+	return nil
+}
+
+func (c *Cluster) TieredOn(tierAt Date) bool {
+	return c.LastTieredAt != nil && c.LastTieredAt.Equal(tierAt)
+}
+
+// Tier sets the cluster's year-to-date tier from its net revenue. A cluster is
+// tiered at most once per date, and never for a date before it was last
+// tiered, or a late run would overwrite a more recent tier. A cluster not yet
+// rolled over into the year of tierAt holds last year's net revenue as year to
+// date, so it isn't tiered.
+func (c *Cluster) Tier(netRevenue Money, tier *ResellerTier, minimum *ResellerTier, tierAt Date, updatedAt time.Time) error {
+	if c.LastTieredAt != nil && !tierAt.After(*c.LastTieredAt) {
+		return NewDomainError(
+			ClusterExpectedTierAtAfterLast,
+			fmt.Sprintf("tier cluster requires tier at %s after last tiered at %s", tierAt, *c.LastTieredAt))
+	}
+	if c.FiscalYear != tierAt.Year() {
+		return NewDomainError(
+			ClusterExpectedFiscalYear,
+			fmt.Sprintf("tier cluster requires tier at %s in fiscal year %d", tierAt, c.FiscalYear))
+	}
+
+	c.CalculatedNetRevenueYearToDate = &netRevenue
+	c.CalculatedResellerTierYearToDate = tier
+	c.CalculatedResellerTierMinimum = minimum
+	c.LastTieredAt = &tierAt
+	c.UpdatedAt = &updatedAt
+	c.AddDomainEvent(ClusterTieredEvent{
+		OccurredAt:                       updatedAt,
+		ID:                               c.ID,
+		TierAt:                           tierAt,
+		CalculatedNetRevenueYearToDate:   netRevenue.Amount,
+		CalculatedResellerTierYearToDate: tier,
+		CalculatedResellerTierMinimum:    minimum,
+	})
+	return nil
+}
+
+// RollOver starts a fiscal year from the net revenue of the cluster's members
+// last year and the minimum tier it results in. Until tiered, the cluster's
+// tier is its minimum tier.
+func (c *Cluster) RollOver(fiscalYear int, netRevenueLastYear *Money, minimum *ResellerTier, updatedAt time.Time) error {
+	if fiscalYear <= c.FiscalYear {
+		return NewDomainError(
+			ClusterExpectedLaterYear,
+			fmt.Sprintf("roll over cluster requires fiscal year %d after %d", fiscalYear, c.FiscalYear))
+	}
+
+	c.CalculatedNetRevenueLastYear = netRevenueLastYear
+	c.CalculatedResellerTierMinimum = minimum
+	c.CalculatedNetRevenueYearToDate = nil
+	c.CalculatedResellerTierYearToDate = nil
+	c.CalculatedNetRevenueProjected = nil
+	c.CalculatedResellerTierProjected = nil
+	c.FiscalYear = fiscalYear
+	c.UpdatedAt = &updatedAt
+	c.AddDomainEvent(ClusterRolledOverEvent{
+		OccurredAt:                    updatedAt,
+		ID:                            c.ID,
+		FiscalYear:                    fiscalYear,
+		CalculatedNetRevenueLastYear:  moneyAmount(netRevenueLastYear),
+		CalculatedResellerTierMinimum: minimum,
+	})
+	return nil
+}
+
+// CheckBillingBookedAt refuses a member's billing booked before the cluster's
+// fiscal year. Rolling over fixed the cluster's net revenue for that year, so
+// the billing would count for the member but not for the cluster.
+func (c *Cluster) CheckBillingBookedAt(bookedAt Date) error {
+	if bookedAt.Year() < c.FiscalYear {
+		return NewDomainError(
+			ClusterExpectedBookedInFiscalYear,
+			fmt.Sprintf("record billing for cluster member requires booked at %s in fiscal year %d or later", bookedAt, c.FiscalYear))
+	}
+	return nil
+}
+
+// ResellerTier returns the tier of the cluster's resellers, which is the
+// higher of the year-to-date and minimum tiers, and which of them it is. On a
+// tie, the cluster earned its tier this year.
+func (c *Cluster) ResellerTier() (*ResellerTier, TierBasis) {
+	return higherResellerTier(c.CalculatedResellerTierYearToDate, c.CalculatedResellerTierMinimum)
+}
+
+func higherResellerTier(yearToDate *ResellerTier, minimum *ResellerTier) (*ResellerTier, TierBasis) {
+	if rankResellerTier(minimum) > rankResellerTier(yearToDate) {
+		return minimum, TierBasisMinimum
+	}
+	return yearToDate, TierBasisYearToDate
+}
+
+// Project sets the tier the cluster is projected to reach by year end. The
+// projection goes with the year-to-date tier it extrapolates, so the cluster
+// must be tiered on projectAt first.
+func (c *Cluster) Project(netRevenue Money, tier *ResellerTier, projectAt Date, updatedAt time.Time) error {
+	if !c.TieredOn(projectAt) {
+		return NewDomainError(
+			ClusterExpectedTieredForProject,
+			fmt.Sprintf("project cluster requires cluster tiered at %s", projectAt))
+	}
+
+	c.CalculatedNetRevenueProjected = &netRevenue
+	c.CalculatedResellerTierProjected = tier
+	c.UpdatedAt = &updatedAt
+	c.AddDomainEvent(ClusterTierProjectedEvent{
+		OccurredAt:                      updatedAt,
+		ID:                              c.ID,
+		CalculatedNetRevenueProjected:   netRevenue.Amount,
+		CalculatedResellerTierProjected: tier,
+	})
+	return nil
+}
+
+// publishClusterEvents has the reseller react to membership changes among the
+// cluster's pending events. The cluster doesn't change the reseller directly,
+// so invariants of the reseller stay with the reseller.
+func publishClusterEvents(cluster *Cluster, reseller *Reseller) error {
+	for _, event := range cluster.DomainEvents {
+		switch e := event.(type) {
+		case ClusterCreatedEvent:
+			if e.HeadResellerID == reseller.ID {
+				if err := reseller.JoinCluster(e.ID, ResellerRoleHead, e.OccurredAt); err != nil {
+					return err
+				}
+			}
+		case ClusterMemberAddedEvent:
+			if e.ResellerID == reseller.ID {
+				if err := reseller.JoinCluster(e.ClusterID, e.Role, e.OccurredAt); err != nil {
+					return err
+				}
+			}
+		case ClusterMemberRemovedEvent:
+			if e.ResellerID == reseller.ID {
+				if err := reseller.LeaveCluster(e.ClusterID, e.OccurredAt); err != nil {
+					return err
+				}
+			}
+		case ClusterHeadChangedEvent:
+			var role ResellerRole
+			switch reseller.ID {
+			case e.PreviousResellerID:
+				role = ResellerRoleMember
+			case e.ResellerID:
+				role = ResellerRoleHead
+			default:
+				continue
+			}
+			if err := reseller.ChangeClusterRole(e.ClusterID, role, e.OccurredAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Application
+
+type CreateClusterCommand struct {
+	ID             uuid.UUID
+	ExternalID     uuid.UUID
+	RevenueGroupID uuid.UUID
+	HeadResellerID uuid.UUID
+}
+
+type CreateClusterHandler struct {
+	Clusters      ClusterStore
+	Resellers     ResellerStore
+	RevenueGroups RevenueGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h CreateClusterHandler) Handle(ctx context.Context, req CreateClusterCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseClusterID)
+	externalID := parser.Parse("ExternalID", req.ExternalID, ParseClusterExternalID)
+	revenueGroupID := parser.Parse("RevenueGroupID", req.RevenueGroupID, ParseRevenueGroupID)
+	headResellerID := parser.Parse("HeadResellerID", req.HeadResellerID, ParseResellerID)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	exist, err := h.Clusters.ExistByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("Cluster", "ID", id.String())
+	}
+
+	exist, err = h.Clusters.ExistByExternalID(ctx, externalID)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("Cluster", "ExternalID", externalID.String())
+	}
+
+	exist, err = h.RevenueGroups.ExistByID(ctx, revenueGroupID)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return NewNotFoundError("RevenueGroup", "ID", revenueGroupID.String())
+	}
+
+	reseller, err := h.Resellers.GetByID(ctx, headResellerID)
+	if err != nil {
+		return err
+	}
+	if reseller == nil {
+		return NewNotFoundError("Reseller", "ID", headResellerID.String())
+	}
+
+	cluster := NewCluster(id, externalID, revenueGroupID, headResellerID, h.Clock.NowUTC())
+	if err := publishClusterEvents(&cluster, reseller); err != nil {
+		return err
+	}
+
+	// Cluster before reseller, as the reseller refers to the cluster.
+	return h.Projector.Apply(ctx, &cluster, reseller)
+}
+
+type AddClusterMemberCommand struct {
+	ClusterID       uuid.UUID
+	ResellerID      uuid.UUID
+	ExpectedVersion *int32
+}
+
+type AddClusterMemberHandler struct {
+	Clusters  ClusterStore
+	Resellers ResellerStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h AddClusterMemberHandler) Handle(ctx context.Context, req AddClusterMemberCommand) error {
+	parser := &RequestParseCollector{}
+	clusterID := parser.Parse("ClusterID", req.ClusterID, ParseClusterID)
+	resellerID := parser.Parse("ResellerID", req.ResellerID, ParseResellerID)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	cluster, err := h.Clusters.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return NewNotFoundError("Cluster", "ID", clusterID.String())
+	}
+	if err := cluster.CheckVersion("Cluster", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	reseller, err := h.Resellers.GetByID(ctx, resellerID)
+	if err != nil {
+		return err
+	}
+	if reseller == nil {
+		return NewNotFoundError("Reseller", "ID", resellerID.String())
+	}
+
+	if err := cluster.AddMember(resellerID, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	if err := publishClusterEvents(cluster, reseller); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, cluster, reseller)
+}
+
+type RemoveClusterMemberCommand struct {
+	ClusterID       uuid.UUID
+	ResellerID      uuid.UUID
+	ExpectedVersion *int32
+}
+
+type RemoveClusterMemberHandler struct {
+	Clusters  ClusterStore
+	Resellers ResellerStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h RemoveClusterMemberHandler) Handle(ctx context.Context, req RemoveClusterMemberCommand) error {
+	parser := &RequestParseCollector{}
+	clusterID := parser.Parse("ClusterID", req.ClusterID, ParseClusterID)
+	resellerID := parser.Parse("ResellerID", req.ResellerID, ParseResellerID)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	cluster, err := h.Clusters.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return NewNotFoundError("Cluster", "ID", clusterID.String())
+	}
+	if err := cluster.CheckVersion("Cluster", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	reseller, err := h.Resellers.GetByID(ctx, resellerID)
+	if err != nil {
+		return err
+	}
+	if reseller == nil {
+		return NewNotFoundError("Reseller", "ID", resellerID.String())
+	}
+
+	if err := cluster.RemoveMember(resellerID, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	if err := publishClusterEvents(cluster, reseller); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, cluster, reseller)
+}
+
+type ChangeClusterHeadCommand struct {
+	ClusterID       uuid.UUID
+	ResellerID      uuid.UUID
+	ExpectedVersion *int32
+}
+
+type ChangeClusterHeadHandler struct {
+	Clusters  ClusterStore
+	Resellers ResellerStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h ChangeClusterHeadHandler) Handle(ctx context.Context, req ChangeClusterHeadCommand) error {
+	parser := &RequestParseCollector{}
+	clusterID := parser.Parse("ClusterID", req.ClusterID, ParseClusterID)
+	resellerID := parser.Parse("ResellerID", req.ResellerID, ParseResellerID)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	cluster, err := h.Clusters.GetByID(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return NewNotFoundError("Cluster", "ID", clusterID.String())
+	}
+	if err := cluster.CheckVersion("Cluster", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	previousHeadID := cluster.Head()
+	if err := cluster.ChangeHead(resellerID, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+
+	// The previous and the new head both react to the change.
+	aggregates := []Aggregate{cluster}
+	for _, id := range []ResellerID{previousHeadID, resellerID} {
+		reseller, err := h.Resellers.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if reseller == nil {
+			return NewNotFoundError("Reseller", "ID", id.String())
+		}
+		if err := publishClusterEvents(cluster, reseller); err != nil {
+			return err
+		}
+		aggregates = append(aggregates, reseller)
+	}
+	return h.Projector.Apply(ctx, aggregates...)
+}
+
+type RemoveClusterCommand struct {
+	ID              uuid.UUID
+	ExpectedVersion *int32
+}
+
+type RemoveClusterHandler struct {
+	Clusters  ClusterStore
+	Resellers ResellerStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h RemoveClusterHandler) Handle(ctx context.Context, req RemoveClusterCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseClusterID)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	cluster, err := h.Clusters.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return NewNotFoundError("Cluster", "ID", id.String())
+	}
+	if err := cluster.CheckVersion("Cluster", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	members := cluster.Members
+	cluster.Remove(h.Clock.NowUTC())
+
+	// Every member reacts to its removal.
+	var aggregates []Aggregate
+	for _, m := range members {
+		resellerID := MustParseResellerID(m.ResellerID)
+		reseller, err := h.Resellers.GetByID(ctx, resellerID)
+		if err != nil {
+			return err
+		}
+		if reseller == nil {
+			return NewNotFoundError("Reseller", "ID", resellerID.String())
+		}
+		if err := publishClusterEvents(cluster, reseller); err != nil {
+			return err
+		}
+		aggregates = append(aggregates, reseller)
+	}
+
+	// Resellers before cluster, as the resellers refer to the cluster.
+	return h.Projector.Apply(ctx, append(aggregates, cluster)...)
+}
+
+type ClusterMemberResponse struct {
+	ResellerID uuid.UUID    `json:"reseller_id"`
+	Role       ResellerRole `json:"role"`
+}
+
+type ClusterResponse struct {
+	ID                               uuid.UUID               `json:"id"`
+	Version                          int32                   `json:"-"`
+	ExternalID                       uuid.UUID               `json:"external_id"`
+	RevenueGroupID                   uuid.UUID               `json:"revenue_group_id"`
+	Members                          []ClusterMemberResponse `json:"members"`
+	ResellerTier                     *ResellerTier           `json:"reseller_tier"`
+	ResellerTierBasis                TierBasis               `json:"reseller_tier_basis"`
+	CalculatedResellerTierMinimum    *ResellerTier           `json:"calculated_reseller_tier_minimum"`
+	CalculatedResellerTierYearToDate *ResellerTier           `json:"calculated_reseller_tier_year_to_date"`
+	CalculatedNetRevenueYearToDate   *Money                  `json:"calculated_net_revenue_year_to_date"`
+	CalculatedResellerTierProjected  *ResellerTier           `json:"calculated_reseller_tier_projected"`
+	CalculatedNetRevenueProjected    *Money                  `json:"calculated_net_revenue_projected"`
+	CalculatedNetRevenueLastYear     *Money                  `json:"calculated_net_revenue_last_year"`
+	LastTieredAt                     *Date                   `json:"last_tiered_at"`
+	FiscalYear                       int                     `json:"fiscal_year"`
+	CreatedAt                        time.Time               `json:"created_at"`
+	UpdatedAt                        *time.Time              `json:"updated_at"`
+}
+
+func newClusterResponse(cluster *Cluster) *ClusterResponse {
+	members := make([]ClusterMemberResponse, len(cluster.Members))
+	for i, m := range cluster.Members {
+		members[i] = ClusterMemberResponse{
+			ResellerID: m.ResellerID,
+			Role:       m.Role,
+		}
+	}
+	tier, basis := cluster.ResellerTier()
+	return &ClusterResponse{
+		ID:                               cluster.ID,
+		Version:                          cluster.Version,
+		ExternalID:                       cluster.ExternalID.V(),
+		RevenueGroupID:                   cluster.RevenueGroupID,
+		Members:                          members,
+		ResellerTier:                     tier,
+		ResellerTierBasis:                basis,
+		CalculatedResellerTierMinimum:    cluster.CalculatedResellerTierMinimum,
+		CalculatedResellerTierYearToDate: cluster.CalculatedResellerTierYearToDate,
+		CalculatedNetRevenueYearToDate:   cluster.CalculatedNetRevenueYearToDate,
+		CalculatedResellerTierProjected:  cluster.CalculatedResellerTierProjected,
+		CalculatedNetRevenueProjected:    cluster.CalculatedNetRevenueProjected,
+		CalculatedNetRevenueLastYear:     cluster.CalculatedNetRevenueLastYear,
+		LastTieredAt:                     cluster.LastTieredAt,
+		FiscalYear:                       cluster.FiscalYear,
+		CreatedAt:                        cluster.CreatedAt,
+		UpdatedAt:                        cluster.UpdatedAt,
+	}
+}
+
+type GetClusterQuery struct {
+	ID uuid.UUID
+}
+
+type GetClusterHandler struct {
+	Clusters ClusterStore
+}
+
+func (h GetClusterHandler) Handle(ctx context.Context, req GetClusterQuery) (*ClusterResponse, error) {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseClusterID)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	cluster, err := h.Clusters.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return nil, NewNotFoundError("Cluster", "ID", id.String())
+	}
+	return newClusterResponse(cluster), nil
+}
+
+// ClusterTierProjectionResponse compares a cluster's tier from year-to-date
+// net revenue with its tier projected to year end, as of the last tiering.
+// Both are nil until the cluster is tiered.
+type ClusterTierProjectionResponse struct {
+	ClusterID                        uuid.UUID     `json:"cluster_id"`
+	ProjectedAt                      *Date         `json:"projected_at"`
+	CalculatedResellerTierYearToDate *ResellerTier `json:"calculated_reseller_tier_year_to_date"`
+	CalculatedNetRevenueYearToDate   *Money        `json:"calculated_net_revenue_year_to_date"`
+	CalculatedResellerTierProjected  *ResellerTier `json:"calculated_reseller_tier_projected"`
+	CalculatedNetRevenueProjected    *Money        `json:"calculated_net_revenue_projected"`
+}
+
+type GetClusterTierProjectionQuery struct {
+	ClusterID uuid.UUID
+}
+
+type GetClusterTierProjectionHandler struct {
+	Clusters ClusterStore
+}
+
+func (h GetClusterTierProjectionHandler) Handle(ctx context.Context, req GetClusterTierProjectionQuery) (*ClusterTierProjectionResponse, error) {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ClusterID", req.ClusterID, ParseClusterID)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	cluster, err := h.Clusters.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		return nil, NewNotFoundError("Cluster", "ID", id.String())
+	}
+	return &ClusterTierProjectionResponse{
+		ClusterID:                        cluster.ID,
+		ProjectedAt:                      cluster.LastTieredAt,
+		CalculatedResellerTierYearToDate: cluster.CalculatedResellerTierYearToDate,
+		CalculatedNetRevenueYearToDate:   cluster.CalculatedNetRevenueYearToDate,
+		CalculatedResellerTierProjected:  cluster.CalculatedResellerTierProjected,
+		CalculatedNetRevenueProjected:    cluster.CalculatedNetRevenueProjected,
+	}, nil
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterMembership(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	head := newTestReseller(at)
+	member := newTestReseller(at)
+	headID := MustParseResellerID(head.ID)
+	memberID := MustParseResellerID(member.ID)
+
+	c := NewCluster(
+		MustParseClusterID(uuid.New()),
+		MustParseClusterExternalID(uuid.New()),
+		MustParseRevenueGroupID(uuid.New()),
+		headID,
+		at)
+	require.NoError(t, publishClusterEvents(&c, &head))
+	assert.Equal(t, ResellerRoleHead, head.ResellerRole)
+	assert.Equal(t, c.ID, *head.ClusterID)
+	c.ClearDomainEvents()
+
+	var e *DomainError
+	require.ErrorAs(t, c.AddMember(headID, at), &e)
+	assert.Equal(t, ClusterExpectedNonMember, e.Code)
+	require.ErrorAs(t, c.RemoveMember(memberID, at), &e)
+	assert.Equal(t, ClusterExpectedMember, e.Code)
+	require.ErrorAs(t, c.RemoveMember(headID, at), &e)
+	assert.Equal(t, ClusterExpectedNonHeadForRemoval, e.Code)
+
+	require.NoError(t, c.AddMember(memberID, at))
+	require.NoError(t, publishClusterEvents(&c, &member))
+	assert.Equal(t, ResellerRoleMember, member.ResellerRole)
+	c.ClearDomainEvents()
+
+	require.NoError(t, c.RemoveMember(memberID, at))
+	require.NoError(t, publishClusterEvents(&c, &member))
+	assert.Equal(t, ResellerRoleOrphan, member.ResellerRole)
+	assert.Nil(t, member.ClusterID)
+	assert.Equal(t, []ClusterMember{{ResellerID: head.ID, Role: ResellerRoleHead}}, c.Members)
+}
+
+func TestClusterChangeHead(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	head := newTestReseller(at)
+	member := newTestReseller(at)
+	headID := MustParseResellerID(head.ID)
+	memberID := MustParseResellerID(member.ID)
+	c := NewCluster(
+		MustParseClusterID(uuid.New()),
+		MustParseClusterExternalID(uuid.New()),
+		MustParseRevenueGroupID(uuid.New()),
+		headID,
+		at)
+	require.NoError(t, c.AddMember(memberID, at))
+	require.NoError(t, publishClusterEvents(&c, &head))
+	require.NoError(t, publishClusterEvents(&c, &member))
+	c.ClearDomainEvents()
+	head.ClearDomainEvents()
+	member.ClearDomainEvents()
+
+	var e *DomainError
+	require.ErrorAs(t, c.ChangeHead(headID, at), &e)
+	assert.Equal(t, ClusterExpectedNonHeadForHead, e.Code)
+	require.ErrorAs(t, c.ChangeHead(MustParseResellerID(uuid.New()), at), &e)
+	assert.Equal(t, ClusterExpectedMember, e.Code)
+
+	require.NoError(t, c.ChangeHead(memberID, at))
+	require.NoError(t, publishClusterEvents(&c, &head))
+	require.NoError(t, publishClusterEvents(&c, &member))
+	assert.Equal(t, memberID, c.Head())
+	assert.Equal(t, ResellerRoleMember, head.ResellerRole)
+	assert.Equal(t, ResellerRoleHead, member.ResellerRole)
+	require.Len(t, head.DomainEvents, 1)
+	assert.Equal(t, ResellerRoleMember, head.DomainEvents[0].(ResellerRoleChangedEvent).Role)
+	c.ClearDomainEvents()
+
+	// The previous head may now leave the cluster and be unenrolled.
+	require.NoError(t, c.RemoveMember(headID, at))
+	require.NoError(t, publishClusterEvents(&c, &head))
+	require.NoError(t, head.Unenroll(at))
+	assert.Equal(t, []ClusterMember{{ResellerID: member.ID, Role: ResellerRoleHead}}, c.Members)
+}
+
+func TestClusterRemove(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	head := newTestReseller(at)
+	member := newTestReseller(at)
+	c := NewCluster(
+		MustParseClusterID(uuid.New()),
+		MustParseClusterExternalID(uuid.New()),
+		MustParseRevenueGroupID(uuid.New()),
+		MustParseResellerID(head.ID),
+		at)
+	require.NoError(t, c.AddMember(MustParseResellerID(member.ID), at))
+	require.NoError(t, publishClusterEvents(&c, &head))
+	require.NoError(t, publishClusterEvents(&c, &member))
+	c.ClearDomainEvents()
+
+	c.Remove(at)
+	require.NoError(t, publishClusterEvents(&c, &head))
+	require.NoError(t, publishClusterEvents(&c, &member))
+	assert.Empty(t, c.Members)
+	assert.IsType(t, ClusterRemovedEvent{}, c.DomainEvents[len(c.DomainEvents)-1])
+	for _, r := range []*Reseller{&head, &member} {
+		assert.Nil(t, r.ClusterID)
+		assert.Equal(t, ResellerRoleOrphan, r.ResellerRole)
+		require.NoError(t, r.Unenroll(at))
+	}
+}
+
+func TestClusterJoinSecondClusterInvalid(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestReseller(at)
+	require.NoError(t, r.JoinCluster(uuid.New(), ResellerRoleMember, at))
+
+	var e *DomainError
+	require.ErrorAs(t, r.JoinCluster(uuid.New(), ResellerRoleMember, at), &e)
+	assert.Equal(t, ResellerExpectedNoCluster, e.Code)
+	require.ErrorAs(t, r.LeaveCluster(uuid.New(), at), &e)
+	assert.Equal(t, ResellerExpectedCluster, e.Code)
+}
+
+func TestClusterTier(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	head := newTestReseller(at)
+	c := NewCluster(
+		MustParseClusterID(uuid.New()),
+		MustParseClusterExternalID(uuid.New()),
+		MustParseRevenueGroupID(uuid.New()),
+		MustParseResellerID(head.ID),
+		at)
+	c.ClearDomainEvents()
+	tierAt := DateFromTime(at)
+	netRevenue := NewMoney(MustParseDecimal("250"), MustParseCurrencyCode("DKK"))
+	tier := ResellerTierAdvanced
+
+	assert.False(t, c.TieredOn(tierAt))
+	require.NoError(t, c.Tier(netRevenue, &tier, nil, tierAt, at))
+	assert.True(t, c.TieredOn(tierAt))
+	assert.Equal(t, &netRevenue, c.CalculatedNetRevenueYearToDate)
+	assert.Equal(t, &tier, c.CalculatedResellerTierYearToDate)
+	require.Len(t, c.DomainEvents, 1)
+
+	var e *DomainError
+	require.ErrorAs(t, c.Tier(netRevenue, nil, nil, tierAt, at), &e)
+	assert.Equal(t, ClusterExpectedTierAtAfterLast, e.Code)
+	require.ErrorAs(t, c.Tier(netRevenue, nil, nil, tierAt.AddDate(0, 0, -1), at), &e)
+	assert.Equal(t, ClusterExpectedTierAtAfterLast, e.Code)
+
+	require.NoError(t, c.Tier(netRevenue, nil, nil, tierAt.AddDate(0, 0, 1), at))
+	assert.Nil(t, c.CalculatedResellerTierYearToDate)
+}
+
+func TestClusterProject(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	head := newTestReseller(at)
+	c := NewCluster(
+		MustParseClusterID(uuid.New()),
+		MustParseClusterExternalID(uuid.New()),
+		MustParseRevenueGroupID(uuid.New()),
+		MustParseResellerID(head.ID),
+		at)
+	c.ClearDomainEvents()
+	tierAt := DateFromTime(at)
+	netRevenue := NewMoney(MustParseDecimal("250"), MustParseCurrencyCode("DKK"))
+	projected := ProjectLinear(netRevenue, tierAt)
+	tier := ResellerTierPremier
+
+	var e *DomainError
+	require.ErrorAs(t, c.Project(projected, &tier, tierAt, at), &e)
+	assert.Equal(t, ClusterExpectedTieredForProject, e.Code)
+
+	require.NoError(t, c.Tier(netRevenue, nil, nil, tierAt, at))
+	require.NoError(t, c.Project(projected, &tier, tierAt, at))
+	assert.Equal(t, &projected, c.CalculatedNetRevenueProjected)
+	assert.Equal(t, &tier, c.CalculatedResellerTierProjected)
+	require.Len(t, c.DomainEvents, 2)
+
+	require.ErrorAs(t, c.Project(projected, &tier, tierAt.AddDate(0, 0, 1), at), &e)
+	assert.Equal(t, ClusterExpectedTieredForProject, e.Code)
+}
+
+func TestClusterResellerTier(t *testing.T) {
+	tests := map[string]struct {
+		yearToDate    *ResellerTier
+		minimum       *ResellerTier
+		expected      *ResellerTier
+		expectedBasis TierBasis
+	}{
+		"no tier":            {nil, nil, nil, TierBasisYearToDate},
+		"above minimum":      {tierPtr(ResellerTierPremier), tierPtr(ResellerTierAdvanced), tierPtr(ResellerTierPremier), TierBasisYearToDate},
+		"at minimum":         {tierPtr(ResellerTierAdvanced), tierPtr(ResellerTierAdvanced), tierPtr(ResellerTierAdvanced), TierBasisYearToDate},
+		"below minimum":      {tierPtr(ResellerTierAuthorized), tierPtr(ResellerTierAdvanced), tierPtr(ResellerTierAdvanced), TierBasisMinimum},
+		"no tier of its own": {nil, tierPtr(ResellerTierAuthorized), tierPtr(ResellerTierAuthorized), TierBasisMinimum},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := Cluster{CalculatedResellerTierYearToDate: tt.yearToDate, CalculatedResellerTierMinimum: tt.minimum}
+			tier, basis := c.ResellerTier()
+			assert.Equal(t, tt.expected, tier)
+			assert.Equal(t, tt.expectedBasis, basis)
+		})
+	}
+}
+
+func TestClusterRollOver(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	head := newTestReseller(at)
+	c := NewCluster(
+		MustParseClusterID(uuid.New()),
+		MustParseClusterExternalID(uuid.New()),
+		MustParseRevenueGroupID(uuid.New()),
+		MustParseResellerID(head.ID),
+		at)
+	c.ClearDomainEvents()
+	tierAt := DateFromTime(at)
+	netRevenue := NewMoney(MustParseDecimal("250"), MustParseCurrencyCode("DKK"))
+	tier := ResellerTierAdvanced
+	require.NoError(t, c.Tier(netRevenue, &tier, nil, tierAt, at))
+	require.NoError(t, c.Project(ProjectLinear(netRevenue, tierAt), &tier, tierAt, at))
+
+	// Not yet rolled over into 2027, the cluster isn't tiered in 2027.
+	var e *DomainError
+	nextYear := NewDate(2027, 1, 1)
+	require.ErrorAs(t, c.Tier(netRevenue, &tier, nil, nextYear, at), &e)
+	assert.Equal(t, ClusterExpectedFiscalYear, e.Code)
+
+	minimum := ResellerTierAuthorized
+	require.NoError(t, c.RollOver(2027, &netRevenue, &minimum, at))
+	assert.Equal(t, 2027, c.FiscalYear)
+	assert.Equal(t, &netRevenue, c.CalculatedNetRevenueLastYear)
+	assert.Equal(t, &minimum, c.CalculatedResellerTierMinimum)
+	assert.Nil(t, c.CalculatedNetRevenueYearToDate)
+	assert.Nil(t, c.CalculatedResellerTierYearToDate)
+	assert.Nil(t, c.CalculatedNetRevenueProjected)
+	assert.Nil(t, c.CalculatedResellerTierProjected)
+	actual, basis := c.ResellerTier()
+	assert.Equal(t, &minimum, actual)
+	assert.Equal(t, TierBasisMinimum, basis)
+
+	require.ErrorAs(t, c.RollOver(2027, nil, nil, at), &e)
+	assert.Equal(t, ClusterExpectedLaterYear, e.Code)
+	require.ErrorAs(t, c.CheckBillingBookedAt(NewDate(2026, 12, 31)), &e)
+	assert.Equal(t, ClusterExpectedBookedInFiscalYear, e.Code)
+	require.NoError(t, c.CheckBillingBookedAt(nextYear))
+	require.NoError(t, c.Tier(netRevenue, &tier, &minimum, nextYear, at))
+}
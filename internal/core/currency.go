@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 	"uuid"
 )
@@ -14,6 +15,18 @@ type CurrencyStore interface {
 	ExistByID(context.Context, CurrencyID) (bool, error)
 	ExistByCode(context.Context, CurrencyCode) (bool, error)
 	GetByCode(context.Context, CurrencyCode) (*Currency, error)
+	List(context.Context, CurrencyCriteria) ([]*Currency, error)
+}
+
+// CurrencyCriteria selects currencies ordered by code. A nil filter field
+// doesn't filter.
+type CurrencyCriteria struct {
+	// HasFutureExchangeRates selects currencies with (true) or without (false)
+	// an exchange rate whose from is after Today.
+	HasFutureExchangeRates *bool
+	Today                  Date
+	AfterCode              *CurrencyCode
+	Limit                  int
 }
 
 type CurrencyCreatedEvent struct {
@@ -26,7 +39,7 @@ type ExchangeRateAddedEvent struct {
 	domainEventCommon
 	CurrencyID     uuid.UUID `json:"currency_id"`
 	ExchangeRateID uuid.UUID `json:"exchange_rate_id"`
-	Rate           float64   `json:"rate"`
+	Rate           Decimal   `json:"rate"`
 	From           Date      `json:"from"`
 }
 
@@ -34,7 +47,7 @@ type ExchangeRateUpdatedEvent struct {
 	domainEventCommon
 	CurrencyID     uuid.UUID `json:"currency_id"`
 	ExchangeRateID uuid.UUID `json:"exchange_rate_id"`
-	Rate           float64   `json:"rate"`
+	Rate           Decimal   `json:"rate"`
 	From           Date      `json:"from"`
 }
 
@@ -49,18 +62,42 @@ type CurrencyRemovedEvent struct {
 	ID uuid.UUID `json:"id"`
 }
 
+// NoExchangeRateError signals that no exchange rate of a currency is in effect
+// on a date, i.e., the currency has no rates or every rate is from a later
+// date.
+type NoExchangeRateError struct {
+	Code string
+	On   Date
+}
+
+func NewNoExchangeRateError(code CurrencyCode, on Date) *NoExchangeRateError {
+	return &NoExchangeRateError{Code: code.V(), On: on}
+}
+
+func (e *NoExchangeRateError) Error() string {
+	return fmt.Sprintf("no exchange rate for currency %s in effect on %s", e.Code, e.On)
+}
+
 const (
-	CurrencyAddRequiresFutureFrom    = 1600
-	CurrencyUpdateRequiresFutureFrom = 1601
-	CurrencyRemoveRequiresFutureFrom = 1602
-	CurrencyUpdateRequiresChange     = 1603
+	CurrencyAddRequiresFutureFrom     = 1600
+	CurrencyUpdateRequiresFutureFrom  = 1601
+	CurrencyRemoveRequiresFutureFrom  = 1602
+	CurrencyUpdateRequiresChange      = 1603
+	CurrencyReplaceRequiresFutureFrom = 1604
+	CurrencyImportRequiresLatestFrom  = 1605
 )
 
 const (
-	ExchangeRateMin              float64 = 1.
-	ExchangeRateMax              float64 = 100.
-	ExchangeRateDecimalPlacesMin         = 0
-	ExchangeRateDecimalPlacesMax         = 6
+	ExchangeRateDecimalPlacesMin = 0
+	ExchangeRateDecimalPlacesMax = 10
+)
+
+// Rates span currencies worth a fraction of a unit of the reporting currency,
+// e.g., JPY, to currencies worth many units, e.g., KWD, whichever currency
+// reports.
+var (
+	ExchangeRateMin = MustParseDecimal("0.0001")
+	ExchangeRateMax = NewDecimalFromInt(100000)
 )
 
 // CurrencyID
@@ -112,17 +149,17 @@ func MustParseExchangeRateId(v uuid.UUID) ExchangeRateID {
 }
 
 type Rate struct {
-	v float64
+	v Decimal
 }
 
-func (r Rate) V() float64 { return r.v }
+func (r Rate) V() Decimal { return r.v }
 
-func ParseRate(v float64) (Rate, error) {
+func ParseRate(v Decimal) (Rate, error) {
 	errs := &FieldParseError{}
-	if err := ValidateFloat64InclusiveRange(v, ExchangeRateMin, ExchangeRateMax); err != nil {
+	if err := ValidateDecimalInclusiveRange(v, ExchangeRateMin, ExchangeRateMax); err != nil {
 		errs.Add(err.Error())
 	}
-	if err := ValidateFloat64DecimalPlaces(v, ExchangeRateDecimalPlacesMin, ExchangeRateDecimalPlacesMax); err != nil {
+	if err := ValidateDecimalPlaces(v, ExchangeRateDecimalPlacesMin, ExchangeRateDecimalPlacesMax); err != nil {
 		errs.Add(err.Error())
 	}
 	if err := errs.NilOrError(); err != nil {
@@ -131,7 +168,7 @@ func ParseRate(v float64) (Rate, error) {
 	return Rate{v}, nil
 }
 
-func MustParseRate(v float64) Rate {
+func MustParseRate(v Decimal) Rate {
 	v1, err := ParseRate(v)
 	if err != nil {
 		panic(err)
@@ -193,7 +230,7 @@ func (e *ExchangeRate) Update(rate Rate, from ExchangeRateFrom, updatedAt time.T
 	if e.Rate == rate && e.From == from {
 		return NewDomainError(
 			CurrencyUpdateRequiresChange,
-			fmt.Sprintf("update exchange rate requires a rate different from %g and/or a from different from %s", rate.V(), from))
+			fmt.Sprintf("update exchange rate requires a rate different from %s and/or a from different from %s", rate.V(), from))
 	}
 
 	e.Rate = rate
@@ -233,13 +270,8 @@ func (e *Currency) Equal(other *Currency) bool {
 }
 
 func (c *Currency) AddExchangeRate(exchangeRate ExchangeRate, createdAt time.Time) error {
-	for _, e := range c.ExchangeRates {
-		if e.ID == exchangeRate.ID {
-			return NewConflictError("ExchangeRate", "ID", exchangeRate.ID.String())
-		}
-		if e.From == exchangeRate.From {
-			return NewConflictError("ExchangeRate", "From", exchangeRate.From.String())
-		}
+	if err := c.checkExchangeRateUnique(exchangeRate); err != nil {
+		return err
 	}
 
 	today := DateFromTime(createdAt)
@@ -249,6 +281,45 @@ func (c *Currency) AddExchangeRate(exchangeRate ExchangeRate, createdAt time.Tim
 			fmt.Sprintf("add exchange rate requires from %s be after today %s", exchangeRate.From, today.String()))
 	}
 
+	c.appendExchangeRate(exchangeRate, createdAt)
+	return nil
+}
+
+// ImportExchangeRate adds a rate from a rate file. Unlike AddExchangeRate, the
+// rate may be from today or earlier, as rate files publish rates as of the day
+// they're in effect from. A rate from today or earlier must be from after the
+// rate in effect today, so that an import never changes which rate was in
+// effect between two existing rates, and with it conversions at booking dates.
+func (c *Currency) ImportExchangeRate(exchangeRate ExchangeRate, createdAt time.Time) error {
+	if err := c.checkExchangeRateUnique(exchangeRate); err != nil {
+		return err
+	}
+
+	today := DateFromTime(createdAt)
+	from := exchangeRate.From.V()
+	if effective, ok := effectiveOn(c.ExchangeRates, exchangeRateFrom, today); ok && !from.After(today) && !from.After(effective.From.V()) {
+		return NewDomainError(
+			CurrencyImportRequiresLatestFrom,
+			fmt.Sprintf("import exchange rate requires from %s be after today or after the latest rate from %s", from, effective.From))
+	}
+
+	c.appendExchangeRate(exchangeRate, createdAt)
+	return nil
+}
+
+func (c *Currency) checkExchangeRateUnique(exchangeRate ExchangeRate) error {
+	for _, e := range c.ExchangeRates {
+		if e.ID == exchangeRate.ID {
+			return NewConflictError("ExchangeRate", "ID", exchangeRate.ID.String())
+		}
+		if e.From == exchangeRate.From {
+			return NewConflictError("ExchangeRate", "From", exchangeRate.From.String())
+		}
+	}
+	return nil
+}
+
+func (c *Currency) appendExchangeRate(exchangeRate ExchangeRate, createdAt time.Time) {
 	c.ExchangeRates = append(c.ExchangeRates, &exchangeRate)
 	c.AddDomainEvent(ExchangeRateAddedEvent{
 		OccurredAt:     createdAt,
@@ -257,7 +328,6 @@ func (c *Currency) AddExchangeRate(exchangeRate ExchangeRate, createdAt time.Tim
 		Rate:           exchangeRate.Rate.V(),
 		From:           exchangeRate.From.V(),
 	})
-	return nil
 }
 
 func (c *Currency) UpdateExchangeRate(exchangeRateId ExchangeRateID, rate Rate, from ExchangeRateFrom, updatedAt time.Time) error {
@@ -324,6 +394,111 @@ func (c *Currency) RemoveExchangeRate(exchangeRateID ExchangeRateID, updatedAt t
 	return nil
 }
 
+// ReplaceFutureExchangeRates makes schedule the currency's future exchange
+// rates. Rates are matched on from as it's unique within a currency: a matched
+// rate with a different rate is updated, an unmatched rate in schedule is
+// added, and a future rate missing from schedule is removed. Exchange rate IDs
+// in schedule are only used for added rates.
+//
+// Changes go through the same methods as individual changes, so their rules
+// apply. Should one fail part way, the currency is left half replaced in
+// memory only, as the caller doesn't apply it.
+func (c *Currency) ReplaceFutureExchangeRates(schedule []ExchangeRate, updatedAt time.Time) error {
+	today := DateFromTime(updatedAt)
+	scheduled := make(map[ExchangeRateFrom]*ExchangeRate, len(schedule))
+	for i := range schedule {
+		e := &schedule[i]
+		if !e.From.V().After(today) {
+			return NewDomainError(
+				CurrencyReplaceRequiresFutureFrom,
+				fmt.Sprintf("replace future exchange rates requires from %s be after today %s", e.From, today.String()))
+		}
+		if _, ok := scheduled[e.From]; ok {
+			return NewConflictError("ExchangeRate", "From", e.From.String())
+		}
+		scheduled[e.From] = e
+	}
+
+	var removed, updated []*ExchangeRate
+	existing := make(map[ExchangeRateFrom]*ExchangeRate, len(c.ExchangeRates))
+	for _, e := range c.ExchangeRates {
+		existing[e.From] = e
+		if !e.From.V().After(today) {
+			continue
+		}
+		s, ok := scheduled[e.From]
+		switch {
+		case !ok:
+			removed = append(removed, e)
+		case s.Rate != e.Rate:
+			updated = append(updated, s)
+		}
+	}
+
+	// Remove first so that an added rate may reuse the ID of a removed one.
+	for _, e := range removed {
+		if err := c.RemoveExchangeRate(MustParseExchangeRateId(e.ID), updatedAt); err != nil {
+			return fmt.Errorf("replace future exchange rates: %w", err)
+		}
+	}
+	for _, s := range updated {
+		id := MustParseExchangeRateId(existing[s.From].ID)
+		if err := c.UpdateExchangeRate(id, s.Rate, s.From, updatedAt); err != nil {
+			return fmt.Errorf("replace future exchange rates: %w", err)
+		}
+	}
+	for _, e := range schedule {
+		if _, ok := existing[e.From]; ok {
+			continue
+		}
+		if err := c.AddExchangeRate(e, updatedAt); err != nil {
+			return fmt.Errorf("replace future exchange rates: %w", err)
+		}
+	}
+	return nil
+}
+
+// EffectiveExchangeRate returns the rate in effect on a date, i.e., the rate
+// with the latest from not after the date. A rate stays in effect until the
+// next rate takes over, so rates have no end date.
+func (c *Currency) EffectiveExchangeRate(on Date) (*ExchangeRate, error) {
+	effective, ok := effectiveOn(c.ExchangeRates, exchangeRateFrom, on)
+	if !ok {
+		return nil, NewNoExchangeRateError(c.Code, on)
+	}
+	return effective, nil
+}
+
+func exchangeRateFrom(e *ExchangeRate) Date { return e.From.V() }
+
+// Convert converts an amount in one currency to another on a date. A rate is
+// the value of one unit of its currency in the reporting currency, so the
+// amount is converted into the reporting currency and out again. Converting
+// to the same currency requires no rate.
+func Convert(amount Decimal, from *Currency, to *Currency, on Date) (Money, error) {
+	if from.ID == to.ID {
+		return NewMoney(amount, to.Code), nil
+	}
+	fromRate, err := from.EffectiveExchangeRate(on)
+	if err != nil {
+		return Money{}, err
+	}
+	toRate, err := to.EffectiveExchangeRate(on)
+	if err != nil {
+		return Money{}, err
+	}
+	// Multiplying before dividing keeps the result exact until the final
+	// rounding to minor units.
+	minorUnits := int32(to.Code.MinorUnits())
+	return NewMoney(mulDiv(amount, fromRate.Rate.V(), toRate.Rate.V(), minorUnits), to.Code), nil
+}
+
+// ExchangeRateTimeline returns the periods of the currency's rates ordered by
+// from. Any date from requiredFrom must have a rate in effect.
+func (c *Currency) ExchangeRateTimeline(requiredFrom *Date) ([]Period[*ExchangeRate], []Gap) {
+	return timeline(c.ExchangeRates, exchangeRateFrom, requiredFrom)
+}
+
 func (c *Currency) RemoveCurrency(removeAt time.Time) error {
 	today := DateFromTime(removeAt)
 	canRemove := !slices.ContainsFunc(c.ExchangeRates, func(e *ExchangeRate) bool {
@@ -390,7 +565,8 @@ func (h CreateCurrencyHandler) Handle(ctx context.Context, req CreateCurrencyCom
 }
 
 type RemoveCurrencyCommand struct {
-	Code string
+	Code            string
+	ExpectedVersion *int32
 }
 
 func (r RemoveCurrencyCommand) Validate(err *RequestParseCollector) {
@@ -416,6 +592,9 @@ func (h RemoveCurrencyHandler) Handle(ctx context.Context, req RemoveCurrencyCom
 	if currency == nil {
 		return NewNotFoundError("Currency", "Code", code.V())
 	}
+	if err := currency.CheckVersion("Currency", req.ExpectedVersion); err != nil {
+		return err
+	}
 
 	if err := currency.RemoveCurrency(h.Clock.NowUTC()); err != nil {
 		return err
@@ -424,10 +603,11 @@ func (h RemoveCurrencyHandler) Handle(ctx context.Context, req RemoveCurrencyCom
 }
 
 type AddExchangeRateCommand struct {
-	ID   uuid.UUID
-	Code string
-	Rate float64
-	From Date
+	ID              uuid.UUID
+	Code            string
+	Rate            Decimal
+	From            Date
+	ExpectedVersion *int32
 }
 
 type AddExchangeRateHandler struct {
@@ -453,6 +633,9 @@ func (h AddExchangeRateHandler) Handle(ctx context.Context, req AddExchangeRateC
 	if currency == nil {
 		return NewNotFoundError("Currency", "Code", code.V())
 	}
+	if err := currency.CheckVersion("Currency", req.ExpectedVersion); err != nil {
+		return err
+	}
 
 	now := h.Clock.NowUTC()
 	exchangeRate := NewExchangeRate(id, rate, from, now)
@@ -464,10 +647,11 @@ func (h AddExchangeRateHandler) Handle(ctx context.Context, req AddExchangeRateC
 }
 
 type UpdateExchangeRateCommand struct {
-	ID   uuid.UUID
-	Code string
-	Rate float64
-	From Date
+	ID              uuid.UUID
+	Code            string
+	Rate            Decimal
+	From            Date
+	ExpectedVersion *int32
 }
 
 type UpdateExchangeRateHandler struct {
@@ -493,6 +677,9 @@ func (h UpdateExchangeRateHandler) Handle(ctx context.Context, req UpdateExchang
 	if currency == nil {
 		return NewNotFoundError("Currency", "Code", code.V())
 	}
+	if err := currency.CheckVersion("Currency", req.ExpectedVersion); err != nil {
+		return err
+	}
 
 	if err := currency.UpdateExchangeRate(id, rate, from, h.Clock.NowUTC()); err != nil {
 		return err
@@ -501,8 +688,9 @@ func (h UpdateExchangeRateHandler) Handle(ctx context.Context, req UpdateExchang
 }
 
 type RemoveExchangeRateCommand struct {
-	ID   uuid.UUID
-	Code string
+	ID              uuid.UUID
+	Code            string
+	ExpectedVersion *int32
 }
 
 type RemoveExchangeRateHandler struct {
@@ -526,6 +714,9 @@ func (h RemoveExchangeRateHandler) Handle(ctx context.Context, req RemoveExchang
 	if currency == nil {
 		return NewNotFoundError("Currency", "Code", code.V())
 	}
+	if err := currency.CheckVersion("Currency", req.ExpectedVersion); err != nil {
+		return err
+	}
 
 	if err := currency.RemoveExchangeRate(id, h.Clock.NowUTC()); err != nil {
 		return err
@@ -533,24 +724,244 @@ func (h RemoveExchangeRateHandler) Handle(ctx context.Context, req RemoveExchang
 	return h.Projector.Apply(ctx, currency)
 }
 
+type ScheduledExchangeRate struct {
+	ID   uuid.UUID
+	Rate Decimal
+	From Date
+}
+
+type ReplaceFutureExchangeRatesCommand struct {
+	Code            string
+	ExchangeRates   []ScheduledExchangeRate
+	ExpectedVersion *int32
+}
+
+type ReplaceFutureExchangeRatesHandler struct {
+	Currencies CurrencyStore
+	Projector  StoreProjector
+	Clock      Clock
+}
+
+func (h ReplaceFutureExchangeRatesHandler) Handle(ctx context.Context, req ReplaceFutureExchangeRatesCommand) error {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseCurrencyCode)
+	now := h.Clock.NowUTC()
+	schedule := make([]ExchangeRate, len(req.ExchangeRates))
+	for i, e := range req.ExchangeRates {
+		field := fmt.Sprintf("ExchangeRates[%d]", i)
+		id := parser.Parse(field+".ID", e.ID, ParseExchangeRateId)
+		rate := parser.Parse(field+".Rate", e.Rate, ParseRate)
+		from := parser.Parse(field+".From", e.From, ParseExchangeRateFrom)
+		schedule[i] = NewExchangeRate(id, rate, from, now)
+	}
+	if parser.HasErrors() {
+		return parser
+	}
+
+	currency, err := h.Currencies.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if currency == nil {
+		return NewNotFoundError("Currency", "Code", code.V())
+	}
+	if err := currency.CheckVersion("Currency", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	if err := currency.ReplaceFutureExchangeRates(schedule, now); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, currency)
+}
+
+// ExchangeRateImportRow is a row of a rate file as text. Rows are parsed in
+// core rather than when reading the file so that every row is validated the
+// same way regardless of file format.
+type ExchangeRateImportRow struct {
+	// Line is the row's position in the file for reporting.
+	Line int
+	ID   uuid.UUID
+	Code string
+	Rate string
+	From string
+	// Inverse marks a rate quoted as units of the currency per one unit of
+	// the reporting currency, like ECB reference rates per EUR, rather than as
+	// the value of one unit of the currency. It's inverted when parsed.
+	Inverse bool
+}
+
+type ImportExchangeRatesCommand struct {
+	Rows []ExchangeRateImportRow
+	// DryRun validates rows against parsing and currency rules without
+	// applying accepted rows.
+	DryRun bool
+}
+
+type AcceptedExchangeRateRow struct {
+	Line int
+	Code string
+	Rate Decimal
+	From Date
+}
+
+// RejectedExchangeRateRow holds the reasons by field. Rows that parse but
+// violate a currency rule have their reasons under Row.
+type RejectedExchangeRateRow struct {
+	Line        int
+	Code        string
+	FieldErrors map[string][]string
+}
+
+type ImportExchangeRatesReport struct {
+	DryRun   bool
+	Accepted []AcceptedExchangeRateRow
+	Rejected []RejectedExchangeRateRow
+}
+
+type ImportExchangeRatesHandler struct {
+	Currencies CurrencyStore
+	Projector  StoreProjector
+	Clock      Clock
+}
+
+// Handle applies accepted rows in a single StoreProjector.Apply, so either
+// all accepted rows are imported or none are. Rejected rows don't prevent
+// accepted rows from being imported. Rows are imported in order of from, so
+// that a historical file, listing the latest rates first, imports the same as
+// its daily files would have. The report lists rows in line order.
+func (h ImportExchangeRatesHandler) Handle(ctx context.Context, req ImportExchangeRatesCommand) (*ImportExchangeRatesReport, error) {
+	report := &ImportExchangeRatesReport{DryRun: req.DryRun}
+	reject := func(row ExchangeRateImportRow, field string, err error) {
+		report.Rejected = append(report.Rejected, RejectedExchangeRateRow{
+			Line:        row.Line,
+			Code:        row.Code,
+			FieldErrors: map[string][]string{field: {err.Error()}},
+		})
+	}
+
+	type parsedRow struct {
+		row  ExchangeRateImportRow
+		id   ExchangeRateID
+		code CurrencyCode
+		rate Rate
+		from ExchangeRateFrom
+	}
+	var parsed []parsedRow
+	for _, row := range req.Rows {
+		parser := &RequestParseCollector{}
+		id := parser.Parse("ID", row.ID, ParseExchangeRateId)
+		code := parser.Parse("Code", row.Code, ParseCurrencyCode)
+		rate := parser.Parse("Rate", row.Rate, func(v string) (Rate, error) { return parseImportRate(v, row.Inverse) })
+		from := parser.Parse("From", row.From, parseImportFrom)
+		if parser.HasErrors() {
+			report.Rejected = append(report.Rejected, RejectedExchangeRateRow{
+				Line:        row.Line,
+				Code:        row.Code,
+				FieldErrors: parser.FieldErrors,
+			})
+			continue
+		}
+		parsed = append(parsed, parsedRow{row: row, id: id, code: code, rate: rate, from: from})
+	}
+	slices.SortStableFunc(parsed, func(a, b parsedRow) int { return a.from.V().Compare(b.from.V()) })
+
+	now := h.Clock.NowUTC()
+	currencies := map[CurrencyCode]*Currency{}
+	var codes []CurrencyCode
+	for _, p := range parsed {
+		currency, ok := currencies[p.code]
+		if !ok {
+			var err error
+			currency, err = h.Currencies.GetByCode(ctx, p.code)
+			if err != nil {
+				return nil, err
+			}
+			currencies[p.code] = currency
+			if currency != nil {
+				codes = append(codes, p.code)
+			}
+		}
+		if currency == nil {
+			reject(p.row, "Code", NewNotFoundError("Currency", "Code", p.code.V()))
+			continue
+		}
+
+		exchangeRate := NewExchangeRate(p.id, p.rate, p.from, now)
+		if err := currency.ImportExchangeRate(exchangeRate, now); err != nil {
+			reject(p.row, "Row", err)
+			continue
+		}
+		report.Accepted = append(report.Accepted, AcceptedExchangeRateRow{
+			Line: p.row.Line,
+			Code: p.code.V(),
+			Rate: p.rate.V(),
+			From: p.from.V(),
+		})
+	}
+	slices.SortFunc(report.Accepted, func(a, b AcceptedExchangeRateRow) int { return a.Line - b.Line })
+	slices.SortFunc(report.Rejected, func(a, b RejectedExchangeRateRow) int { return a.Line - b.Line })
+
+	if req.DryRun || len(report.Accepted) == 0 {
+		return report, nil
+	}
+
+	// Apply in code order so concurrent imports lock currencies in the same
+	// order.
+	slices.SortFunc(codes, func(a, b CurrencyCode) int { return strings.Compare(a.V(), b.V()) })
+	aggregates := make([]Aggregate, len(codes))
+	for i, code := range codes {
+		aggregates[i] = currencies[code]
+	}
+	if err := h.Projector.Apply(ctx, aggregates...); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// parseImportRate rounds an inverted rate to the decimal places of a rate, as
+// the inverse of, e.g., 1.0856 USD per EUR has no exact decimal.
+func parseImportRate(v string, inverse bool) (Rate, error) {
+	d, err := ParseDecimal(strings.TrimSpace(v))
+	if err != nil {
+		return Rate{}, fmt.Errorf("must be a number, but was %s", v)
+	}
+	if inverse {
+		if d.Sign() <= 0 {
+			return Rate{}, fmt.Errorf("must be positive, but was %s", v)
+		}
+		d = NewDecimalFromInt(1).Div(d, ExchangeRateDecimalPlacesMax)
+	}
+	return ParseRate(d)
+}
+
+func parseImportFrom(v string) (ExchangeRateFrom, error) {
+	d, err := ParseDate(strings.TrimSpace(v))
+	if err != nil {
+		return ExchangeRateFrom{}, fmt.Errorf("must be a YYYY-MM-DD date, but was %s", v)
+	}
+	return ParseExchangeRateFrom(d)
+}
+
 type GetCurrencyQuery struct {
 	Code string
 }
 
 type ExchangeRateResponse struct {
-	ID        uuid.UUID
-	Rate      float64
-	From      Date
-	CreatedAt time.Time
-	UpdatedAt *time.Time
+	ID        uuid.UUID  `json:"id"`
+	Rate      Decimal    `json:"rate"`
+	From      Date       `json:"from"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
 }
 
 type CurrencyResponse struct {
-	ID            uuid.UUID
-	Code          string
-	ExchangeRates []*ExchangeRateResponse
-	CreatedAt     time.Time
-	UpdatedAt     *time.Time
+	ID            uuid.UUID               `json:"id"`
+	Version       int32                   `json:"-"`
+	Code          string                  `json:"code"`
+	ExchangeRates []*ExchangeRateResponse `json:"exchange_rates"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     *time.Time              `json:"updated_at"`
 }
 
 type GetCurrencyHandler struct {
@@ -572,22 +983,304 @@ func (h GetCurrencyHandler) Handle(ctx context.Context, req GetCurrencyQuery) (*
 		return nil, NewNotFoundError("Currency", "Code", code.V())
 	}
 
+	return newCurrencyResponse(currency), nil
+}
+
+func newExchangeRateResponse(e *ExchangeRate) *ExchangeRateResponse {
+	return &ExchangeRateResponse{
+		ID:        e.ID,
+		Rate:      e.Rate.V(),
+		From:      e.From.V(),
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+func newCurrencyResponse(currency *Currency) *CurrencyResponse {
 	exchangeRates := make([]*ExchangeRateResponse, len(currency.ExchangeRates))
 	for i, e := range currency.ExchangeRates {
-		exchangeRates[i] = &ExchangeRateResponse{
-			ID:        e.ID,
-			Rate:      e.Rate.V(),
-			From:      e.From.V(),
-			CreatedAt: e.CreatedAt,
-			UpdatedAt: e.UpdatedAt,
-		}
+		exchangeRates[i] = newExchangeRateResponse(e)
 	}
 
 	return &CurrencyResponse{
 		ID:            currency.ID,
+		Version:       currency.Version,
 		Code:          currency.Code.V(),
 		ExchangeRates: exchangeRates,
 		CreatedAt:     currency.CreatedAt,
 		UpdatedAt:     currency.UpdatedAt,
+	}
+}
+
+type ListCurrenciesQuery struct {
+	HasFutureExchangeRates *bool
+	Cursor                 string
+	Limit                  int
+}
+
+type ListCurrenciesResponse struct {
+	Items      []*CurrencyResponse `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type ListCurrenciesHandler struct {
+	Currencies CurrencyStore
+	Clock      Clock
+}
+
+func (h ListCurrenciesHandler) Handle(ctx context.Context, req ListCurrenciesQuery) (*ListCurrenciesResponse, error) {
+	parser := &RequestParseCollector{}
+	cursor := parser.Parse("Cursor", req.Cursor, parseCurrencyCursor)
+	limit := parser.Parse("Limit", req.Limit, ParsePageLimit)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	currencies, err := h.Currencies.List(ctx, CurrencyCriteria{
+		HasFutureExchangeRates: req.HasFutureExchangeRates,
+		Today:                  h.Clock.Today(),
+		AfterCode:              cursor,
+		Limit:                  limit.V() + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page, next := nextPage(currencies, limit, func(c *Currency) string { return c.Code.V() })
+	items := make([]*CurrencyResponse, len(page))
+	for i, c := range page {
+		items[i] = newCurrencyResponse(c)
+	}
+	return &ListCurrenciesResponse{
+		Items:      items,
+		NextCursor: next,
 	}, nil
 }
+
+func parseCurrencyCursor(v string) (*CurrencyCode, error) {
+	cursor, err := ParsePageCursor(v)
+	if err != nil || cursor.IsFirst() {
+		return nil, err
+	}
+	code, err := ParseCurrencyCode(cursor.After())
+	if err != nil {
+		return nil, fmt.Errorf("must be a cursor from a previous page, but was %s", v)
+	}
+	return &code, nil
+}
+
+// parseEffectiveOn bounds the date asked about to the dates an exchange rate
+// may be from. Outside it no rate can be in effect or the date is a mistake.
+func parseEffectiveOn(v Date) (Date, error) {
+	if err := ValidateDateInclusiveRange(v, ExchangeRateFromMin, ExchangeRateFromMax); err != nil {
+		return Date{}, err
+	}
+	return v, nil
+}
+
+type GetEffectiveExchangeRateQuery struct {
+	Code string
+	On   Date
+}
+
+type GetEffectiveExchangeRateHandler struct {
+	Currencies CurrencyStore
+}
+
+func (h GetEffectiveExchangeRateHandler) Handle(ctx context.Context, req GetEffectiveExchangeRateQuery) (*ExchangeRateResponse, error) {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseCurrencyCode)
+	on := parser.Parse("On", req.On, parseEffectiveOn)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	currency, err := h.Currencies.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, NewNotFoundError("Currency", "Code", code.V())
+	}
+
+	exchangeRate, err := currency.EffectiveExchangeRate(on)
+	if err != nil {
+		return nil, err
+	}
+	return newExchangeRateResponse(exchangeRate), nil
+}
+
+type ConvertMoneyQuery struct {
+	Amount   Decimal
+	FromCode string
+	ToCode   string
+	On       Date
+}
+
+// parseConvertAmount bounds the amount so that its conversion fits a Decimal
+// even at the lowest exchange rate.
+func parseConvertAmount(v Decimal) (Decimal, error) {
+	if err := ValidateDecimalInclusiveRange(v, MoneyAmountMax.Neg(), MoneyAmountMax); err != nil {
+		return Decimal{}, err
+	}
+	return v, nil
+}
+
+type ConvertMoneyHandler struct {
+	Currencies CurrencyStore
+}
+
+func (h ConvertMoneyHandler) Handle(ctx context.Context, req ConvertMoneyQuery) (*Money, error) {
+	parser := &RequestParseCollector{}
+	amount := parser.Parse("Amount", req.Amount, parseConvertAmount)
+	fromCode := parser.Parse("FromCode", req.FromCode, ParseCurrencyCode)
+	toCode := parser.Parse("ToCode", req.ToCode, ParseCurrencyCode)
+	on := parser.Parse("On", req.On, parseEffectiveOn)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	from, err := h.Currencies.GetByCode(ctx, fromCode)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, NewNotFoundError("Currency", "Code", fromCode.V())
+	}
+	to := from
+	if toCode != fromCode {
+		to, err = h.Currencies.GetByCode(ctx, toCode)
+		if err != nil {
+			return nil, err
+		}
+		if to == nil {
+			return nil, NewNotFoundError("Currency", "Code", toCode.V())
+		}
+	}
+
+	money, err := Convert(amount, from, to, on)
+	if err != nil {
+		return nil, err
+	}
+	return &money, nil
+}
+
+type GetExchangeRateTimelineQuery struct {
+	Code string
+	// RequiredFrom is the first date a rate must be in effect, e.g., the first
+	// billing date. Without it no gaps are reported.
+	RequiredFrom *Date
+}
+
+type ExchangeRatePeriodResponse struct {
+	ExchangeRateID uuid.UUID    `json:"exchange_rate_id"`
+	Rate           Decimal      `json:"rate"`
+	From           Date         `json:"from"`
+	To             *Date        `json:"to"`
+	Status         PeriodStatus `json:"status"`
+}
+
+type ExchangeRateGapResponse struct {
+	From Date  `json:"from"`
+	To   *Date `json:"to"`
+}
+
+type ExchangeRateTimelineResponse struct {
+	Code    string                        `json:"code"`
+	Today   Date                          `json:"today"`
+	Periods []*ExchangeRatePeriodResponse `json:"periods"`
+	Gaps    []*ExchangeRateGapResponse    `json:"gaps"`
+}
+
+type GetExchangeRateTimelineHandler struct {
+	Currencies CurrencyStore
+	Clock      Clock
+}
+
+func (h GetExchangeRateTimelineHandler) Handle(ctx context.Context, req GetExchangeRateTimelineQuery) (*ExchangeRateTimelineResponse, error) {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseCurrencyCode)
+	if req.RequiredFrom != nil {
+		parser.Parse("RequiredFrom", *req.RequiredFrom, parseEffectiveOn)
+	}
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	currency, err := h.Currencies.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, NewNotFoundError("Currency", "Code", code.V())
+	}
+
+	today := h.Clock.Today()
+	periods, gaps := currency.ExchangeRateTimeline(req.RequiredFrom)
+	res := &ExchangeRateTimelineResponse{
+		Code:    currency.Code.V(),
+		Today:   today,
+		Periods: make([]*ExchangeRatePeriodResponse, len(periods)),
+		Gaps:    make([]*ExchangeRateGapResponse, len(gaps)),
+	}
+	for i, p := range periods {
+		res.Periods[i] = &ExchangeRatePeriodResponse{
+			ExchangeRateID: p.Item.ID,
+			Rate:           p.Item.Rate.V(),
+			From:           p.From,
+			To:             p.To,
+			Status:         p.Status(today),
+		}
+	}
+	for i, g := range gaps {
+		res.Gaps[i] = &ExchangeRateGapResponse{From: g.From, To: g.To}
+	}
+	return res, nil
+}
+
+// currencyConverter looks up currencies by code to convert amounts between
+// them, and caches them across conversions, as many amounts share currencies.
+type currencyConverter struct {
+	currencyStore CurrencyStore
+	currencies    map[CurrencyCode]*Currency
+}
+
+func newCurrencyConverter(currencies CurrencyStore) *currencyConverter {
+	return &currencyConverter{
+		currencyStore: currencies,
+		currencies:    map[CurrencyCode]*Currency{},
+	}
+}
+
+func (c *currencyConverter) currency(ctx context.Context, code CurrencyCode) (*Currency, error) {
+	if currency, ok := c.currencies[code]; ok {
+		return currency, nil
+	}
+	currency, err := c.currencyStore.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, NewNotFoundError("Currency", "Code", code.V())
+	}
+	c.currencies[code] = currency
+	return currency, nil
+}
+
+// convert converts at the exchange rates in effect on the date. An amount
+// already in the currency converted to is returned as is.
+func (c *currencyConverter) convert(ctx context.Context, amount Money, to CurrencyCode, on Date) (Money, error) {
+	from := MustParseCurrencyCode(amount.Code)
+	if from == to {
+		return amount, nil
+	}
+	fromCurrency, err := c.currency(ctx, from)
+	if err != nil {
+		return Money{}, err
+	}
+	toCurrency, err := c.currency(ctx, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return Convert(amount.Amount, fromCurrency, toCurrency, on)
+}
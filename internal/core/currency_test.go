@@ -0,0 +1,305 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCurrency(code string, rates map[Date]string) *Currency {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCurrency(MustParseCurrencyId(uuid.New()), MustParseCurrencyCode(code), createdAt)
+	for from, rate := range rates {
+		e := NewExchangeRate(MustParseExchangeRateId(uuid.New()), MustParseRate(MustParseDecimal(rate)), MustParseExchangeRateFrom(from), createdAt)
+		c.ExchangeRates = append(c.ExchangeRates, &e)
+	}
+	return &c
+}
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		invalid bool
+	}{
+		"min":             {"0.0001", false},
+		"max":             {"100000", false},
+		"jpy in eur":      {"0.00625", false},
+		"gbp in eur":      {"1.1764705882", false},
+		"below":           {"0.00009", true},
+		"above":           {"100000.0000000001", true},
+		"too many places": {"1.00000000001", true},
+		"zero":            {"0", true},
+		"negative":        {"-1", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := ParseRate(MustParseDecimal(tt.value))
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.value), r.V())
+		})
+	}
+}
+
+func TestParseImportRate(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		inverse  bool
+		expected string
+		invalid  bool
+	}{
+		"rate":              {"1.1746", false, "1.1746", false},
+		"inverse":           {"0.8", true, "1.25", false},
+		"inverse rounded":   {"0.85135", true, "1.1746050391", false},
+		"inverse jpy":       {"161.42", true, "0.0061950192", false},
+		"inverse zero":      {"0", true, "", true},
+		"inverse too large": {"100000", true, "", true},
+		"malformed":         {"abc", false, "", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := parseImportRate(tt.value, tt.inverse)
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.expected), r.V())
+		})
+	}
+}
+
+func TestEffectiveExchangeRate(t *testing.T) {
+	c := newTestCurrency("EUR", map[Date]string{
+		NewDate(2026, 3, 1): "7.5",
+		NewDate(2026, 1, 1): "7.4",
+		NewDate(2026, 2, 1): "7.45",
+	})
+
+	tests := map[string]struct {
+		on       Date
+		expected string
+		invalid  bool
+	}{
+		"before first": {NewDate(2025, 12, 31), "", true},
+		"on first":     {NewDate(2026, 1, 1), "7.4", false},
+		"between":      {NewDate(2026, 1, 31), "7.4", false},
+		"on middle":    {NewDate(2026, 2, 1), "7.45", false},
+		"after last":   {NewDate(2027, 1, 1), "7.5", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := c.EffectiveExchangeRate(tt.on)
+			if tt.invalid {
+				var noRate *NoExchangeRateError
+				require.ErrorAs(t, err, &noRate)
+				assert.Equal(t, "EUR", noRate.Code)
+				assert.Equal(t, tt.on, noRate.On)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.expected), e.Rate.V())
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	eur := newTestCurrency("EUR", map[Date]string{NewDate(2026, 1, 1): "7.5"})
+	usd := newTestCurrency("USD", map[Date]string{NewDate(2026, 2, 1): "6"})
+
+	hundred := NewDecimalFromInt(100)
+
+	m, err := Convert(hundred, eur, usd, NewDate(2026, 2, 1))
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: NewDecimalFromInt(125), Code: "USD"}, m)
+
+	m, err = Convert(hundred, eur, eur, NewDate(2026, 2, 1))
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: hundred, Code: "EUR"}, m)
+	// Before EUR's first rate.
+	m, err = Convert(hundred, eur, eur, NewDate(2025, 12, 1))
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: hundred, Code: "EUR"}, m)
+
+	// Dividing 1000 by 3 first would give 333.33... and 2499.99... JPY.
+	jpy := newTestCurrency("JPY", map[Date]string{NewDate(2026, 1, 1): "3"})
+	m, err = Convert(NewDecimalFromInt(1000), eur, jpy, NewDate(2026, 2, 1))
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: MustParseDecimal("2500"), Code: "JPY"}, m)
+	// 2.5 JPY rounds half to even to JPY's zero minor units.
+	m, err = Convert(NewDecimalFromInt(1), eur, jpy, NewDate(2026, 2, 1))
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: NewDecimalFromInt(2), Code: "JPY"}, m)
+
+	_, err = Convert(hundred, eur, usd, NewDate(2026, 1, 15))
+	var noRate *NoExchangeRateError
+	require.ErrorAs(t, err, &noRate)
+	assert.Equal(t, "USD", noRate.Code)
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	// ECB quotes 0.85 GBP and 160 JPY per EUR, i.e., one GBP is worth 1/0.85
+	// EUR and one JPY 1/160 EUR.
+	gbp := newTestCurrency("GBP", map[Date]string{NewDate(2026, 1, 1): "1.1764705882"})
+	jpy := newTestCurrency("JPY", map[Date]string{NewDate(2026, 1, 1): "0.00625"})
+	on := NewDate(2026, 2, 1)
+
+	m, err := Convert(MustParseDecimal("1000.00"), gbp, jpy, on)
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: NewDecimalFromInt(188235), Code: "JPY"}, m)
+
+	m, err = Convert(m.Amount, jpy, gbp, on)
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: NewDecimalFromInt(1000), Code: "GBP"}, m)
+}
+
+func TestExchangeRateTimeline(t *testing.T) {
+	c := newTestCurrency("EUR", map[Date]string{
+		NewDate(2026, 3, 1): "7.5",
+		NewDate(2026, 1, 1): "7.4",
+		NewDate(2026, 2, 1): "7.45",
+	})
+	today := NewDate(2026, 2, 15)
+
+	periods, gaps := c.ExchangeRateTimeline(nil)
+
+	require.Len(t, periods, 3)
+	assert.Empty(t, gaps)
+	assert.Equal(t, NewDate(2026, 1, 1), periods[0].From)
+	assert.Equal(t, NewDate(2026, 2, 1), *periods[0].To)
+	assert.Equal(t, PeriodPast, periods[0].Status(today))
+	assert.Equal(t, NewDate(2026, 2, 1), periods[1].From)
+	assert.Equal(t, NewDate(2026, 3, 1), *periods[1].To)
+	assert.Equal(t, PeriodCurrent, periods[1].Status(today))
+	assert.Equal(t, NewDate(2026, 3, 1), periods[2].From)
+	assert.Nil(t, periods[2].To)
+	assert.Equal(t, PeriodFuture, periods[2].Status(today))
+}
+
+func TestExchangeRateTimelineGaps(t *testing.T) {
+	first := NewDate(2026, 1, 1)
+	tests := map[string]struct {
+		rates        map[Date]string
+		requiredFrom Date
+		expected     []Gap
+	}{
+		"no rates":    {nil, NewDate(2025, 12, 1), []Gap{{From: NewDate(2025, 12, 1)}}},
+		"before":      {map[Date]string{first: "7.4"}, NewDate(2025, 12, 1), []Gap{{From: NewDate(2025, 12, 1), To: &first}}},
+		"on first":    {map[Date]string{first: "7.4"}, first, nil},
+		"after first": {map[Date]string{first: "7.4"}, NewDate(2026, 1, 2), nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestCurrency("EUR", tt.rates)
+			_, gaps := c.ExchangeRateTimeline(&tt.requiredFrom)
+			assert.Equal(t, tt.expected, gaps)
+		})
+	}
+}
+
+func TestImportExchangeRate(t *testing.T) {
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	rates := map[Date]string{NewDate(2026, 1, 1): "7.4", NewDate(2026, 2, 1): "7.45", NewDate(2026, 3, 1): "7.5"}
+	tests := map[string]struct {
+		from    Date
+		invalid bool
+	}{
+		"between past rates":       {NewDate(2026, 1, 15), true},
+		"before first rate":        {NewDate(2025, 12, 1), true},
+		"after rate in effect":     {NewDate(2026, 2, 10), false},
+		"today":                    {NewDate(2026, 2, 15), false},
+		"between future rates":     {NewDate(2026, 2, 20), false},
+		"after latest future rate": {NewDate(2026, 4, 1), false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestCurrency("EUR", rates)
+			e := NewExchangeRate(MustParseExchangeRateId(uuid.New()), MustParseRate(MustParseDecimal("7.6")), MustParseExchangeRateFrom(tt.from), now)
+
+			err := c.ImportExchangeRate(e, now)
+
+			if tt.invalid {
+				var de *DomainError
+				require.ErrorAs(t, err, &de)
+				assert.Equal(t, CurrencyImportRequiresLatestFrom, de.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, c.ExchangeRates, len(rates)+1)
+		})
+	}
+
+	t.Run("no rates", func(t *testing.T) {
+		c := newTestCurrency("EUR", nil)
+		e := NewExchangeRate(MustParseExchangeRateId(uuid.New()), MustParseRate(MustParseDecimal("7.6")), MustParseExchangeRateFrom(NewDate(2025, 1, 1)), now)
+		require.NoError(t, c.ImportExchangeRate(e, now))
+	})
+}
+
+func TestReplaceFutureExchangeRates(t *testing.T) {
+	c := newTestCurrency("EUR", map[Date]string{
+		NewDate(2026, 1, 1): "7.4",  // Past, kept.
+		NewDate(2026, 3, 1): "7.5",  // Future, removed.
+		NewDate(2026, 4, 1): "7.55", // Future, unchanged.
+		NewDate(2026, 5, 1): "7.6",  // Future, updated.
+	})
+	c.ClearDomainEvents()
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	added := MustParseExchangeRateId(uuid.New())
+	schedule := []ExchangeRate{
+		NewExchangeRate(MustParseExchangeRateId(uuid.New()), MustParseRate(MustParseDecimal("7.55")), MustParseExchangeRateFrom(NewDate(2026, 4, 1)), now),
+		NewExchangeRate(MustParseExchangeRateId(uuid.New()), MustParseRate(MustParseDecimal("7.65")), MustParseExchangeRateFrom(NewDate(2026, 5, 1)), now),
+		NewExchangeRate(added, MustParseRate(MustParseDecimal("7.7")), MustParseExchangeRateFrom(NewDate(2026, 6, 1)), now),
+	}
+
+	err := c.ReplaceFutureExchangeRates(schedule, now)
+
+	require.NoError(t, err)
+	periods, _ := c.ExchangeRateTimeline(nil)
+	require.Len(t, periods, 4)
+	assert.Equal(t, NewDate(2026, 1, 1), periods[0].From)
+	assert.Equal(t, NewDate(2026, 4, 1), periods[1].From)
+	assert.Equal(t, NewDate(2026, 5, 1), periods[2].From)
+	assert.Equal(t, MustParseDecimal("7.65"), periods[2].Item.Rate.V())
+	assert.Equal(t, added.V(), periods[3].Item.ID)
+
+	require.Len(t, c.DomainEvents, 3)
+	assert.IsType(t, ExchangeRateRemovedEvent{}, c.DomainEvents[0])
+	assert.IsType(t, ExchangeRateUpdatedEvent{}, c.DomainEvents[1])
+	assert.IsType(t, ExchangeRateAddedEvent{}, c.DomainEvents[2])
+}
+
+func TestReplaceFutureExchangeRatesInvalid(t *testing.T) {
+	now := time.Date(2026, 2, 15, 12, 0, 0, 0, time.UTC)
+	newRate := func(from Date) ExchangeRate {
+		return NewExchangeRate(MustParseExchangeRateId(uuid.New()), MustParseRate(MustParseDecimal("7.5")), MustParseExchangeRateFrom(from), now)
+	}
+
+	t.Run("past from", func(t *testing.T) {
+		c := newTestCurrency("EUR", nil)
+		err := c.ReplaceFutureExchangeRates([]ExchangeRate{newRate(NewDate(2026, 2, 15))}, now)
+		var e *DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, CurrencyReplaceRequiresFutureFrom, e.Code)
+	})
+
+	t.Run("duplicate from", func(t *testing.T) {
+		c := newTestCurrency("EUR", nil)
+		from := NewDate(2026, 3, 1)
+		err := c.ReplaceFutureExchangeRates([]ExchangeRate{newRate(from), newRate(from)}, now)
+		var e *ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, from.String(), e.FieldValues["From"])
+	})
+}
@@ -0,0 +1,281 @@
+package core
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact fixed-point number: coef × 10^-scale. Unlike float64,
+// amounts such as 0.1 are represented exactly, and sums over many amounts
+// don't accumulate representation errors.
+//
+// A Decimal is always normalized, i.e., without trailing fractional zeros, so
+// that equal values are equal with == and may be map keys. It follows that
+// Scale is the number of significant decimal places.
+//
+// Arithmetic panics on overflow of the 64-bit coefficient. Values in the
+// domain are bounded by validation well within 18 significant digits, so an
+// overflow is a bug rather than bad input.
+type Decimal struct {
+	coef  int64
+	scale int32
+}
+
+const decimalMaxDigits = 18
+
+var (
+	bigTen  = big.NewInt(10)
+	bigZero = big.NewInt(0)
+)
+
+// NewDecimal returns coef × 10^-scale, e.g., NewDecimal(1050, 2) is 10.5.
+func NewDecimal(coef int64, scale int32) Decimal {
+	Assert(scale >= 0, "decimal scale must be non-negative, but was %d", scale)
+	return fromBig(big.NewInt(coef), scale)
+}
+
+func NewDecimalFromInt(v int64) Decimal {
+	return Decimal{coef: v}
+}
+
+// ParseDecimal parses plain decimal notation such as "-12.345". Exponents,
+// thousands separators, and more than 18 significant digits aren't accepted.
+func ParseDecimal(s string) (Decimal, error) {
+	v := s
+	negative := false
+	if v != "" && (v[0] == '-' || v[0] == '+') {
+		negative = v[0] == '-'
+		v = v[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(v, ".")
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("must be a decimal number, but was %s", s)
+	}
+	digits := intPart + fracPart
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Decimal{}, fmt.Errorf("must be a decimal number, but was %s", s)
+		}
+	}
+	if len(strings.TrimLeft(digits, "0")) > decimalMaxDigits {
+		return Decimal{}, fmt.Errorf("must have at most %d significant digits, but was %s", decimalMaxDigits, s)
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("must be a decimal number, but was %s", s)
+	}
+	if negative {
+		coef.Neg(coef)
+	}
+	return fromBig(coef, int32(len(fracPart))), nil
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// fromBig normalizes before checking for overflow as trailing zeros may be
+// what doesn't fit.
+func fromBig(coef *big.Int, scale int32) Decimal {
+	c := new(big.Int).Set(coef)
+	r := new(big.Int)
+	for scale > 0 {
+		q, m := new(big.Int).QuoRem(c, bigTen, r)
+		if m.Sign() != 0 {
+			break
+		}
+		c = q
+		scale--
+	}
+	if c.Sign() == 0 {
+		scale = 0
+	}
+	Assert(c.IsInt64(), "decimal overflow: %s × 10^-%d", c, scale)
+	return Decimal{coef: c.Int64(), scale: scale}
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// scaled returns the coefficient for a scale at least as large as d's.
+func (d Decimal) scaled(scale int32) *big.Int {
+	b := big.NewInt(d.coef)
+	if scale > d.scale {
+		b.Mul(b, pow10(scale-d.scale))
+	}
+	return b
+}
+
+func (d Decimal) Scale() int32 { return d.scale }
+func (d Decimal) Sign() int    { return big.NewInt(d.coef).Sign() }
+func (d Decimal) IsZero() bool { return d.coef == 0 }
+func (d Decimal) Neg() Decimal { return fromBig(new(big.Int).Neg(big.NewInt(d.coef)), d.scale) }
+func (d Decimal) Abs() Decimal { return fromBig(new(big.Int).Abs(big.NewInt(d.coef)), d.scale) }
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	scale := max(d.scale, o.scale)
+	return fromBig(new(big.Int).Add(d.scaled(scale), o.scaled(scale)), scale)
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul is exact. The scale of the result is at most the sum of the scales.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return fromBig(new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(o.coef)), d.scale+o.scale)
+}
+
+// Div rounds the quotient half to even to at most scale decimal places.
+func (d Decimal) Div(o Decimal, scale int32) Decimal {
+	return mulDiv(d, NewDecimalFromInt(1), o, scale)
+}
+
+// mulDiv returns a×b/c rounded half to even to at most scale decimal places.
+// Unlike a.Mul(b).Div(c, scale), the intermediate product may exceed 64 bits.
+func mulDiv(a, b, c Decimal, scale int32) Decimal {
+	Assert(!c.IsZero(), "decimal division by zero")
+	// a×b/c = (a.coef × b.coef × 10^-(a.scale+b.scale)) / (c.coef × 10^-c.scale).
+	// Scaling both sides so the quotient has scale decimal places gives the
+	// integer division (a.coef × b.coef × 10^(scale+c.scale)) / (c.coef ×
+	// 10^(a.scale+b.scale)).
+	num := new(big.Int).Mul(big.NewInt(a.coef), big.NewInt(b.coef))
+	num.Mul(num, pow10(scale+c.scale))
+	den := new(big.Int).Mul(big.NewInt(c.coef), pow10(a.scale+b.scale))
+	return fromBig(quoHalfEven(num, den), scale)
+}
+
+// Round rounds half to even to at most scale decimal places, i.e., banker's
+// rounding. Rounding half up would bias sums of many rounded amounts upwards.
+func (d Decimal) Round(scale int32) Decimal {
+	if d.scale <= scale {
+		return d
+	}
+	return fromBig(quoHalfEven(big.NewInt(d.coef), pow10(d.scale-scale)), scale)
+}
+
+func quoHalfEven(num, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// Compare the remainder to half the divisor by doubling the remainder.
+	cmp := new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(new(big.Int).Abs(den))
+	if cmp > 0 || (cmp == 0 && q.Bit(0) == 1) {
+		// Quotient is truncated towards zero, so away from zero is in the
+		// direction of the sign of the exact quotient.
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	scale := max(d.scale, o.scale)
+	return d.scaled(scale).Cmp(o.scaled(scale))
+}
+
+func (d Decimal) LessThan(o Decimal) bool    { return d.Cmp(o) < 0 }
+func (d Decimal) GreaterThan(o Decimal) bool { return d.Cmp(o) > 0 }
+
+// String returns plain decimal notation with only significant decimal places,
+// e.g., "10.5".
+func (d Decimal) String() string {
+	return d.format(d.scale)
+}
+
+// StringFixed returns d rounded to and padded with exactly places decimal
+// places, e.g., "10.50" for money with two minor units.
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places).format(places)
+}
+
+func (d Decimal) format(places int32) string {
+	c := big.NewInt(d.coef)
+	if places > d.scale {
+		c.Mul(c, pow10(places-d.scale))
+	}
+	digits := new(big.Int).Abs(c).String()
+	if places > 0 {
+		if pad := int(places) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(places)] + "." + digits[len(digits)-int(places):]
+	}
+	if c.Cmp(bigZero) < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// MarshalJSON writes a JSON number rather than a string so that clients see
+// the same type as they would for a float.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a number or a string. Parsing the number's literal
+// text avoids the detour through float64 which would defeat the purpose.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements driver.Valuer. PostgreSQL parses the text into numeric
+// without loss.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner. A numeric column is received as text.
+func (d *Decimal) Scan(source any) error {
+	var (
+		v   Decimal
+		err error
+	)
+	switch s := source.(type) {
+	case string:
+		v, err = ParseDecimal(s)
+	case []byte:
+		v, err = ParseDecimal(string(s))
+	case int64:
+		v = NewDecimalFromInt(s)
+	case float64:
+		v, err = ParseDecimal(strconv.FormatFloat(s, 'f', -1, 64))
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", source)
+	}
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected string
+		invalid  bool
+	}{
+		"integer":          {"42", "42", false},
+		"fraction":         {"10.5", "10.5", false},
+		"trailing zeros":   {"10.500", "10.5", false},
+		"leading zeros":    {"007.25", "7.25", false},
+		"negative":         {"-0.125", "-0.125", false},
+		"plus sign":        {"+1.5", "1.5", false},
+		"no integer part":  {".5", "0.5", false},
+		"no fraction part": {"5.", "5", false},
+		"negative zero":    {"-0.00", "0", false},
+		"max digits":       {"123456789.123456789", "123456789.123456789", false},
+		"too many digits":  {"1234567890.123456789", "", true},
+		"empty":            {"", "", true},
+		"sign only":        {"-", "", true},
+		"dot only":         {".", "", true},
+		"exponent":         {"1e3", "", true},
+		"thousands":        {"1,000", "", true},
+		"two dots":         {"1.2.3", "", true},
+		"inner whitespace": {"1 000", "", true},
+		"outer whitespace": {" 1", "", true},
+		"not a number":     {"NaN", "", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := ParseDecimal(tt.value)
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, d.String())
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+
+	assert.Equal(t, d("0.3"), d("0.1").Add(d("0.2")))
+	assert.Equal(t, d("-1.75"), d("0.25").Sub(d("2")))
+	assert.Equal(t, d("7.5"), d("2.5").Mul(d("3")))
+	assert.Equal(t, d("0.0001"), d("0.01").Mul(d("0.01")))
+	assert.Equal(t, d("0.33"), d("1").Div(d("3"), 2))
+	assert.Equal(t, d("0.67"), d("2").Div(d("3"), 2))
+	assert.Equal(t, d("-0.67"), d("-2").Div(d("3"), 2))
+	assert.Equal(t, d("-0.67"), d("2").Div(d("-3"), 2))
+	assert.Equal(t, d("12.5"), d("100").Div(d("8"), 6))
+	assert.Equal(t, d("1.5"), d("-1.5").Abs())
+	assert.Equal(t, d("-1.5"), d("1.5").Neg())
+	assert.Equal(t, -1, d("-0.01").Sign())
+	assert.True(t, d("0.000").IsZero())
+	assert.Equal(t, 0, d("1.50").Cmp(d("1.5")))
+	assert.True(t, d("1.49").LessThan(d("1.5")))
+	assert.True(t, d("-1").LessThan(d("0")))
+	assert.Panics(t, func() { d("1").Div(d("0"), 2) })
+	assert.Panics(t, func() { d("999999999999999999").Mul(d("10")) })
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		scale    int32
+		expected string
+	}{
+		"half to even down":  {"2.5", 0, "2"},
+		"half to even up":    {"3.5", 0, "4"},
+		"below half":         {"2.49", 0, "2"},
+		"above half":         {"2.51", 0, "3"},
+		"negative half down": {"-2.5", 0, "-2"},
+		"negative half up":   {"-3.5", 0, "-4"},
+		"two places":         {"10.125", 2, "10.12"},
+		"two places up":      {"10.135", 2, "10.14"},
+		"already rounded":    {"10.1", 2, "10.1"},
+		"carry":              {"9.995", 2, "10"},
+		"to zero":            {"0.004", 2, "0"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, MustParseDecimal(tt.expected), MustParseDecimal(tt.value).Round(tt.scale))
+		})
+	}
+}
+
+func TestDecimalStringFixed(t *testing.T) {
+	d := MustParseDecimal
+	assert.Equal(t, "10.50", d("10.5").StringFixed(2))
+	assert.Equal(t, "0.05", d("0.05").StringFixed(2))
+	assert.Equal(t, "-0.005", d("-0.005").StringFixed(3))
+	assert.Equal(t, "1234", d("1234.5").StringFixed(0))
+	assert.Equal(t, "0.000", d("0").StringFixed(3))
+}
+
+func TestDecimalJSON(t *testing.T) {
+	type payload struct {
+		Amount Decimal `json:"amount"`
+	}
+
+	b, err := json.Marshal(payload{MustParseDecimal("10.05")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":10.05}`, string(b))
+
+	var p payload
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":0.1}`), &p))
+	assert.Equal(t, MustParseDecimal("0.1"), p.Amount)
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"7.45"}`), &p))
+	assert.Equal(t, MustParseDecimal("7.45"), p.Amount)
+	require.Error(t, json.Unmarshal([]byte(`{"amount":1e3}`), &p))
+	require.Error(t, json.Unmarshal([]byte(`{"amount":true}`), &p))
+}
+
+func TestDecimalSQL(t *testing.T) {
+	v, err := MustParseDecimal("7.45").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "7.45", v)
+
+	tests := map[string]struct {
+		source   any
+		expected string
+		invalid  bool
+	}{
+		"string":  {"7.450000", "7.45", false},
+		"bytes":   {[]byte("12.00"), "12", false},
+		"int64":   {int64(3), "3", false},
+		"float64": {0.1, "0.1", false},
+		"bool":    {true, "", true},
+		"invalid": {"x", "", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var d Decimal
+			err := d.Scan(tt.source)
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.expected), d)
+		})
+	}
+}
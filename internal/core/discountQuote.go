@@ -0,0 +1,324 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"uuid"
+)
+
+// Domain
+
+// A discount quote is the tier discount a reseller gets on an order line for a
+// product on a date. Like net revenue, a quote must be reproducible by hand, so
+// it carries the outcome of every lookup behind it:
+//
+//  1. The reseller by external ID, and the cluster it's a member of. An orphan
+//     has no tier.
+//  2. The product by code. Like when recording a billing, the product must be
+//     assigned a product group.
+//  3. The cluster's tiers from its latest tiering or rollover on or before the
+//     date. The reseller's tier is the higher of the year-to-date and minimum
+//     tiers. A cluster not tiered by then has no tier.
+//  4. The tier discount in effect on the date and its percentage for the tier.
+//     Without a tier, the percentage is zero.
+//
+// Cluster membership isn't kept over time, so the reseller's cluster on the
+// date is taken to be its current cluster.
+
+const (
+	DiscountQuoteLinesMin = 1
+	DiscountQuoteLinesMax = 100
+)
+
+func parseDiscountQuoteLineCount(v int) (int, error) {
+	if err := ValidateIntInclusiveRange(v, DiscountQuoteLinesMin, DiscountQuoteLinesMax); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// isLineError tells an order line that can't be quoted from a failure to look
+// it up, which fails every line.
+func isLineError(err error) bool {
+	var notFound *NotFoundError
+	var noTierDiscount *NoTierDiscountError
+	var domainErr *DomainError
+	return errors.As(err, &notFound) || errors.As(err, &noTierDiscount) || errors.As(err, &domainErr)
+}
+
+type clusterTierKey struct {
+	clusterID uuid.UUID
+	on        Date
+}
+
+// discountQuoter caches lookups across order lines, as the lines of an order
+// share the reseller and date, and many orders share products and clusters.
+// Tier discounts are few, so they're all looked up once.
+type discountQuoter struct {
+	resellerStore     ResellerStore
+	clusterStore      ClusterStore
+	productStore      ProductStore
+	tierDiscountStore TierDiscountStore
+	resellers         map[ResellerExternalID]*Reseller
+	clusterTiers      map[clusterTierKey]*ClusterTier
+	products          map[ProductCode]*Product
+	tierDiscounts     []*TierDiscount
+}
+
+func newDiscountQuoter(resellers ResellerStore, clusters ClusterStore, products ProductStore, tierDiscounts TierDiscountStore) *discountQuoter {
+	return &discountQuoter{
+		resellerStore:     resellers,
+		clusterStore:      clusters,
+		productStore:      products,
+		tierDiscountStore: tierDiscounts,
+		resellers:         map[ResellerExternalID]*Reseller{},
+		clusterTiers:      map[clusterTierKey]*ClusterTier{},
+		products:          map[ProductCode]*Product{},
+	}
+}
+
+func (q *discountQuoter) reseller(ctx context.Context, externalID ResellerExternalID) (*Reseller, error) {
+	if r, ok := q.resellers[externalID]; ok {
+		return r, nil
+	}
+	r, err := q.resellerStore.GetByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, NewNotFoundError("Reseller", "ExternalID", externalID.String())
+	}
+	q.resellers[externalID] = r
+	return r, nil
+}
+
+// clusterTier caches a cluster not tiered by a date as nil.
+func (q *discountQuoter) clusterTier(ctx context.Context, clusterID uuid.UUID, on Date) (*ClusterTier, error) {
+	key := clusterTierKey{clusterID: clusterID, on: on}
+	if t, ok := q.clusterTiers[key]; ok {
+		return t, nil
+	}
+	t, err := q.clusterStore.GetTierOn(ctx, MustParseClusterID(clusterID), on)
+	if err != nil {
+		return nil, err
+	}
+	q.clusterTiers[key] = t
+	return t, nil
+}
+
+func (q *discountQuoter) product(ctx context.Context, code ProductCode) (*Product, error) {
+	if p, ok := q.products[code]; ok {
+		return p, nil
+	}
+	p, err := q.productStore.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, NewNotFoundError("Product", "Code", code.V())
+	}
+	q.products[code] = p
+	return p, nil
+}
+
+func (q *discountQuoter) tierDiscount(ctx context.Context, on Date) (*TierDiscount, error) {
+	if q.tierDiscounts == nil {
+		tierDiscounts, err := q.tierDiscountStore.List(ctx, TierDiscountCriteria{})
+		if err != nil {
+			return nil, err
+		}
+		q.tierDiscounts = tierDiscounts
+	}
+	return EffectiveTierDiscount(q.tierDiscounts, on)
+}
+
+func (q *discountQuoter) quote(ctx context.Context, externalID ResellerExternalID, productCode ProductCode, on Date) (*DiscountQuoteResponse, error) {
+	reseller, err := q.reseller(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+	product, err := q.product(ctx, productCode)
+	if err != nil {
+		return nil, err
+	}
+	if product.ProductGroup == nil {
+		return nil, NewDomainError(
+			ProductExpectedProductGroupSet,
+			fmt.Sprintf("quote discount requires product %s to have a product group", product.Code))
+	}
+
+	var clusterTier *ClusterTier
+	if reseller.ClusterID != nil {
+		clusterTier, err = q.clusterTier(ctx, *reseller.ClusterID, on)
+		if err != nil {
+			return nil, err
+		}
+	}
+	tierDiscount, err := q.tierDiscount(ctx, on)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := DiscountQuoteBreakdown{
+		ResellerID:       reseller.ID,
+		ResellerRole:     reseller.ResellerRole,
+		ClusterID:        reseller.ClusterID,
+		ProductGroupCode: product.ProductGroup.Code.V(),
+		TierDiscountID:   tierDiscount.ID,
+		TierDiscountFrom: tierDiscount.From.V(),
+		Percentages: DiscountPercentagesResponse{
+			Authorized: tierDiscount.Percentages.Authorized(),
+			Advanced:   tierDiscount.Percentages.Advanced(),
+			Premier:    tierDiscount.Percentages.Premier(),
+		},
+	}
+	var tier *ResellerTier
+	if clusterTier != nil {
+		var basis TierBasis
+		tier, basis = clusterTier.ResellerTier()
+		breakdown.TierAt = &clusterTier.TierAt
+		breakdown.ResellerTierBasis = &basis
+		breakdown.CalculatedResellerTierYearToDate = clusterTier.CalculatedResellerTierYearToDate
+		breakdown.CalculatedResellerTierMinimum = clusterTier.CalculatedResellerTierMinimum
+	}
+
+	return &DiscountQuoteResponse{
+		ResellerExternalID: externalID.V(),
+		ProductCode:        productCode.V(),
+		On:                 on,
+		ResellerTier:       tier,
+		Percentage:         tierDiscount.Percentages.Of(tier),
+		Breakdown:          breakdown,
+	}, nil
+}
+
+// Application
+
+type GetDiscountQuoteQuery struct {
+	ResellerExternalID uuid.UUID
+	ProductCode        string
+	On                 Date
+}
+
+// DiscountQuoteResponse holds the discount percentage of the reseller's tier
+// on the date and the lookups behind it.
+type DiscountQuoteResponse struct {
+	ResellerExternalID uuid.UUID              `json:"reseller_external_id"`
+	ProductCode        string                 `json:"product_code"`
+	On                 Date                   `json:"on"`
+	ResellerTier       *ResellerTier          `json:"reseller_tier"`
+	Percentage         Decimal                `json:"percentage"`
+	Breakdown          DiscountQuoteBreakdown `json:"breakdown"`
+}
+
+// DiscountQuoteBreakdown explains a quote by the outcome of each lookup. A
+// reseller without a cluster, or with a cluster not tiered by the date, has no
+// tier, and neither tier at nor the cluster's tiers are set.
+type DiscountQuoteBreakdown struct {
+	ResellerID                       uuid.UUID                   `json:"reseller_id"`
+	ResellerRole                     ResellerRole                `json:"reseller_role"`
+	ClusterID                        *uuid.UUID                  `json:"cluster_id"`
+	ProductGroupCode                 string                      `json:"product_group_code"`
+	TierAt                           *Date                       `json:"tier_at"`
+	ResellerTierBasis                *TierBasis                  `json:"reseller_tier_basis"`
+	CalculatedResellerTierYearToDate *ResellerTier               `json:"calculated_reseller_tier_year_to_date"`
+	CalculatedResellerTierMinimum    *ResellerTier               `json:"calculated_reseller_tier_minimum"`
+	TierDiscountID                   uuid.UUID                   `json:"tier_discount_id"`
+	TierDiscountFrom                 Date                        `json:"tier_discount_from"`
+	Percentages                      DiscountPercentagesResponse `json:"percentages"`
+}
+
+type GetDiscountQuoteHandler struct {
+	Resellers     ResellerStore
+	Clusters      ClusterStore
+	Products      ProductStore
+	TierDiscounts TierDiscountStore
+}
+
+func (h GetDiscountQuoteHandler) Handle(ctx context.Context, req GetDiscountQuoteQuery) (*DiscountQuoteResponse, error) {
+	parser := &RequestParseCollector{}
+	resellerExternalID := parser.Parse("ResellerExternalID", req.ResellerExternalID, ParseResellerExternalID)
+	productCode := parser.Parse("ProductCode", req.ProductCode, ParseProductCode)
+	on := parser.Parse("On", req.On, parseTierDiscountEffectiveOn)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	q := newDiscountQuoter(h.Resellers, h.Clusters, h.Products, h.TierDiscounts)
+	return q.quote(ctx, resellerExternalID, productCode, on)
+}
+
+type DiscountQuoteLineInput struct {
+	ResellerExternalID uuid.UUID
+	ProductCode        string
+	On                 Date
+}
+
+// GetDiscountQuotesQuery quotes the lines of one or more orders at once, so
+// that lookups are shared between lines.
+type GetDiscountQuotesQuery struct {
+	Lines []DiscountQuoteLineInput
+}
+
+// FailedDiscountQuote refers to a line by its index in the query.
+type FailedDiscountQuote struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// DiscountQuotesResponse holds a quote for each line, or nil for a failed line,
+// so that quotes line up with the query's lines. A line failing, e.g., for an
+// unknown product, doesn't fail other lines.
+type DiscountQuotesResponse struct {
+	Quotes []*DiscountQuoteResponse `json:"quotes"`
+	Failed []FailedDiscountQuote    `json:"failed"`
+}
+
+type GetDiscountQuotesHandler struct {
+	Resellers     ResellerStore
+	Clusters      ClusterStore
+	Products      ProductStore
+	TierDiscounts TierDiscountStore
+}
+
+func (h GetDiscountQuotesHandler) Handle(ctx context.Context, req GetDiscountQuotesQuery) (*DiscountQuotesResponse, error) {
+	type line struct {
+		resellerExternalID ResellerExternalID
+		productCode        ProductCode
+		on                 Date
+	}
+
+	parser := &RequestParseCollector{}
+	parser.Parse("Lines", len(req.Lines), parseDiscountQuoteLineCount)
+	lines := make([]line, len(req.Lines))
+	for i, l := range req.Lines {
+		field := fmt.Sprintf("Lines[%d]", i)
+		lines[i] = line{
+			resellerExternalID: parser.Parse(field+".ResellerExternalID", l.ResellerExternalID, ParseResellerExternalID),
+			productCode:        parser.Parse(field+".ProductCode", l.ProductCode, ParseProductCode),
+			on:                 parser.Parse(field+".On", l.On, parseTierDiscountEffectiveOn),
+		}
+	}
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	q := newDiscountQuoter(h.Resellers, h.Clusters, h.Products, h.TierDiscounts)
+	res := &DiscountQuotesResponse{
+		Quotes: make([]*DiscountQuoteResponse, len(lines)),
+		Failed: []FailedDiscountQuote{},
+	}
+	for i, l := range lines {
+		quote, err := q.quote(ctx, l.resellerExternalID, l.productCode, l.on)
+		if err != nil {
+			if !isLineError(err) {
+				return nil, err
+			}
+			res.Failed = append(res.Failed, FailedDiscountQuote{Line: i, Reason: err.Error()})
+			continue
+		}
+		res.Quotes[i] = quote
+	}
+	return res, nil
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"embed"
+	"encoding/csv"
+	"strconv"
+)
+
+// Code sets are embedded rather than stored in the database because they
+// change rarely, by amendment of a standard, and then only together with a
+// release that may depend on the change.
+
+//go:embed data/iso4217.csv data/iso3166.csv
+var isoData embed.FS
+
+// ISO4217Currency is an active currency per https://www.iso.org/iso-4217-currency-codes.html.
+// Fund codes and codes without minor units, such as precious metals, are left
+// out as resellers aren't billed in them.
+type ISO4217Currency struct {
+	Code       string
+	Numeric    string
+	Name       string
+	MinorUnits int
+}
+
+// ISO3166Country is a country per https://www.iso.org/iso-3166-country-codes.html.
+type ISO3166Country struct {
+	Alpha2  string
+	Alpha3  string
+	Numeric string
+	Name    string
+}
+
+var (
+	ISO4217Currencies = loadISO4217("data/iso4217.csv")
+	ISO3166Countries  = loadISO3166("data/iso3166.csv")
+
+	// CurrencyCodes and CountryCodes are the sets validation checks against.
+	CurrencyCodes = codeSet(ISO4217Currencies)
+	CountryCodes  = codeSet(ISO3166Countries)
+)
+
+func readISOData(name string) [][]string {
+	f, err := isoData.Open(name)
+	Assert(err == nil, "open %s: %v", name, err)
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	Assert(err == nil, "read %s: %v", name, err)
+	Assert(len(records) > 1, "%s has no rows", name)
+
+	// Skip header row.
+	return records[1:]
+}
+
+func loadISO4217(name string) map[string]ISO4217Currency {
+	records := readISOData(name)
+	m := make(map[string]ISO4217Currency, len(records))
+	for _, r := range records {
+		minorUnits, err := strconv.Atoi(r[2])
+		Assert(err == nil, "%s: minor units of %s: %v", name, r[0], err)
+		m[r[0]] = ISO4217Currency{
+			Code:       r[0],
+			Numeric:    r[1],
+			Name:       r[3],
+			MinorUnits: minorUnits,
+		}
+	}
+	return m
+}
+
+func loadISO3166(name string) map[string]ISO3166Country {
+	records := readISOData(name)
+	m := make(map[string]ISO3166Country, len(records))
+	for _, r := range records {
+		m[r[0]] = ISO3166Country{
+			Alpha2:  r[0],
+			Alpha3:  r[1],
+			Numeric: r[2],
+			Name:    r[3],
+		}
+	}
+	return m
+}
+
+func codeSet[V any](m map[string]V) map[string]struct{} {
+	s := make(map[string]struct{}, len(m))
+	for k := range m {
+		s[k] = struct{}{}
+	}
+	return s
+}
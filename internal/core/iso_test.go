@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestISO4217Currencies(t *testing.T) {
+	tests := map[string]struct {
+		numeric    string
+		minorUnits int
+	}{
+		"DKK": {"208", 2},
+		"GBP": {"826", 2},
+		"JPY": {"392", 0},
+		"BHD": {"048", 3},
+	}
+
+	for code, tt := range tests {
+		t.Run(code, func(t *testing.T) {
+			c, ok := ISO4217Currencies[code]
+			require.True(t, ok)
+			assert.Equal(t, code, c.Code)
+			assert.Equal(t, tt.numeric, c.Numeric)
+			assert.Equal(t, tt.minorUnits, c.MinorUnits)
+			assert.NotEmpty(t, c.Name)
+			assert.Equal(t, tt.minorUnits, MustParseCurrencyCode(code).MinorUnits())
+		})
+	}
+	assert.Len(t, CurrencyCodes, len(ISO4217Currencies))
+}
+
+func TestISO3166Countries(t *testing.T) {
+	c, ok := ISO3166Countries["DK"]
+	require.True(t, ok)
+	assert.Equal(t, ISO3166Country{Alpha2: "DK", Alpha3: "DNK", Numeric: "208", Name: "Denmark"}, c)
+	assert.Len(t, ISO3166Countries, 249)
+	assert.Len(t, CountryCodes, len(ISO3166Countries))
+}
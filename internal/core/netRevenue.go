@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Domain
+
+// Net revenue is what a reseller is tiered on. As resellers dispute their tier
+// based on it, the calculation must be reproducible by hand from a billing, the
+// product groups its products were assigned to when it was recorded, and the
+// weights of those product groups in effect when it was booked:
+//
+//  1. An item's weight is the weight in effect on the billing's BookedAt of the
+//     product group that the item's product is assigned to when the billing is
+//     recorded. See ProductGroup.EffectiveWeight. Product group assignments
+//     aren't kept over time, so net revenue is calculated once on recording
+//     and stored with the billing. Reassigning a product later doesn't change
+//     the net revenue of billings already recorded.
+//  2. An item's net revenue is its gross revenue × weight / 100, rounded once
+//     to the minor units of the billing's currency, half to even.
+//  3. A billing's net revenue is the sum of its items' rounded net revenue.
+//     Summing rounded amounts ensures the billing adds up to its items, at the
+//     cost of possibly differing by a few minor units from rounding the sum.
+//
+// A credit memo's gross revenue is negative. Rounding half to even is
+// symmetric around zero, so a credit memo of an invoice's items exactly
+// offsets that invoice's net revenue.
+
+// CalculateNetRevenue derives the item's net revenue from its gross revenue and
+// weight. The amount is rounded to the minor units of code.
+func (i *ResellerBillingItem) CalculateNetRevenue(weight *ProductGroupWeight, code CurrencyCode) {
+	net := mulDiv(i.GrossRevenue.Amount, weight.Percentage.V(), NewDecimalFromInt(100), int32(code.MinorUnits()))
+	i.CalculatedNetRevenue = NewMoney(net, code)
+}
+
+// CalculateNetRevenue derives every item's net revenue and rolls them up into
+// the billing's net revenue. Weights are by product code and must hold a
+// weight for every item's product.
+func (b *ResellerBilling) CalculateNetRevenue(weights map[ProductCode]*ProductGroupWeight) {
+	total := NewDecimalFromInt(0)
+	for i := range b.ResellerBillingItems {
+		item := &b.ResellerBillingItems[i]
+		weight, ok := weights[item.ProductCode]
+		Assert(ok, "missing weight for product %s", item.ProductCode)
+		item.CalculateNetRevenue(weight, b.CurrencyCode)
+		total = total.Add(item.CalculatedNetRevenue.Amount)
+	}
+	b.CalculatedNetRevenue = NewMoney(total, b.CurrencyCode)
+}
+
+// NetRevenueCalculator is a domain service as the weight of an item is held by
+// aggregates other than the reseller: the item's product and its product
+// group.
+type NetRevenueCalculator struct {
+	Products      ProductStore
+	ProductGroups ProductGroupStore
+}
+
+// Calculate looks up the weight of each item's product on the billing's
+// BookedAt, by the product's current product group, and calculates the
+// billing's net revenue. Each product and product group is looked up once
+// regardless of how many items refer to it.
+func (c NetRevenueCalculator) Calculate(ctx context.Context, billing *ResellerBilling) error {
+	weights := map[ProductCode]*ProductGroupWeight{}
+	productGroups := map[ProductGroupCode]*ProductGroup{}
+	for _, item := range billing.ResellerBillingItems {
+		if _, ok := weights[item.ProductCode]; ok {
+			continue
+		}
+
+		product, err := c.Products.GetByCode(ctx, item.ProductCode)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return NewNotFoundError("Product", "Code", item.ProductCode.V())
+		}
+		if product.ProductGroup == nil {
+			return NewDomainError(
+				ProductExpectedProductGroupSet,
+				fmt.Sprintf("calculate net revenue requires product %s to have a product group", product.Code))
+		}
+
+		productGroup, ok := productGroups[product.ProductGroup.Code]
+		if !ok {
+			productGroup, err = c.ProductGroups.GetByCode(ctx, product.ProductGroup.Code)
+			if err != nil {
+				return err
+			}
+			if productGroup == nil {
+				return NewNotFoundError("ProductGroup", "Code", product.ProductGroup.Code.V())
+			}
+			productGroups[productGroup.Code] = productGroup
+		}
+
+		weight, err := productGroup.EffectiveWeight(billing.BookedAt)
+		if err != nil {
+			return err
+		}
+		weights[item.ProductCode] = weight
+	}
+
+	billing.CalculateNetRevenue(weights)
+	return nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWeight(percentage string) *ProductGroupWeight {
+	w := NewProductGroupWeight(
+		MustParseProductGroupWeightID(uuid.New()),
+		MustParseWeight(MustParseDecimal(percentage)),
+		MustParseProductGroupWeightFrom(NewDate(2026, 1, 1)),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	return &w
+}
+
+func newTestBilling(code string, items map[string]string) *ResellerBilling {
+	b := &ResellerBilling{
+		BookedAt:     NewDate(2026, 1, 1),
+		CurrencyCode: MustParseCurrencyCode(code),
+	}
+	for productCode, gross := range items {
+		b.ResellerBillingItems = append(b.ResellerBillingItems, ResellerBillingItem{
+			ProductCode:  MustParseProductCode(productCode),
+			GrossRevenue: Money{Amount: MustParseDecimal(gross), Code: code},
+		})
+	}
+	return b
+}
+
+func TestCalculateNetRevenue(t *testing.T) {
+	weights := map[ProductCode]*ProductGroupWeight{
+		MustParseProductCode("HW"):   newTestWeight("50"),
+		MustParseProductCode("SW"):   newTestWeight("12.5"),
+		MustParseProductCode("FREE"): newTestWeight("0"),
+		MustParseProductCode("FULL"): newTestWeight("100"),
+	}
+
+	tests := map[string]struct {
+		code     string
+		items    map[string]string
+		expected map[string]string
+		total    string
+	}{
+		"exact":           {"DKK", map[string]string{"HW": "100.50"}, map[string]string{"HW": "50.25"}, "50.25"},
+		"half to even":    {"DKK", map[string]string{"HW": "0.05"}, map[string]string{"HW": "0.02"}, "0.02"},
+		"half to even up": {"DKK", map[string]string{"HW": "0.07"}, map[string]string{"HW": "0.04"}, "0.04"},
+		"credit memo":     {"DKK", map[string]string{"HW": "-0.05"}, map[string]string{"HW": "-0.02"}, "-0.02"},
+		"zero weight":     {"DKK", map[string]string{"FREE": "999.99"}, map[string]string{"FREE": "0"}, "0"},
+		"full weight":     {"DKK", map[string]string{"FULL": "999.99"}, map[string]string{"FULL": "999.99"}, "999.99"},
+		"no minor units":  {"JPY", map[string]string{"SW": "1004"}, map[string]string{"SW": "126"}, "126"},
+		"sum of rounded":  {"DKK", map[string]string{"HW": "0.01", "SW": "0.04"}, map[string]string{"HW": "0", "SW": "0"}, "0"},
+		"multiple groups": {"DKK", map[string]string{"HW": "10", "SW": "80", "FULL": "1.01"}, map[string]string{"HW": "5", "SW": "10", "FULL": "1.01"}, "16.01"},
+		"no items":        {"DKK", map[string]string{}, map[string]string{}, "0"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := newTestBilling(tt.code, tt.items)
+
+			b.CalculateNetRevenue(weights)
+
+			for _, item := range b.ResellerBillingItems {
+				assert.Equal(t, MustParseDecimal(tt.expected[item.ProductCode.V()]), item.CalculatedNetRevenue.Amount, item.ProductCode.V())
+				assert.Equal(t, tt.code, item.CalculatedNetRevenue.Code)
+			}
+			assert.Equal(t, MustParseDecimal(tt.total), b.CalculatedNetRevenue.Amount)
+			assert.Equal(t, tt.code, b.CalculatedNetRevenue.Code)
+		})
+	}
+}
+
+func TestCalculateNetRevenueCreditMemoOffsetsInvoice(t *testing.T) {
+	weights := map[ProductCode]*ProductGroupWeight{MustParseProductCode("SW"): newTestWeight("33.33")}
+	invoice := newTestBilling("DKK", map[string]string{"SW": "1234.57"})
+	creditMemo := newTestBilling("DKK", map[string]string{"SW": "-1234.57"})
+
+	invoice.CalculateNetRevenue(weights)
+	creditMemo.CalculateNetRevenue(weights)
+
+	assert.True(t, invoice.CalculatedNetRevenue.Amount.Add(creditMemo.CalculatedNetRevenue.Amount).IsZero())
+}
@@ -1,47 +1,470 @@
 package core
 
 import (
+	"context"
+	"fmt"
 	"time"
 	"uuid"
 )
 
-type ProductRepository interface {
+// Domain
+
+type ProductStore interface {
+	ExistByID(context.Context, ProductID) (bool, error)
+	ExistByCode(context.Context, ProductCode) (bool, error)
+	GetByCode(context.Context, ProductCode) (*Product, error)
+	List(context.Context, ProductCriteria) ([]*Product, error)
 }
 
-type ProductCode string
+// ProductCriteria selects products ordered by code. A nil filter field doesn't
+// filter.
+type ProductCriteria struct {
+	ProductGroupCode *ProductGroupCode
+	AfterCode        *ProductCode
+	Limit            int
+}
 
 type ProductCreatedEvent struct {
-	ProductId   uuid.UUID
-	ProductCode ProductCode
+	domainEventCommon
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
 }
 
 type ProductGroupAssignedEvent struct {
-	ProductCode      ProductCode
-	ProductGroupCode ProductGroupCode
+	domainEventCommon
+	ProductID        uuid.UUID `json:"product_id"`
+	ProductGroupID   uuid.UUID `json:"product_group_id"`
+	ProductGroupCode string    `json:"product_group_code"`
 }
 
 type ProductGroupUnassignedEvent struct {
-	ProductCode ProductCode
+	domainEventCommon
+	ProductID      uuid.UUID `json:"product_id"`
+	ProductGroupID uuid.UUID `json:"product_group_id"`
+}
+
+type ProductRemovedEvent struct {
+	domainEventCommon
+	ID uuid.UUID `json:"id"`
 }
 
 const (
 	ProductExpectedDifferentProductGroup = 1000
 	ProductExpectedProductGroupSet       = 1001
+	ProductExpectedNoBillingsForRemoval  = 1002
+)
+
+// ProductID
+
+type ProductID struct {
+	v uuid.UUID
+}
+
+func (p ProductID) V() uuid.UUID   { return p.v }
+func (p ProductID) String() string { return p.v.String() }
+
+func ParseProductID(v uuid.UUID) (ProductID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ProductID{}, err
+	}
+	return ProductID{v}, nil
+}
+
+func MustParseProductID(v uuid.UUID) ProductID {
+	v1, err := ParseProductID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ProductCode
+
+const (
+	ProductCodeLengthMin = 1
+	ProductCodeLengthMax = 10
 )
 
+type ProductCode struct {
+	v string
+}
+
+func (p ProductCode) V() string      { return p.v }
+func (p ProductCode) String() string { return p.v }
+
+func ParseProductCode(v string) (ProductCode, error) {
+	errs := &FieldParseError{}
+	if err := ValidateStringInclusiveLength(v, ProductCodeLengthMin, ProductCodeLengthMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateStringCode(v); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := errs.NilOrError(); err != nil {
+		return ProductCode{}, err
+	}
+	return ProductCode{v}, nil
+}
+
+func MustParseProductCode(v string) ProductCode {
+	v1, err := ParseProductCode(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ProductGroupRef refers to the product group a product is assigned to. As
+// product groups are referred to by code, the code is kept with the ID.
+type ProductGroupRef struct {
+	ID   uuid.UUID
+	Code ProductGroupCode
+}
+
+// Product is a product billed to resellers. Until a product is assigned a
+// product group, its revenue has no weight.
 type Product struct {
 	AggregateRoot
-	Code ProductCode
+	Code         ProductCode
+	ProductGroup *ProductGroupRef
+}
+
+func NewProduct(id ProductID, code ProductCode, createdAt time.Time) Product {
+	p := Product{
+		ID:        id.V(),
+		CreatedAt: createdAt,
+		Code:      code,
+	}
+
+	p.AddDomainEvent(ProductCreatedEvent{
+		OccurredAt: createdAt,
+		ID:         id.V(),
+		Code:       code.V(),
+	})
+	return p
+}
+
+func (p *Product) Equal(other *Product) bool {
+	return EntityEqual(p, other)
+}
+
+func (p *Product) AssignProductGroup(productGroup *ProductGroup, updatedAt time.Time) error {
+	if p.ProductGroup != nil && p.ProductGroup.ID == productGroup.ID {
+		return NewDomainError(
+			ProductExpectedDifferentProductGroup,
+			fmt.Sprintf("assign product group requires a product group different from %s", productGroup.Code))
+	}
+
+	p.ProductGroup = &ProductGroupRef{
+		ID:   productGroup.ID,
+		Code: productGroup.Code,
+	}
+	p.UpdatedAt = &updatedAt
+	p.AddDomainEvent(ProductGroupAssignedEvent{
+		OccurredAt:       updatedAt,
+		ProductID:        p.ID,
+		ProductGroupID:   productGroup.ID,
+		ProductGroupCode: productGroup.Code.V(),
+	})
+	return nil
+}
+
+func (p *Product) UnassignProductGroup(updatedAt time.Time) error {
+	if p.ProductGroup == nil {
+		return NewDomainError(
+			ProductExpectedProductGroupSet,
+			fmt.Sprintf("unassign product group requires product %s to have a product group", p.Code))
+	}
+
+	productGroupID := p.ProductGroup.ID
+	p.ProductGroup = nil
+	p.UpdatedAt = &updatedAt
+	p.AddDomainEvent(ProductGroupUnassignedEvent{
+		OccurredAt:     updatedAt,
+		ProductID:      p.ID,
+		ProductGroupID: productGroupID,
+	})
+	return nil
+}
+
+func (p *Product) Remove(removeAt time.Time) {
+	p.AddDomainEvent(ProductRemovedEvent{
+		OccurredAt: removeAt,
+		ID:         p.ID,
+	})
+}
+
+// Application
+
+type CreateProductCommand struct {
+	ID   uuid.UUID
+	Code string
+}
+
+type CreateProductHandler struct {
+	Products  ProductStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h CreateProductHandler) Handle(ctx context.Context, req CreateProductCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseProductID)
+	code := parser.Parse("Code", req.Code, ParseProductCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	exist, err := h.Products.ExistByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("Product", "ID", id.String())
+	}
+
+	exist, err = h.Products.ExistByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("Product", "Code", code.V())
+	}
+
+	product := NewProduct(id, code, h.Clock.NowUTC())
+	return h.Projector.Apply(ctx, &product)
+}
+
+type AssignProductGroupCommand struct {
+	Code             string
+	ProductGroupCode string
+	ExpectedVersion  *int32
+}
+
+type AssignProductGroupHandler struct {
+	Products      ProductStore
+	ProductGroups ProductGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h AssignProductGroupHandler) Handle(ctx context.Context, req AssignProductGroupCommand) error {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductCode)
+	productGroupCode := parser.Parse("ProductGroupCode", req.ProductGroupCode, ParseProductGroupCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	product, err := h.Products.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return NewNotFoundError("Product", "Code", code.V())
+	}
+	if err := product.CheckVersion("Product", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, productGroupCode)
+	if err != nil {
+		return err
+	}
+	if productGroup == nil {
+		return NewNotFoundError("ProductGroup", "Code", productGroupCode.V())
+	}
+
+	if err := product.AssignProductGroup(productGroup, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, product)
+}
+
+type UnassignProductGroupCommand struct {
+	Code            string
+	ExpectedVersion *int32
+}
+
+type UnassignProductGroupHandler struct {
+	Products  ProductStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h UnassignProductGroupHandler) Handle(ctx context.Context, req UnassignProductGroupCommand) error {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	product, err := h.Products.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return NewNotFoundError("Product", "Code", code.V())
+	}
+	if err := product.CheckVersion("Product", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	if err := product.UnassignProductGroup(h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, product)
+}
+
+type RemoveProductCommand struct {
+	Code            string
+	ExpectedVersion *int32
+}
+
+type RemoveProductHandler struct {
+	Products  ProductStore
+	Resellers ResellerStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h RemoveProductHandler) Handle(ctx context.Context, req RemoveProductCommand) error {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	product, err := h.Products.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return NewNotFoundError("Product", "Code", code.V())
+	}
+	if err := product.CheckVersion("Product", req.ExpectedVersion); err != nil {
+		return err
+	}
+	billed, err := h.Resellers.ExistBillingItemByProduct(ctx, MustParseProductID(product.ID))
+	if err != nil {
+		return err
+	}
+	if billed {
+		return NewDomainError(
+			ProductExpectedNoBillingsForRemoval,
+			fmt.Sprintf("remove product requires no billings of %s", code))
+	}
+
+	product.Remove(h.Clock.NowUTC())
+	return h.Projector.Apply(ctx, product)
+}
+
+type GetProductQuery struct {
+	Code string
+}
+
+type ProductResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	Version          int32      `json:"-"`
+	Code             string     `json:"code"`
+	ProductGroupCode *string    `json:"product_group_code"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at"`
 }
 
-func NewProduct(id uuid.UUID, code ProductCode, createdAt time.Time) Product {
-	return Product{}
+type GetProductHandler struct {
+	Products ProductStore
 }
 
-func (p *Product) AssignProductGroup(productGroup ProductGroup, updatedAt time.Time) {
+func (h GetProductHandler) Handle(ctx context.Context, req GetProductQuery) (*ProductResponse, error) {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductCode)
+	if parser.HasErrors() {
+		return nil, parser
+	}
 
+	product, err := h.Products.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, NewNotFoundError("Product", "Code", code.V())
+	}
+	return newProductResponse(product), nil
 }
 
-func (p *Product) UnassignProductGroup(updatedAt time.Time) {
+func newProductResponse(product *Product) *ProductResponse {
+	var productGroupCode *string
+	if product.ProductGroup != nil {
+		code := product.ProductGroup.Code.V()
+		productGroupCode = &code
+	}
+	return &ProductResponse{
+		ID:               product.ID,
+		Version:          product.Version,
+		Code:             product.Code.V(),
+		ProductGroupCode: productGroupCode,
+		CreatedAt:        product.CreatedAt,
+		UpdatedAt:        product.UpdatedAt,
+	}
+}
+
+type ListProductsQuery struct {
+	ProductGroupCode *string
+	Cursor           string
+	Limit            int
+}
+
+type ListProductsResponse struct {
+	Items      []*ProductResponse `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type ListProductsHandler struct {
+	Products ProductStore
+}
+
+func (h ListProductsHandler) Handle(ctx context.Context, req ListProductsQuery) (*ListProductsResponse, error) {
+	parser := &RequestParseCollector{}
+	var productGroupCode *ProductGroupCode
+	if req.ProductGroupCode != nil {
+		code := parser.Parse("ProductGroupCode", *req.ProductGroupCode, ParseProductGroupCode)
+		productGroupCode = &code
+	}
+	cursor := parser.Parse("Cursor", req.Cursor, parseProductCursor)
+	limit := parser.Parse("Limit", req.Limit, ParsePageLimit)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	products, err := h.Products.List(ctx, ProductCriteria{
+		ProductGroupCode: productGroupCode,
+		AfterCode:        cursor,
+		Limit:            limit.V() + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page, next := nextPage(products, limit, func(p *Product) string { return p.Code.V() })
+	items := make([]*ProductResponse, len(page))
+	for i, p := range page {
+		items[i] = newProductResponse(p)
+	}
+	return &ListProductsResponse{
+		Items:      items,
+		NextCursor: next,
+	}, nil
+}
 
+func parseProductCursor(v string) (*ProductCode, error) {
+	cursor, err := ParsePageCursor(v)
+	if err != nil || cursor.IsFirst() {
+		return nil, err
+	}
+	code, err := ParseProductCode(cursor.After())
+	if err != nil {
+		return nil, fmt.Errorf("must be a cursor from a previous page, but was %s", v)
+	}
+	return &code, nil
 }
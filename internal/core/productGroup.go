@@ -1,24 +1,801 @@
 package core
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"uuid"
+)
+
+// Domain
+
+type ProductGroupStore interface {
+	ExistByID(context.Context, ProductGroupID) (bool, error)
+	ExistByCode(context.Context, ProductGroupCode) (bool, error)
+	GetByCode(context.Context, ProductGroupCode) (*ProductGroup, error)
+	List(context.Context, ProductGroupCriteria) ([]*ProductGroup, error)
+}
+
+// ProductGroupCriteria selects product groups ordered by code. A nil filter
+// field doesn't filter.
+type ProductGroupCriteria struct {
+	AfterCode *ProductGroupCode
+	Limit     int
+}
+
+type ProductGroupCreatedEvent struct {
+	domainEventCommon
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
+}
+
+type ProductGroupWeightAddedEvent struct {
+	domainEventCommon
+	ProductGroupID       uuid.UUID `json:"product_group_id"`
+	ProductGroupWeightID uuid.UUID `json:"product_group_weight_id"`
+	Percentage           Decimal   `json:"percentage"`
+	From                 Date      `json:"from"`
+}
+
+type ProductGroupWeightUpdatedEvent struct {
+	domainEventCommon
+	ProductGroupID       uuid.UUID `json:"product_group_id"`
+	ProductGroupWeightID uuid.UUID `json:"product_group_weight_id"`
+	Percentage           Decimal   `json:"percentage"`
+	From                 Date      `json:"from"`
+}
+
+type ProductGroupWeightRemovedEvent struct {
+	domainEventCommon
+	ProductGroupID       uuid.UUID `json:"product_group_id"`
+	ProductGroupWeightID uuid.UUID `json:"product_group_weight_id"`
+}
+
+type ProductGroupRemovedEvent struct {
+	domainEventCommon
+	ID uuid.UUID `json:"id"`
+}
+
+// NoProductGroupWeightError signals that no weight of a product group is in
+// effect on a date, i.e., the product group has no weights or every weight is
+// from a later date.
+type NoProductGroupWeightError struct {
+	Code string
+	On   Date
+}
+
+func NewNoProductGroupWeightError(code ProductGroupCode, on Date) *NoProductGroupWeightError {
+	return &NoProductGroupWeightError{Code: code.V(), On: on}
+}
+
+func (e *NoProductGroupWeightError) Error() string {
+	return fmt.Sprintf("no weight for product group %s in effect on %s", e.Code, e.On)
+}
 
 const (
 	ProductGroupCodeExpectedFutureFromForAdd            = 1100
 	ProductGroupCodeExpectedFutureFromForUpdate         = 1101
 	ProductGroupCodeExpectedFutureFromForWeightRemoval  = 1102
 	ProductGroupCodeExpectedDifferentProductGroupWeight = 1103
+	ProductGroupCodeExpectedNoProductsForRemoval        = 1104
+)
+
+const (
+	ProductGroupWeightDecimalPlacesMin = 0
+	ProductGroupWeightDecimalPlacesMax = 2
+)
+
+var (
+	ProductGroupWeightMin = NewDecimalFromInt(0)
+	ProductGroupWeightMax = NewDecimalFromInt(100)
+)
+
+// ProductGroupID
+
+type ProductGroupID struct {
+	v uuid.UUID
+}
+
+func (p ProductGroupID) V() uuid.UUID   { return p.v }
+func (p ProductGroupID) String() string { return p.v.String() }
+
+func ParseProductGroupID(v uuid.UUID) (ProductGroupID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ProductGroupID{}, err
+	}
+	return ProductGroupID{v}, nil
+}
+
+func MustParseProductGroupID(v uuid.UUID) ProductGroupID {
+	v1, err := ParseProductGroupID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ProductGroupWeightID
+
+type ProductGroupWeightID struct {
+	v uuid.UUID
+}
+
+func (p ProductGroupWeightID) V() uuid.UUID   { return p.v }
+func (p ProductGroupWeightID) String() string { return p.v.String() }
+
+func ParseProductGroupWeightID(v uuid.UUID) (ProductGroupWeightID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ProductGroupWeightID{}, err
+	}
+	return ProductGroupWeightID{v}, nil
+}
+
+func MustParseProductGroupWeightID(v uuid.UUID) ProductGroupWeightID {
+	v1, err := ParseProductGroupWeightID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ProductGroupCode
+
+const (
+	ProductGroupCodeLengthMin = 1
+	ProductGroupCodeLengthMax = 10
 )
 
-type ProductGroupCode string
+type ProductGroupCode struct {
+	v string
+}
+
+func (p ProductGroupCode) V() string      { return p.v }
+func (p ProductGroupCode) String() string { return p.v }
+
+func ParseProductGroupCode(v string) (ProductGroupCode, error) {
+	errs := &FieldParseError{}
+	if err := ValidateStringInclusiveLength(v, ProductGroupCodeLengthMin, ProductGroupCodeLengthMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateStringCode(v); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := errs.NilOrError(); err != nil {
+		return ProductGroupCode{}, err
+	}
+	return ProductGroupCode{v}, nil
+}
+
+func MustParseProductGroupCode(v string) ProductGroupCode {
+	v1, err := ParseProductGroupCode(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// Weight is the percentage of a product's gross revenue which counts toward a
+// reseller's net revenue.
+type Weight struct {
+	v Decimal
+}
+
+func (w Weight) V() Decimal     { return w.v }
+func (w Weight) String() string { return w.v.String() }
+
+func ParseWeight(v Decimal) (Weight, error) {
+	errs := &FieldParseError{}
+	if err := ValidateDecimalInclusiveRange(v, ProductGroupWeightMin, ProductGroupWeightMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateDecimalPlaces(v, ProductGroupWeightDecimalPlacesMin, ProductGroupWeightDecimalPlacesMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := errs.NilOrError(); err != nil {
+		return Weight{}, err
+	}
+	return Weight{v}, nil
+}
+
+func MustParseWeight(v Decimal) Weight {
+	v1, err := ParseWeight(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+var (
+	ProductGroupWeightFromMin = NewDate(2024, 1, 1)
+	ProductGroupWeightFromMax = NewDate(2034, 12, 31)
+)
+
+type ProductGroupWeightFrom struct {
+	v Date
+}
+
+func (f ProductGroupWeightFrom) V() Date        { return f.v }
+func (f ProductGroupWeightFrom) String() string { return f.v.String() }
+
+func ParseProductGroupWeightFrom(v Date) (ProductGroupWeightFrom, error) {
+	if err := ValidateDateInclusiveRange(v, ProductGroupWeightFromMin, ProductGroupWeightFromMax); err != nil {
+		return ProductGroupWeightFrom{}, err
+	}
+	return ProductGroupWeightFrom{v}, nil
+}
+
+func MustParseProductGroupWeightFrom(v Date) ProductGroupWeightFrom {
+	v1, err := ParseProductGroupWeightFrom(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
 
 type ProductGroupWeight struct {
 	Entity
-	Percentage float64
-	From       time.Time
+	Percentage Weight
+	From       ProductGroupWeightFrom
+}
+
+func NewProductGroupWeight(id ProductGroupWeightID, percentage Weight, from ProductGroupWeightFrom, createdAt time.Time) ProductGroupWeight {
+	return ProductGroupWeight{
+		ID:         id.V(),
+		CreatedAt:  createdAt,
+		UpdatedAt:  nil,
+		Percentage: percentage,
+		From:       from,
+	}
+}
+
+func (w *ProductGroupWeight) Update(percentage Weight, from ProductGroupWeightFrom, updatedAt time.Time) error {
+	today := DateFromTime(updatedAt)
+	if !from.V().After(today) {
+		return NewDomainError(
+			ProductGroupCodeExpectedFutureFromForUpdate,
+			fmt.Sprintf("update product group weight requires from %s be after today %s", from, today))
+	}
+
+	if w.Percentage == percentage && w.From == from {
+		return NewDomainError(
+			ProductGroupCodeExpectedDifferentProductGroupWeight,
+			fmt.Sprintf("update product group weight requires a percentage different from %s and/or a from different from %s", percentage, from))
+	}
+
+	w.Percentage = percentage
+	w.From = from
+	w.UpdatedAt = &updatedAt
+	return nil
+}
+
+func (w *ProductGroupWeight) Equal(other *ProductGroupWeight) bool {
+	return EntityEqual(w, other)
 }
 
 type ProductGroup struct {
 	AggregateRoot
 	Code                ProductGroupCode
-	ProductGroupWeights []ProductGroupWeight
+	ProductGroupWeights []*ProductGroupWeight
+}
+
+func NewProductGroup(id ProductGroupID, code ProductGroupCode, createdAt time.Time) ProductGroup {
+	pg := ProductGroup{
+		ID:                  id.V(),
+		CreatedAt:           createdAt,
+		Code:                code,
+		ProductGroupWeights: []*ProductGroupWeight{},
+	}
+
+	pg.AddDomainEvent(ProductGroupCreatedEvent{
+		OccurredAt: createdAt,
+		ID:         id.V(),
+		Code:       code.V(),
+	})
+	return pg
+}
+
+func (pg *ProductGroup) Equal(other *ProductGroup) bool {
+	return EntityEqual(pg, other)
+}
+
+func (pg *ProductGroup) AddWeight(weight ProductGroupWeight, createdAt time.Time) error {
+	for _, w := range pg.ProductGroupWeights {
+		if w.ID == weight.ID {
+			return NewConflictError("ProductGroupWeight", "ID", weight.ID.String())
+		}
+		if w.From == weight.From {
+			return NewConflictError("ProductGroupWeight", "From", weight.From.String())
+		}
+	}
+
+	today := DateFromTime(createdAt)
+	if !weight.From.V().After(today) {
+		return NewDomainError(
+			ProductGroupCodeExpectedFutureFromForAdd,
+			fmt.Sprintf("add product group weight requires from %s be after today %s", weight.From, today))
+	}
+
+	pg.ProductGroupWeights = append(pg.ProductGroupWeights, &weight)
+	pg.AddDomainEvent(ProductGroupWeightAddedEvent{
+		OccurredAt:           createdAt,
+		ProductGroupID:       pg.ID,
+		ProductGroupWeightID: weight.ID,
+		Percentage:           weight.Percentage.V(),
+		From:                 weight.From.V(),
+	})
+	return nil
+}
+
+func (pg *ProductGroup) UpdateWeight(weightID ProductGroupWeightID, percentage Weight, from ProductGroupWeightFrom, updatedAt time.Time) error {
+	var (
+		weightByID   *ProductGroupWeight
+		weightByFrom *ProductGroupWeight
+	)
+	for _, w := range pg.ProductGroupWeights {
+		if w.ID == weightID.V() {
+			weightByID = w
+		}
+		if w.From == from {
+			weightByFrom = w
+		}
+	}
+	if weightByID == nil {
+		return NewNotFoundError("ProductGroupWeight", "ID", weightID.String())
+	}
+	if weightByFrom != nil && weightByFrom.ID != weightID.V() {
+		return NewConflictError("ProductGroupWeight", "From", from.String())
+	}
+
+	if err := weightByID.Update(percentage, from, updatedAt); err != nil {
+		return fmt.Errorf("product group update weight: %w", err)
+	}
+
+	pg.AddDomainEvent(ProductGroupWeightUpdatedEvent{
+		OccurredAt:           updatedAt,
+		ProductGroupID:       pg.ID,
+		ProductGroupWeightID: weightByID.ID,
+		Percentage:           weightByID.Percentage.V(),
+		From:                 weightByID.From.V(),
+	})
+	return nil
+}
+
+func (pg *ProductGroup) RemoveWeight(weightID ProductGroupWeightID, updatedAt time.Time) error {
+	var weight *ProductGroupWeight
+	for _, w := range pg.ProductGroupWeights {
+		if w.ID == weightID.V() {
+			weight = w
+		}
+	}
+	if weight == nil {
+		return NewNotFoundError("ProductGroupWeight", "ID", weightID.String())
+	}
+
+	today := DateFromTime(updatedAt)
+	if !weight.From.V().After(today) {
+		return NewDomainError(
+			ProductGroupCodeExpectedFutureFromForWeightRemoval,
+			fmt.Sprintf("remove product group weight requires from %s be after today %s", weight.From, today))
+	}
+
+	idx := slices.IndexFunc(pg.ProductGroupWeights, weight.Equal)
+	Assert(idx != -1, "missing product group weight %s", weight.ID)
+
+	pg.ProductGroupWeights = slices.Delete(pg.ProductGroupWeights, idx, idx+1)
+	pg.AddDomainEvent(ProductGroupWeightRemovedEvent{
+		OccurredAt:           updatedAt,
+		ProductGroupID:       pg.ID,
+		ProductGroupWeightID: weight.ID,
+	})
+	return nil
+}
+
+// EffectiveWeight returns the weight in effect on a date, i.e., the weight
+// with the latest from not after the date. A weight stays in effect until the
+// next weight takes over, so weights have no end date.
+func (pg *ProductGroup) EffectiveWeight(on Date) (*ProductGroupWeight, error) {
+	effective, ok := effectiveOn(pg.ProductGroupWeights, func(w *ProductGroupWeight) Date { return w.From.V() }, on)
+	if !ok {
+		return nil, NewNoProductGroupWeightError(pg.Code, on)
+	}
+	return effective, nil
+}
+
+func (pg *ProductGroup) RemoveProductGroup(removeAt time.Time) error {
+	today := DateFromTime(removeAt)
+	canRemove := !slices.ContainsFunc(pg.ProductGroupWeights, func(w *ProductGroupWeight) bool {
+		return !w.From.V().After(today)
+	})
+	if !canRemove {
+		return NewDomainError(
+			ProductGroupCodeExpectedFutureFromForWeightRemoval,
+			fmt.Sprintf("remove product group requires all weights to have from after today %s", today))
+	}
+
+	for i := len(pg.ProductGroupWeights) - 1; i >= 0; i-- {
+		if err := pg.RemoveWeight(MustParseProductGroupWeightID(pg.ProductGroupWeights[i].ID), removeAt); err != nil {
+			return fmt.Errorf("remove product group: %w", err)
+		}
+	}
+
+	pg.AddDomainEvent(ProductGroupRemovedEvent{
+		OccurredAt: removeAt,
+		ID:         pg.ID,
+	})
+	return nil
+}
+
+// Application
+
+type CreateProductGroupCommand struct {
+	ID   uuid.UUID
+	Code string
+}
+
+type CreateProductGroupHandler struct {
+	ProductGroups ProductGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h CreateProductGroupHandler) Handle(ctx context.Context, req CreateProductGroupCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseProductGroupID)
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	exist, err := h.ProductGroups.ExistByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("ProductGroup", "ID", id.String())
+	}
+
+	exist, err = h.ProductGroups.ExistByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("ProductGroup", "Code", code.V())
+	}
+
+	productGroup := NewProductGroup(id, code, h.Clock.NowUTC())
+	return h.Projector.Apply(ctx, &productGroup)
+}
+
+type RemoveProductGroupCommand struct {
+	Code            string
+	ExpectedVersion *int32
+}
+
+type RemoveProductGroupHandler struct {
+	ProductGroups ProductGroupStore
+	Products      ProductStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h RemoveProductGroupHandler) Handle(ctx context.Context, req RemoveProductGroupCommand) error {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if productGroup == nil {
+		return NewNotFoundError("ProductGroup", "Code", code.V())
+	}
+	if err := productGroup.CheckVersion("ProductGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	// Products reference their product group, so it must be unassigned from
+	// every product first. The aggregate doesn't know its products, so the
+	// rule is enforced here.
+	products, err := h.Products.List(ctx, ProductCriteria{ProductGroupCode: &code, Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(products) > 0 {
+		return NewDomainError(
+			ProductGroupCodeExpectedNoProductsForRemoval,
+			fmt.Sprintf("remove product group requires no products assigned to %s", code))
+	}
+
+	if err := productGroup.RemoveProductGroup(h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, productGroup)
+}
+
+type AddProductGroupWeightCommand struct {
+	ID              uuid.UUID
+	Code            string
+	Percentage      Decimal
+	From            Date
+	ExpectedVersion *int32
+}
+
+type AddProductGroupWeightHandler struct {
+	ProductGroups ProductGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h AddProductGroupWeightHandler) Handle(ctx context.Context, req AddProductGroupWeightCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseProductGroupWeightID)
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	percentage := parser.Parse("Percentage", req.Percentage, ParseWeight)
+	from := parser.Parse("From", req.From, ParseProductGroupWeightFrom)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if productGroup == nil {
+		return NewNotFoundError("ProductGroup", "Code", code.V())
+	}
+	if err := productGroup.CheckVersion("ProductGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	now := h.Clock.NowUTC()
+	weight := NewProductGroupWeight(id, percentage, from, now)
+	if err := productGroup.AddWeight(weight, now); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, productGroup)
+}
+
+type UpdateProductGroupWeightCommand struct {
+	ID              uuid.UUID
+	Code            string
+	Percentage      Decimal
+	From            Date
+	ExpectedVersion *int32
+}
+
+type UpdateProductGroupWeightHandler struct {
+	ProductGroups ProductGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h UpdateProductGroupWeightHandler) Handle(ctx context.Context, req UpdateProductGroupWeightCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseProductGroupWeightID)
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	percentage := parser.Parse("Percentage", req.Percentage, ParseWeight)
+	from := parser.Parse("From", req.From, ParseProductGroupWeightFrom)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if productGroup == nil {
+		return NewNotFoundError("ProductGroup", "Code", code.V())
+	}
+	if err := productGroup.CheckVersion("ProductGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	if err := productGroup.UpdateWeight(id, percentage, from, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, productGroup)
+}
+
+type RemoveProductGroupWeightCommand struct {
+	ID              uuid.UUID
+	Code            string
+	ExpectedVersion *int32
+}
+
+type RemoveProductGroupWeightHandler struct {
+	ProductGroups ProductGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h RemoveProductGroupWeightHandler) Handle(ctx context.Context, req RemoveProductGroupWeightCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseProductGroupWeightID)
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if productGroup == nil {
+		return NewNotFoundError("ProductGroup", "Code", code.V())
+	}
+	if err := productGroup.CheckVersion("ProductGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	if err := productGroup.RemoveWeight(id, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, productGroup)
+}
+
+type GetProductGroupQuery struct {
+	Code string
+}
+
+type ProductGroupWeightResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Percentage Decimal    `json:"percentage"`
+	From       Date       `json:"from"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+type ProductGroupResponse struct {
+	ID        uuid.UUID                     `json:"id"`
+	Version   int32                         `json:"-"`
+	Code      string                        `json:"code"`
+	Weights   []*ProductGroupWeightResponse `json:"weights"`
+	CreatedAt time.Time                     `json:"created_at"`
+	UpdatedAt *time.Time                    `json:"updated_at"`
+}
+
+type GetProductGroupHandler struct {
+	ProductGroups ProductGroupStore
+}
+
+func (h GetProductGroupHandler) Handle(ctx context.Context, req GetProductGroupQuery) (*ProductGroupResponse, error) {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if productGroup == nil {
+		return nil, NewNotFoundError("ProductGroup", "Code", code.V())
+	}
+
+	return newProductGroupResponse(productGroup), nil
+}
+
+func newProductGroupWeightResponse(w *ProductGroupWeight) *ProductGroupWeightResponse {
+	return &ProductGroupWeightResponse{
+		ID:         w.ID,
+		Percentage: w.Percentage.V(),
+		From:       w.From.V(),
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+}
+
+func newProductGroupResponse(productGroup *ProductGroup) *ProductGroupResponse {
+	weights := make([]*ProductGroupWeightResponse, len(productGroup.ProductGroupWeights))
+	for i, w := range productGroup.ProductGroupWeights {
+		weights[i] = newProductGroupWeightResponse(w)
+	}
+
+	return &ProductGroupResponse{
+		ID:        productGroup.ID,
+		Version:   productGroup.Version,
+		Code:      productGroup.Code.V(),
+		Weights:   weights,
+		CreatedAt: productGroup.CreatedAt,
+		UpdatedAt: productGroup.UpdatedAt,
+	}
+}
+
+type ListProductGroupsQuery struct {
+	Cursor string
+	Limit  int
+}
+
+type ListProductGroupsResponse struct {
+	Items      []*ProductGroupResponse `json:"items"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+type ListProductGroupsHandler struct {
+	ProductGroups ProductGroupStore
+}
+
+func (h ListProductGroupsHandler) Handle(ctx context.Context, req ListProductGroupsQuery) (*ListProductGroupsResponse, error) {
+	parser := &RequestParseCollector{}
+	cursor := parser.Parse("Cursor", req.Cursor, parseProductGroupCursor)
+	limit := parser.Parse("Limit", req.Limit, ParsePageLimit)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	productGroups, err := h.ProductGroups.List(ctx, ProductGroupCriteria{
+		AfterCode: cursor,
+		Limit:     limit.V() + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page, next := nextPage(productGroups, limit, func(pg *ProductGroup) string { return pg.Code.V() })
+	items := make([]*ProductGroupResponse, len(page))
+	for i, pg := range page {
+		items[i] = newProductGroupResponse(pg)
+	}
+	return &ListProductGroupsResponse{
+		Items:      items,
+		NextCursor: next,
+	}, nil
+}
+
+func parseProductGroupCursor(v string) (*ProductGroupCode, error) {
+	cursor, err := ParsePageCursor(v)
+	if err != nil || cursor.IsFirst() {
+		return nil, err
+	}
+	code, err := ParseProductGroupCode(cursor.After())
+	if err != nil {
+		return nil, fmt.Errorf("must be a cursor from a previous page, but was %s", v)
+	}
+	return &code, nil
+}
+
+// parseWeightEffectiveOn bounds the date asked about to the dates a weight may
+// be from. Outside it no weight can be in effect or the date is a mistake.
+func parseWeightEffectiveOn(v Date) (Date, error) {
+	if err := ValidateDateInclusiveRange(v, ProductGroupWeightFromMin, ProductGroupWeightFromMax); err != nil {
+		return Date{}, err
+	}
+	return v, nil
+}
+
+type GetEffectiveProductGroupWeightQuery struct {
+	Code string
+	On   Date
+}
+
+type GetEffectiveProductGroupWeightHandler struct {
+	ProductGroups ProductGroupStore
+}
+
+func (h GetEffectiveProductGroupWeightHandler) Handle(ctx context.Context, req GetEffectiveProductGroupWeightQuery) (*ProductGroupWeightResponse, error) {
+	parser := &RequestParseCollector{}
+	code := parser.Parse("Code", req.Code, ParseProductGroupCode)
+	on := parser.Parse("On", req.On, parseWeightEffectiveOn)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	productGroup, err := h.ProductGroups.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if productGroup == nil {
+		return nil, NewNotFoundError("ProductGroup", "Code", code.V())
+	}
+
+	weight, err := productGroup.EffectiveWeight(on)
+	if err != nil {
+		return nil, err
+	}
+	return newProductGroupWeightResponse(weight), nil
 }
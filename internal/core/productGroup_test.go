@@ -0,0 +1,114 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProductGroupWithWeights(code string, weights map[Date]string) *ProductGroup {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pg := NewProductGroup(MustParseProductGroupID(uuid.New()), MustParseProductGroupCode(code), createdAt)
+	for from, percentage := range weights {
+		w := NewProductGroupWeight(MustParseProductGroupWeightID(uuid.New()), MustParseWeight(MustParseDecimal(percentage)), MustParseProductGroupWeightFrom(from), createdAt)
+		pg.ProductGroupWeights = append(pg.ProductGroupWeights, &w)
+	}
+	pg.ClearDomainEvents()
+	return &pg
+}
+
+func TestParseWeight(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		invalid bool
+	}{
+		"zero":             {"0", false},
+		"hundred":          {"100", false},
+		"two places":       {"12.5", false},
+		"negative":         {"-0.01", true},
+		"above hundred":    {"100.01", true},
+		"too many places":  {"12.345", true},
+		"range and places": {"100.001", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseWeight(MustParseDecimal(tt.value))
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEffectiveWeight(t *testing.T) {
+	pg := newTestProductGroupWithWeights("HW", map[Date]string{
+		NewDate(2026, 3, 1): "75",
+		NewDate(2026, 1, 1): "50",
+		NewDate(2026, 2, 1): "60",
+	})
+
+	tests := map[string]struct {
+		on       Date
+		expected string
+		invalid  bool
+	}{
+		"before first": {NewDate(2025, 12, 31), "", true},
+		"on first":     {NewDate(2026, 1, 1), "50", false},
+		"between":      {NewDate(2026, 1, 31), "50", false},
+		"on middle":    {NewDate(2026, 2, 1), "60", false},
+		"after last":   {NewDate(2027, 1, 1), "75", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w, err := pg.EffectiveWeight(tt.on)
+			if tt.invalid {
+				var noWeight *NoProductGroupWeightError
+				require.ErrorAs(t, err, &noWeight)
+				assert.Equal(t, "HW", noWeight.Code)
+				assert.Equal(t, tt.on, noWeight.On)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.expected), w.Percentage.V())
+		})
+	}
+}
+
+func TestProductGroupWeightFutureFrom(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	pg := newTestProductGroupWithWeights("HW", map[Date]string{
+		NewDate(2026, 1, 1): "50",
+		NewDate(2026, 3, 1): "60",
+	})
+	past, _ := pg.EffectiveWeight(NewDate(2026, 1, 1))
+	future, _ := pg.EffectiveWeight(NewDate(2026, 3, 1))
+	percentage := MustParseWeight(MustParseDecimal("70"))
+
+	var e *DomainError
+	add := NewProductGroupWeight(MustParseProductGroupWeightID(uuid.New()), percentage, MustParseProductGroupWeightFrom(NewDate(2026, 2, 1)), now)
+	require.ErrorAs(t, pg.AddWeight(add, now), &e)
+	assert.Equal(t, ProductGroupCodeExpectedFutureFromForAdd, e.Code)
+
+	require.ErrorAs(t, pg.UpdateWeight(MustParseProductGroupWeightID(future.ID), percentage, MustParseProductGroupWeightFrom(NewDate(2026, 2, 1)), now), &e)
+	assert.Equal(t, ProductGroupCodeExpectedFutureFromForUpdate, e.Code)
+
+	require.ErrorAs(t, pg.UpdateWeight(MustParseProductGroupWeightID(future.ID), future.Percentage, future.From, now), &e)
+	assert.Equal(t, ProductGroupCodeExpectedDifferentProductGroupWeight, e.Code)
+
+	require.ErrorAs(t, pg.RemoveWeight(MustParseProductGroupWeightID(past.ID), now), &e)
+	assert.Equal(t, ProductGroupCodeExpectedFutureFromForWeightRemoval, e.Code)
+
+	require.ErrorAs(t, pg.RemoveProductGroup(now), &e)
+	assert.Equal(t, ProductGroupCodeExpectedFutureFromForWeightRemoval, e.Code)
+
+	require.NoError(t, pg.RemoveWeight(MustParseProductGroupWeightID(future.ID), now))
+	assert.Len(t, pg.ProductGroupWeights, 1)
+	assert.IsType(t, ProductGroupWeightRemovedEvent{}, pg.DomainEvents[0])
+}
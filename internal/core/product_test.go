@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProductGroup(code string) *ProductGroup {
+	return &ProductGroup{
+		AggregateRoot: AggregateRoot{Entity: Entity{ID: uuid.New()}},
+		Code:          MustParseProductGroupCode(code),
+	}
+}
+
+func TestProductGroupAssignment(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProduct(MustParseProductID(uuid.New()), MustParseProductCode("P-1"), at)
+	p.ClearDomainEvents()
+	hardware := newTestProductGroup("HW")
+	software := newTestProductGroup("SW")
+
+	var e *DomainError
+	require.ErrorAs(t, p.UnassignProductGroup(at), &e)
+	assert.Equal(t, ProductExpectedProductGroupSet, e.Code)
+
+	require.NoError(t, p.AssignProductGroup(hardware, at))
+	require.ErrorAs(t, p.AssignProductGroup(hardware, at), &e)
+	assert.Equal(t, ProductExpectedDifferentProductGroup, e.Code)
+
+	require.NoError(t, p.AssignProductGroup(software, at))
+	assert.Equal(t, software.ID, p.ProductGroup.ID)
+
+	require.NoError(t, p.UnassignProductGroup(at))
+	assert.Nil(t, p.ProductGroup)
+
+	require.Len(t, p.DomainEvents, 3)
+	assert.Equal(t, hardware.ID, p.DomainEvents[0].(ProductGroupAssignedEvent).ProductGroupID)
+	assert.Equal(t, software.ID, p.DomainEvents[1].(ProductGroupAssignedEvent).ProductGroupID)
+	assert.Equal(t, software.ID, p.DomainEvents[2].(ProductGroupUnassignedEvent).ProductGroupID)
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Domain
+
+// A projection extrapolates a reseller's year-to-date net revenue to year end,
+// so that account managers may warn resellers about to miss a tier. Like net
+// revenue, a projection must be reproducible by hand:
+//
+//   - Linear assumes the reseller keeps its run rate: year-to-date × days in
+//     the year / days elapsed, including the tiering date, rounded once to the
+//     minor units of the reseller's currency, half to even.
+//   - Seasonal assumes the rest of the year repeats the rest of last year:
+//     year-to-date + last year − net revenue of billings booked last year up
+//     to and including the same date. A reseller without net revenue for last
+//     year is projected linearly.
+//
+// A reseller is projected in its own currency and converted to the revenue
+// group's currency like its year-to-date net revenue. Like when recording a
+// billing, a billing booked last year is converted to the reseller's currency
+// at the rates in effect when it was booked.
+
+type TierProjectionMethod string
+
+const (
+	TierProjectionLinear   TierProjectionMethod = "Linear"
+	TierProjectionSeasonal TierProjectionMethod = "Seasonal"
+)
+
+var TierProjectionMethods = map[TierProjectionMethod]struct{}{
+	TierProjectionLinear: {}, TierProjectionSeasonal: {},
+}
+
+func ParseTierProjectionMethod(v string) (TierProjectionMethod, error) {
+	if _, ok := TierProjectionMethods[TierProjectionMethod(v)]; !ok {
+		return "", fmt.Errorf("must be one of Linear or Seasonal, but was %s", v)
+	}
+	return TierProjectionMethod(v), nil
+}
+
+func MustParseTierProjectionMethod(v string) TierProjectionMethod {
+	v1, err := ParseTierProjectionMethod(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ProjectLinear extrapolates yearToDate on a date to the end of its year.
+func ProjectLinear(yearToDate Money, on Date) Money {
+	yearEnd := NewDate(on.Year(), 12, 31)
+	elapsed := on.YearDay()
+	days := yearEnd.YearDay()
+	code := MustParseCurrencyCode(yearToDate.Code)
+	amount := mulDiv(yearToDate.Amount, NewDecimalFromInt(int64(days)), NewDecimalFromInt(int64(elapsed)), int32(code.MinorUnits()))
+	return NewMoney(amount, code)
+}
+
+// ProjectSeasonal adds the rest of last year, i.e., lastYear less
+// lastYearToDate, to yearToDate. All amounts must be in the same currency.
+func ProjectSeasonal(yearToDate Money, lastYear Money, lastYearToDate Money) Money {
+	Assert(yearToDate.Code == lastYear.Code && lastYear.Code == lastYearToDate.Code, "currencies must match")
+	amount := yearToDate.Amount.Add(lastYear.Amount).Sub(lastYearToDate.Amount)
+	return NewMoney(amount, MustParseCurrencyCode(yearToDate.Code))
+}
+
+// NetRevenueProjection is a domain service as a seasonal projection depends
+// on billings of last year which the reseller doesn't hold.
+type NetRevenueProjection struct {
+	Method     TierProjectionMethod
+	Resellers  ResellerStore
+	Currencies CurrencyStore
+}
+
+// Project returns the reseller's projected net revenue for the year of on in
+// the reseller's currency. Without year-to-date net revenue, the reseller has
+// billed nothing this year and year-to-date is taken to be zero.
+func (p NetRevenueProjection) Project(ctx context.Context, reseller *Reseller, on Date) (Money, error) {
+	yearToDate := NewMoney(NewDecimalFromInt(0), reseller.CurrencyCode)
+	if reseller.CalculatedNetRevenueYearToDate != nil {
+		yearToDate = *reseller.CalculatedNetRevenueYearToDate
+	}
+	if p.Method == TierProjectionLinear || reseller.CalculatedNetRevenueLastYear == nil {
+		return ProjectLinear(yearToDate, on), nil
+	}
+
+	lastYearOn := on.AddDate(-1, 0, 0)
+	lastYearStart := NewDate(lastYearOn.Year(), 1, 1)
+	billings, err := p.Resellers.ListBillingNetRevenue(ctx, MustParseResellerID(reseller.ID), lastYearStart, lastYearOn)
+	if err != nil {
+		return Money{}, err
+	}
+	converter := newCurrencyConverter(p.Currencies)
+	lastYearToDate := NewMoney(NewDecimalFromInt(0), reseller.CurrencyCode)
+	for _, b := range billings {
+		netRevenue, err := converter.convert(ctx, b.NetRevenue, reseller.CurrencyCode, b.BookedAt)
+		if err != nil {
+			return Money{}, err
+		}
+		lastYearToDate.Amount = lastYearToDate.Amount.Add(netRevenue.Amount)
+	}
+	return ProjectSeasonal(yearToDate, *reseller.CalculatedNetRevenueLastYear, lastYearToDate), nil
+}
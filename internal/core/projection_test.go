@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectLinear(t *testing.T) {
+	tests := map[string]struct {
+		yearToDate string
+		code       string
+		on         Date
+		expected   string
+	}{
+		"first day":            {"10", "DKK", NewDate(2026, 1, 1), "3650.00"},
+		"rounded":              {"1000", "DKK", NewDate(2026, 3, 1), "6083.33"},
+		"leap year":            {"1000", "DKK", NewDate(2028, 2, 29), "6100.00"},
+		"last day":             {"1000", "DKK", NewDate(2026, 12, 31), "1000.00"},
+		"half to even":         {"1", "JPY", NewDate(2026, 1, 2), "182"},
+		"no minor units":       {"1000", "JPY", NewDate(2026, 3, 1), "6083"},
+		"low value early":      {"3000000000", "JPY", NewDate(2026, 1, 2), "547500000000"},
+		"negative":             {"-1000", "DKK", NewDate(2026, 3, 1), "-6083.33"},
+		"nothing year to date": {"0", "DKK", NewDate(2026, 3, 1), "0.00"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			code := MustParseCurrencyCode(tt.code)
+			actual := ProjectLinear(NewMoney(MustParseDecimal(tt.yearToDate), code), tt.on)
+			assert.Equal(t, tt.expected, actual.Amount.StringFixed(int32(code.MinorUnits())))
+			assert.Equal(t, tt.code, actual.Code)
+		})
+	}
+}
+
+func TestProjectSeasonal(t *testing.T) {
+	dkk := MustParseCurrencyCode("DKK")
+	money := func(v string) Money { return NewMoney(MustParseDecimal(v), dkk) }
+
+	tests := map[string]struct {
+		yearToDate     string
+		lastYear       string
+		lastYearToDate string
+		expected       string
+	}{
+		"ahead of last year":  {"1000", "5000", "800", "5200"},
+		"behind last year":    {"500", "5000", "800", "4700"},
+		"rest of year billed": {"1000", "5000", "5000", "1000"},
+		"credit memo":         {"1000", "5000", "-200", "6200"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := ProjectSeasonal(money(tt.yearToDate), money(tt.lastYear), money(tt.lastYearToDate))
+			assert.Zero(t, MustParseDecimal(tt.expected).Cmp(actual.Amount), actual.Amount.String())
+		})
+	}
+}
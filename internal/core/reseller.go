@@ -1,48 +1,1017 @@
 package core
 
+import (
+	"context"
+	"fmt"
+	"time"
+	"uuid"
+)
+
+// Domain
+
+type ResellerStore interface {
+	ExistByID(context.Context, ResellerID) (bool, error)
+	ExistByExternalID(context.Context, ResellerExternalID) (bool, error)
+	GetByID(context.Context, ResellerID) (*Reseller, error)
+	GetByExternalID(context.Context, ResellerExternalID) (*Reseller, error)
+	List(context.Context) ([]*Reseller, error)
+	ExistBillingByID(context.Context, ResellerBillingID) (bool, error)
+	ExistBillingByDocumentNumber(context.Context, DocumentNumber) (bool, error)
+	ExistBillingItemByProduct(context.Context, ProductID) (bool, error)
+	// ListBillingNetRevenue lists the net revenue of the reseller's billings
+	// booked between from and to, inclusive, in the billings' currencies.
+	ListBillingNetRevenue(ctx context.Context, id ResellerID, from Date, to Date) ([]BookedNetRevenue, error)
+}
+
+// BookedNetRevenue is the net revenue of a billing in the billing's currency.
+type BookedNetRevenue struct {
+	BookedAt   Date
+	NetRevenue Money
+}
+
+type ResellerEnrolledEvent struct {
+	domainEventCommon
+	ID           uuid.UUID    `json:"id"`
+	ExternalID   uuid.UUID    `json:"external_id"`
+	CountryCode  string       `json:"country_code"`
+	CurrencyCode string       `json:"currency_code"`
+	Role         ResellerRole `json:"role"`
+	EnrolledAt   Date         `json:"enrolled_at"`
+	FiscalYear   int          `json:"fiscal_year"`
+}
+
+type ResellerRoleChangedEvent struct {
+	domainEventCommon
+	ID   uuid.UUID    `json:"id"`
+	Role ResellerRole `json:"role"`
+}
+
+type ResellerCurrencyChangedEvent struct {
+	domainEventCommon
+	ID           uuid.UUID `json:"id"`
+	CurrencyCode string    `json:"currency_code"`
+}
+
+type ResellerJoinedClusterEvent struct {
+	domainEventCommon
+	ResellerID uuid.UUID    `json:"reseller_id"`
+	ClusterID  uuid.UUID    `json:"cluster_id"`
+	Role       ResellerRole `json:"role"`
+}
+
+type ResellerLeftClusterEvent struct {
+	domainEventCommon
+	ResellerID uuid.UUID `json:"reseller_id"`
+	ClusterID  uuid.UUID `json:"cluster_id"`
+}
+
+type ResellerBillingRecordedEvent struct {
+	domainEventCommon
+	ResellerID           uuid.UUID           `json:"reseller_id"`
+	BillingID            uuid.UUID           `json:"billing_id"`
+	DocumentNumber       string              `json:"document_number"`
+	BookedAt             Date                `json:"booked_at"`
+	Kind                 ResellerBillingKind `json:"kind"`
+	CurrencyCode         string              `json:"currency_code"`
+	CalculatedNetRevenue Decimal             `json:"calculated_net_revenue"`
+}
+
+type ResellerBillingItemRecordedEvent struct {
+	domainEventCommon
+	BillingID            uuid.UUID `json:"billing_id"`
+	BillingItemID        uuid.UUID `json:"billing_item_id"`
+	ProductCode          string    `json:"product_code"`
+	CurrencyCode         string    `json:"currency_code"`
+	GrossRevenue         Decimal   `json:"gross_revenue"`
+	CalculatedNetRevenue Decimal   `json:"calculated_net_revenue"`
+}
+
+// ResellerNetRevenueChangedEvent carries the reseller's net revenue in its
+// currency. Nil is net revenue not calculated.
+type ResellerNetRevenueChangedEvent struct {
+	domainEventCommon
+	ID                             uuid.UUID `json:"id"`
+	CalculatedNetRevenueYearToDate *Decimal  `json:"calculated_net_revenue_year_to_date"`
+	CalculatedNetRevenueLastYear   *Decimal  `json:"calculated_net_revenue_last_year"`
+}
+
+// ResellerRolledOverEvent leaves year-to-date net revenue not calculated.
+type ResellerRolledOverEvent struct {
+	domainEventCommon
+	ID                           uuid.UUID `json:"id"`
+	FiscalYear                   int       `json:"fiscal_year"`
+	CalculatedNetRevenueLastYear *Decimal  `json:"calculated_net_revenue_last_year"`
+}
+
+type ResellerUnenrolledEvent struct {
+	domainEventCommon
+	ID uuid.UUID `json:"id"`
+}
+
 const (
 	ExpectedDifferentResellerRole = 1200
 	ExpectedDifferentCurrencyCode = 1201
+	ExpectedOrphanResellerRole    = 1202
+	ResellerExpectedNoCluster     = 1203
+	ResellerExpectedCluster       = 1204
+	ResellerBillingExpectedPast   = 1205
+	ResellerExpectedLaterYear     = 1206
+	ResellerBillingExpectedRecent = 1207
 )
 
-type ResellerBillingItem struct {
-	Entity
-	ProductCode          ProductCode // Example of foreign key not being a UUID because domain
-	GrossRevenue         Money
-	CalculatedNetRevenue Money
+// ResellerID
+
+type ResellerID struct {
+	v uuid.UUID
 }
 
-type ResellerBillingKind string
+func (r ResellerID) V() uuid.UUID   { return r.v }
+func (r ResellerID) String() string { return r.v.String() }
 
-var ResellerBillingKinds = map[ResellerBillingKind]struct{}{
-	"Invoice": {}, "CreditMemo": {},
+func ParseResellerID(v uuid.UUID) (ResellerID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ResellerID{}, err
+	}
+	return ResellerID{v}, nil
+}
+
+func MustParseResellerID(v uuid.UUID) ResellerID {
+	v1, err := ParseResellerID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
 }
 
+// ResellerExternalID identifies a reseller in the system of record for
+// resellers, such as a CRM. Integrations refer to resellers by it.
+type ResellerExternalID struct {
+	v uuid.UUID
+}
+
+func (r ResellerExternalID) V() uuid.UUID   { return r.v }
+func (r ResellerExternalID) String() string { return r.v.String() }
+
+func ParseResellerExternalID(v uuid.UUID) (ResellerExternalID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ResellerExternalID{}, err
+	}
+	return ResellerExternalID{v}, nil
+}
+
+func MustParseResellerExternalID(v uuid.UUID) ResellerExternalID {
+	v1, err := ParseResellerExternalID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ResellerRole is the reseller's position relative to a cluster. An orphan
+// isn't part of a cluster, whereas a head represents one and a member belongs
+// to one.
 type ResellerRole string
 
+const (
+	ResellerRoleOrphan ResellerRole = "Orphan"
+	ResellerRoleHead   ResellerRole = "Head"
+	ResellerRoleMember ResellerRole = "Member"
+)
+
 var ResellerRoles = map[ResellerRole]struct{}{
-	"Orphan": {}, "Head": {}, "Member": {},
+	ResellerRoleOrphan: {}, ResellerRoleHead: {}, ResellerRoleMember: {},
 }
 
-type DocumentNumber string
+func ParseResellerRole(v string) (ResellerRole, error) {
+	if _, ok := ResellerRoles[ResellerRole(v)]; !ok {
+		return "", fmt.Errorf("must be one of Orphan, Head, or Member, but was %s", v)
+	}
+	return ResellerRole(v), nil
+}
+
+func MustParseResellerRole(v string) ResellerRole {
+	v1, err := ParseResellerRole(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+var (
+	ResellerEnrolledAtMin = NewDate(2024, 1, 1)
+	ResellerEnrolledAtMax = NewDate(2034, 12, 31)
+)
+
+type ResellerEnrolledAt struct {
+	v Date
+}
+
+func (e ResellerEnrolledAt) V() Date        { return e.v }
+func (e ResellerEnrolledAt) String() string { return e.v.String() }
+
+func ParseResellerEnrolledAt(v Date) (ResellerEnrolledAt, error) {
+	if err := ValidateDateInclusiveRange(v, ResellerEnrolledAtMin, ResellerEnrolledAtMax); err != nil {
+		return ResellerEnrolledAt{}, err
+	}
+	return ResellerEnrolledAt{v}, nil
+}
+
+func MustParseResellerEnrolledAt(v Date) ResellerEnrolledAt {
+	v1, err := ParseResellerEnrolledAt(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ResellerBillingID
+
+type ResellerBillingID struct {
+	v uuid.UUID
+}
+
+func (r ResellerBillingID) V() uuid.UUID   { return r.v }
+func (r ResellerBillingID) String() string { return r.v.String() }
+
+func ParseResellerBillingID(v uuid.UUID) (ResellerBillingID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ResellerBillingID{}, err
+	}
+	return ResellerBillingID{v}, nil
+}
+
+func MustParseResellerBillingID(v uuid.UUID) ResellerBillingID {
+	v1, err := ParseResellerBillingID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ResellerBillingItemID
+
+type ResellerBillingItemID struct {
+	v uuid.UUID
+}
+
+func (r ResellerBillingItemID) V() uuid.UUID   { return r.v }
+func (r ResellerBillingItemID) String() string { return r.v.String() }
+
+func ParseResellerBillingItemID(v uuid.UUID) (ResellerBillingItemID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return ResellerBillingItemID{}, err
+	}
+	return ResellerBillingItemID{v}, nil
+}
+
+func MustParseResellerBillingItemID(v uuid.UUID) ResellerBillingItemID {
+	v1, err := ParseResellerBillingItemID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// DocumentNumber identifies a billing in the system of record for billings,
+// such as an ERP.
+
+const (
+	DocumentNumberLengthMin = 1
+	DocumentNumberLengthMax = 10
+)
+
+type DocumentNumber struct {
+	v string
+}
+
+func (d DocumentNumber) V() string      { return d.v }
+func (d DocumentNumber) String() string { return d.v }
+
+func ParseDocumentNumber(v string) (DocumentNumber, error) {
+	errs := &FieldParseError{}
+	if err := ValidateStringInclusiveLength(v, DocumentNumberLengthMin, DocumentNumberLengthMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateStringCode(v); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := errs.NilOrError(); err != nil {
+		return DocumentNumber{}, err
+	}
+	return DocumentNumber{v}, nil
+}
+
+func MustParseDocumentNumber(v string) DocumentNumber {
+	v1, err := ParseDocumentNumber(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// ResellerBillingKind tells an invoice, which adds to net revenue, from a
+// credit memo, which subtracts from it.
+type ResellerBillingKind string
+
+const (
+	ResellerBillingKindInvoice    ResellerBillingKind = "Invoice"
+	ResellerBillingKindCreditMemo ResellerBillingKind = "CreditMemo"
+)
+
+var ResellerBillingKinds = map[ResellerBillingKind]struct{}{
+	ResellerBillingKindInvoice: {}, ResellerBillingKindCreditMemo: {},
+}
+
+func ParseResellerBillingKind(v string) (ResellerBillingKind, error) {
+	if _, ok := ResellerBillingKinds[ResellerBillingKind(v)]; !ok {
+		return "", fmt.Errorf("must be one of Invoice or CreditMemo, but was %s", v)
+	}
+	return ResellerBillingKind(v), nil
+}
+
+func MustParseResellerBillingKind(v string) ResellerBillingKind {
+	v1, err := ParseResellerBillingKind(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+var (
+	ResellerBillingBookedAtMin = NewDate(2024, 1, 1)
+	ResellerBillingBookedAtMax = NewDate(2034, 12, 31)
+)
+
+// parseBillingBookedAt bounds when a billing may be booked to the years the
+// program runs.
+func parseBillingBookedAt(v Date) (Date, error) {
+	if err := ValidateDateInclusiveRange(v, ResellerBillingBookedAtMin, ResellerBillingBookedAtMax); err != nil {
+		return Date{}, err
+	}
+	return v, nil
+}
+
+const (
+	ResellerBillingItemsMin = 1
+	ResellerBillingItemsMax = 100
+)
+
+func parseBillingItemCount(v int) (int, error) {
+	if err := ValidateIntInclusiveRange(v, ResellerBillingItemsMin, ResellerBillingItemsMax); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+// ResellerBillingGrossRevenueMax keeps the sum of a billing's items within the
+// database's numeric(13,3).
+var (
+	ResellerBillingGrossRevenueMin = NewDecimalFromInt(0)
+	ResellerBillingGrossRevenueMax = NewDecimalFromInt(10_000_000)
+)
+
+// parseBillingGrossRevenue accepts an item's gross revenue as billed. A credit
+// memo is billed with positive amounts too, and only becomes negative when
+// recorded. Amounts have at most the minor units of the billing's currency.
+func parseBillingGrossRevenue(code CurrencyCode) func(Decimal) (Decimal, error) {
+	return func(v Decimal) (Decimal, error) {
+		errs := &FieldParseError{}
+		if err := ValidateDecimalInclusiveRange(v, ResellerBillingGrossRevenueMin, ResellerBillingGrossRevenueMax); err != nil {
+			errs.Add(err.Error())
+		}
+		if err := ValidateDecimalPlaces(v, 0, code.MinorUnits()); err != nil {
+			errs.Add(err.Error())
+		}
+		if err := errs.NilOrError(); err != nil {
+			return Decimal{}, err
+		}
+		return v, nil
+	}
+}
+
+type ResellerBillingItem struct {
+	Entity
+	ProductCode          ProductCode // Example of foreign key not being a UUID because domain
+	GrossRevenue         Money
+	CalculatedNetRevenue Money
+}
+
+func NewResellerBillingItem(id ResellerBillingItemID, productCode ProductCode, grossRevenue Money, createdAt time.Time) ResellerBillingItem {
+	return ResellerBillingItem{
+		ID:           id.V(),
+		CreatedAt:    createdAt,
+		ProductCode:  productCode,
+		GrossRevenue: grossRevenue,
+	}
+}
 
 type ResellerBilling struct {
 	Entity
 	DocumentNumber       DocumentNumber
 	BookedAt             Date
-	CurrencyCode         string
+	CurrencyCode         CurrencyCode
 	ResellerBillingKind  ResellerBillingKind
 	CalculatedNetRevenue Money
 	ResellerBillingItems []ResellerBillingItem
 }
 
+// NewResellerBilling negates the gross revenue of a credit memo's items, so
+// that a credit memo subtracts from net revenue. Net revenue is left for
+// NetRevenueCalculator.
+func NewResellerBilling(id ResellerBillingID, documentNumber DocumentNumber, bookedAt Date, kind ResellerBillingKind, currencyCode CurrencyCode, items []ResellerBillingItem, createdAt time.Time) ResellerBilling {
+	if kind == ResellerBillingKindCreditMemo {
+		for i := range items {
+			items[i].GrossRevenue.Amount = items[i].GrossRevenue.Amount.Neg()
+		}
+	}
+	return ResellerBilling{
+		ID:                   id.V(),
+		CreatedAt:            createdAt,
+		DocumentNumber:       documentNumber,
+		BookedAt:             bookedAt,
+		CurrencyCode:         currencyCode,
+		ResellerBillingKind:  kind,
+		ResellerBillingItems: items,
+	}
+}
+
 type Reseller struct {
 	AggregateRoot
-	CountryCode                    string
-	CurrencyCode                   string
-	EnrolledAt                     Date
+	ExternalID                     ResellerExternalID
+	ClusterID                      *uuid.UUID
+	CountryCode                    CountryCode
+	CurrencyCode                   CurrencyCode
+	EnrolledAt                     ResellerEnrolledAt
 	ResellerRole                   ResellerRole
 	CalculatedNetRevenueYearToDate *Money
 	CalculatedNetRevenueLastYear   *Money
-	ResellerBillings               []ResellerBilling
+	// FiscalYear is the year of year-to-date net revenue.
+	FiscalYear       int
+	ResellerBillings []ResellerBilling
+}
+
+// NewReseller enrolls a reseller as an orphan. It takes joining a cluster to
+// become a head or a member.
+func NewReseller(id ResellerID, externalID ResellerExternalID, countryCode CountryCode, currencyCode CurrencyCode, enrolledAt ResellerEnrolledAt, createdAt time.Time) Reseller {
+	r := Reseller{
+		ID:           id.V(),
+		CreatedAt:    createdAt,
+		ExternalID:   externalID,
+		CountryCode:  countryCode,
+		CurrencyCode: currencyCode,
+		EnrolledAt:   enrolledAt,
+		ResellerRole: ResellerRoleOrphan,
+		FiscalYear:   createdAt.Year(),
+	}
+
+	r.AddDomainEvent(ResellerEnrolledEvent{
+		OccurredAt:   createdAt,
+		ID:           id.V(),
+		ExternalID:   externalID.V(),
+		CountryCode:  countryCode.V(),
+		CurrencyCode: currencyCode.V(),
+		Role:         r.ResellerRole,
+		EnrolledAt:   enrolledAt.V(),
+		FiscalYear:   r.FiscalYear,
+	})
+	return r
+}
+
+func (r *Reseller) Equal(other *Reseller) bool {
+	return EntityEqual(r, other)
+}
+
+// JoinCluster is the reseller's reaction to a cluster adding it as a member.
+func (r *Reseller) JoinCluster(clusterID uuid.UUID, role ResellerRole, updatedAt time.Time) error {
+	if r.ClusterID != nil {
+		return NewDomainError(
+			ResellerExpectedNoCluster,
+			fmt.Sprintf("join cluster requires reseller not in cluster %s", r.ClusterID))
+	}
+
+	r.ClusterID = &clusterID
+	r.ResellerRole = role
+	r.UpdatedAt = &updatedAt
+	r.AddDomainEvent(ResellerJoinedClusterEvent{
+		OccurredAt: updatedAt,
+		ResellerID: r.ID,
+		ClusterID:  clusterID,
+		Role:       role,
+	})
+	return nil
+}
+
+// LeaveCluster is the reseller's reaction to a cluster removing it as a
+// member. Outside a cluster, the reseller is an orphan.
+func (r *Reseller) LeaveCluster(clusterID uuid.UUID, updatedAt time.Time) error {
+	if r.ClusterID == nil || *r.ClusterID != clusterID {
+		return NewDomainError(
+			ResellerExpectedCluster,
+			fmt.Sprintf("leave cluster requires reseller in cluster %s", clusterID))
+	}
+
+	r.ClusterID = nil
+	r.ResellerRole = ResellerRoleOrphan
+	r.UpdatedAt = &updatedAt
+	r.AddDomainEvent(ResellerLeftClusterEvent{
+		OccurredAt: updatedAt,
+		ResellerID: r.ID,
+		ClusterID:  clusterID,
+	})
+	return nil
+}
+
+// ChangeClusterRole is the reseller's reaction to the cluster changing its
+// head.
+func (r *Reseller) ChangeClusterRole(clusterID uuid.UUID, role ResellerRole, updatedAt time.Time) error {
+	if r.ClusterID == nil || *r.ClusterID != clusterID {
+		return NewDomainError(
+			ResellerExpectedCluster,
+			fmt.Sprintf("change cluster role requires reseller in cluster %s", clusterID))
+	}
+
+	r.ResellerRole = role
+	r.UpdatedAt = &updatedAt
+	r.AddDomainEvent(ResellerRoleChangedEvent{
+		OccurredAt: updatedAt,
+		ID:         r.ID,
+		Role:       role,
+	})
+	return nil
+}
+
+// ChangeCurrency changes the currency that the reseller's net revenue is
+// calculated in. Net revenue already calculated is in the previous currency,
+// so it's cleared until next calculated.
+func (r *Reseller) ChangeCurrency(currencyCode CurrencyCode, updatedAt time.Time) error {
+	if r.CurrencyCode == currencyCode {
+		return NewDomainError(
+			ExpectedDifferentCurrencyCode,
+			fmt.Sprintf("change reseller currency requires a currency different from %s", currencyCode.V()))
+	}
+
+	r.CurrencyCode = currencyCode
+	r.CalculatedNetRevenueYearToDate = nil
+	r.CalculatedNetRevenueLastYear = nil
+	r.UpdatedAt = &updatedAt
+	r.AddDomainEvent(ResellerCurrencyChangedEvent{
+		OccurredAt:   updatedAt,
+		ID:           r.ID,
+		CurrencyCode: currencyCode.V(),
+	})
+	return nil
+}
+
+// RollOver starts a fiscal year: net revenue year to date becomes last year's,
+// and year to date starts over. Rolling over more than a year leaves no net
+// revenue for last year, as the reseller wasn't billed that year.
+func (r *Reseller) RollOver(fiscalYear int, updatedAt time.Time) error {
+	if fiscalYear <= r.FiscalYear {
+		return NewDomainError(
+			ResellerExpectedLaterYear,
+			fmt.Sprintf("roll over reseller requires fiscal year %d after %d", fiscalYear, r.FiscalYear))
+	}
+
+	r.CalculatedNetRevenueLastYear = nil
+	if fiscalYear == r.FiscalYear+1 {
+		r.CalculatedNetRevenueLastYear = r.CalculatedNetRevenueYearToDate
+	}
+	r.CalculatedNetRevenueYearToDate = nil
+	r.FiscalYear = fiscalYear
+	r.UpdatedAt = &updatedAt
+	r.AddDomainEvent(ResellerRolledOverEvent{
+		OccurredAt:                   updatedAt,
+		ID:                           r.ID,
+		FiscalYear:                   fiscalYear,
+		CalculatedNetRevenueLastYear: moneyAmount(r.CalculatedNetRevenueLastYear),
+	})
+	return nil
+}
+
+// RecordBilling adds a billing whose net revenue has been calculated. Net
+// revenue is what the billing adds to the reseller's net revenue, converted
+// into the reseller's currency. Billings booked this year add to year to date,
+// and billings booked last year, and recorded late, add to last year. Billings
+// booked before last year would count towards no tier, so they're rejected.
+// Net revenue cleared by a change of currency starts over from zero.
+//
+// A reseller not yet rolled over into this year is rolled over first, so that
+// a billing recorded before the year-end rollover doesn't add to last year's
+// year to date.
+func (r *Reseller) RecordBilling(billing ResellerBilling, netRevenue Money, recordedAt time.Time) error {
+	Assert(netRevenue.Code == r.CurrencyCode.V(), "net revenue in %s, but reseller in %s", netRevenue.Code, r.CurrencyCode.V())
+	today := DateFromTime(recordedAt)
+	if billing.BookedAt.After(today) {
+		return NewDomainError(
+			ResellerBillingExpectedPast,
+			fmt.Sprintf("record billing requires booked at %s be no later than today %s", billing.BookedAt, today))
+	}
+	if billing.BookedAt.Year() < today.Year()-1 {
+		return NewDomainError(
+			ResellerBillingExpectedRecent,
+			fmt.Sprintf("record billing requires booked at %s be in %d or later", billing.BookedAt, today.Year()-1))
+	}
+	if r.FiscalYear < today.Year() {
+		if err := r.RollOver(today.Year(), recordedAt); err != nil {
+			return err
+		}
+	}
+
+	r.ResellerBillings = append(r.ResellerBillings, billing)
+	r.UpdatedAt = &recordedAt
+	r.AddDomainEvent(ResellerBillingRecordedEvent{
+		OccurredAt:           recordedAt,
+		ResellerID:           r.ID,
+		BillingID:            billing.ID,
+		DocumentNumber:       billing.DocumentNumber.V(),
+		BookedAt:             billing.BookedAt,
+		Kind:                 billing.ResellerBillingKind,
+		CurrencyCode:         billing.CurrencyCode.V(),
+		CalculatedNetRevenue: billing.CalculatedNetRevenue.Amount,
+	})
+	for _, item := range billing.ResellerBillingItems {
+		r.AddDomainEvent(ResellerBillingItemRecordedEvent{
+			OccurredAt:           recordedAt,
+			BillingID:            billing.ID,
+			BillingItemID:        item.ID,
+			ProductCode:          item.ProductCode.V(),
+			CurrencyCode:         item.GrossRevenue.Code,
+			GrossRevenue:         item.GrossRevenue.Amount,
+			CalculatedNetRevenue: item.CalculatedNetRevenue.Amount,
+		})
+	}
+
+	if billing.BookedAt.Year() == today.Year() {
+		r.CalculatedNetRevenueYearToDate = addNetRevenue(r.CalculatedNetRevenueYearToDate, netRevenue)
+	} else {
+		r.CalculatedNetRevenueLastYear = addNetRevenue(r.CalculatedNetRevenueLastYear, netRevenue)
+	}
+	r.AddDomainEvent(ResellerNetRevenueChangedEvent{
+		OccurredAt:                     recordedAt,
+		ID:                             r.ID,
+		CalculatedNetRevenueYearToDate: moneyAmount(r.CalculatedNetRevenueYearToDate),
+		CalculatedNetRevenueLastYear:   moneyAmount(r.CalculatedNetRevenueLastYear),
+	})
+	return nil
+}
+
+func addNetRevenue(total *Money, amount Money) *Money {
+	sum := amount
+	if total != nil {
+		sum.Amount = total.Amount.Add(amount.Amount)
+	}
+	return &sum
+}
+
+func moneyAmount(m *Money) *Decimal {
+	if m == nil {
+		return nil
+	}
+	amount := m.Amount
+	return &amount
+}
+
+// Unenroll requires the reseller to have left its cluster first, or the
+// cluster would be left with a missing head or member. Billings are kept for
+// reporting but no longer refer to the reseller.
+func (r *Reseller) Unenroll(removeAt time.Time) error {
+	if r.ResellerRole != ResellerRoleOrphan {
+		return NewDomainError(
+			ExpectedOrphanResellerRole,
+			fmt.Sprintf("unenroll reseller requires role %s, but was %s", ResellerRoleOrphan, r.ResellerRole))
+	}
+
+	r.AddDomainEvent(ResellerUnenrolledEvent{
+		OccurredAt: removeAt,
+		ID:         r.ID,
+	})
+	return nil
+}
+
+// Application
+
+type EnrollResellerCommand struct {
+	ID           uuid.UUID
+	ExternalID   uuid.UUID
+	CountryCode  string
+	CurrencyCode string
+	EnrolledAt   Date
+}
+
+type EnrollResellerHandler struct {
+	Resellers  ResellerStore
+	Currencies CurrencyStore
+	Projector  StoreProjector
+	Clock      Clock
+}
+
+func (h EnrollResellerHandler) Handle(ctx context.Context, req EnrollResellerCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseResellerID)
+	externalID := parser.Parse("ExternalID", req.ExternalID, ParseResellerExternalID)
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	currencyCode := parser.Parse("CurrencyCode", req.CurrencyCode, ParseCurrencyCode)
+	enrolledAt := parser.Parse("EnrolledAt", req.EnrolledAt, ParseResellerEnrolledAt)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	exist, err := h.Resellers.ExistByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("Reseller", "ID", id.String())
+	}
+
+	exist, err = h.Resellers.ExistByExternalID(ctx, externalID)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("Reseller", "ExternalID", externalID.String())
+	}
+
+	// Net revenue is converted into the reseller's currency, which requires
+	// the currency's exchange rates.
+	exist, err = h.Currencies.ExistByCode(ctx, currencyCode)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return NewNotFoundError("Currency", "Code", currencyCode.V())
+	}
+
+	reseller := NewReseller(id, externalID, countryCode, currencyCode, enrolledAt, h.Clock.NowUTC())
+	return h.Projector.Apply(ctx, &reseller)
+}
+
+type ChangeResellerCurrencyCommand struct {
+	ID              uuid.UUID
+	CurrencyCode    string
+	ExpectedVersion *int32
+}
+
+type ChangeResellerCurrencyHandler struct {
+	Resellers  ResellerStore
+	Currencies CurrencyStore
+	Projector  StoreProjector
+	Clock      Clock
+}
+
+func (h ChangeResellerCurrencyHandler) Handle(ctx context.Context, req ChangeResellerCurrencyCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseResellerID)
+	currencyCode := parser.Parse("CurrencyCode", req.CurrencyCode, ParseCurrencyCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	reseller, err := h.Resellers.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reseller == nil {
+		return NewNotFoundError("Reseller", "ID", id.String())
+	}
+	if err := reseller.CheckVersion("Reseller", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	exist, err := h.Currencies.ExistByCode(ctx, currencyCode)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return NewNotFoundError("Currency", "Code", currencyCode.V())
+	}
+
+	if err := reseller.ChangeCurrency(currencyCode, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, reseller)
+}
+
+type UnenrollResellerCommand struct {
+	ID              uuid.UUID
+	ExpectedVersion *int32
+}
+
+type UnenrollResellerHandler struct {
+	Resellers ResellerStore
+	Projector StoreProjector
+	Clock     Clock
+}
+
+func (h UnenrollResellerHandler) Handle(ctx context.Context, req UnenrollResellerCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseResellerID)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	reseller, err := h.Resellers.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reseller == nil {
+		return NewNotFoundError("Reseller", "ID", id.String())
+	}
+	if err := reseller.CheckVersion("Reseller", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	if err := reseller.Unenroll(h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, reseller)
+}
+
+type RecordBillingItemInput struct {
+	ID           uuid.UUID
+	ProductCode  string
+	GrossRevenue Decimal
+}
+
+type RecordBillingCommand struct {
+	ID                 uuid.UUID
+	ResellerExternalID uuid.UUID
+	DocumentNumber     string
+	BookedAt           Date
+	Kind               string
+	CurrencyCode       string
+	Items              []RecordBillingItemInput
+}
+
+type RecordBillingHandler struct {
+	Resellers  ResellerStore
+	Clusters   ClusterStore
+	Currencies CurrencyStore
+	NetRevenue NetRevenueCalculator
+	Projector  StoreProjector
+	Clock      Clock
+}
+
+// Handle records a billing from the system of record for billings, which
+// refers to resellers by external ID. A cluster member's billing must be
+// booked in the cluster's fiscal year or later.
+func (h RecordBillingHandler) Handle(ctx context.Context, req RecordBillingCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseResellerBillingID)
+	resellerExternalID := parser.Parse("ResellerExternalID", req.ResellerExternalID, ParseResellerExternalID)
+	documentNumber := parser.Parse("DocumentNumber", req.DocumentNumber, ParseDocumentNumber)
+	bookedAt := parser.Parse("BookedAt", req.BookedAt, parseBillingBookedAt)
+	kind := parser.Parse("Kind", req.Kind, ParseResellerBillingKind)
+	currencyCode := parser.Parse("CurrencyCode", req.CurrencyCode, ParseCurrencyCode)
+	parser.Parse("Items", len(req.Items), parseBillingItemCount)
+	now := h.Clock.NowUTC()
+	items := make([]ResellerBillingItem, len(req.Items))
+	for i, item := range req.Items {
+		field := fmt.Sprintf("Items[%d]", i)
+		itemID := parser.Parse(field+".ID", item.ID, ParseResellerBillingItemID)
+		productCode := parser.Parse(field+".ProductCode", item.ProductCode, ParseProductCode)
+		grossRevenue := parser.Parse(field+".GrossRevenue", item.GrossRevenue, parseBillingGrossRevenue(currencyCode))
+		items[i] = NewResellerBillingItem(itemID, productCode, NewMoney(grossRevenue, currencyCode), now)
+	}
+	if parser.HasErrors() {
+		return parser
+	}
+
+	exist, err := h.Resellers.ExistBillingByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("ResellerBilling", "ID", id.String())
+	}
+
+	exist, err = h.Resellers.ExistBillingByDocumentNumber(ctx, documentNumber)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("ResellerBilling", "DocumentNumber", documentNumber.V())
+	}
+
+	reseller, err := h.Resellers.GetByExternalID(ctx, resellerExternalID)
+	if err != nil {
+		return err
+	}
+	if reseller == nil {
+		return NewNotFoundError("Reseller", "ExternalID", resellerExternalID.String())
+	}
+	if reseller.ClusterID != nil {
+		clusterID := MustParseClusterID(*reseller.ClusterID)
+		cluster, err := h.Clusters.GetByID(ctx, clusterID)
+		if err != nil {
+			return err
+		}
+		if cluster == nil {
+			return NewNotFoundError("Cluster", "ID", clusterID.String())
+		}
+		if err := cluster.CheckBillingBookedAt(bookedAt); err != nil {
+			return err
+		}
+	}
+
+	billing := NewResellerBilling(id, documentNumber, bookedAt, kind, currencyCode, items, now)
+	if err := h.NetRevenue.Calculate(ctx, &billing); err != nil {
+		return err
+	}
+
+	// Converting at the exchange rates in effect when the billing was booked
+	// means recording a billing late doesn't change its net revenue.
+	netRevenue, err := newCurrencyConverter(h.Currencies).convert(ctx, billing.CalculatedNetRevenue, reseller.CurrencyCode, bookedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := reseller.RecordBilling(billing, netRevenue, now); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, reseller)
+}
+
+type ResellerResponse struct {
+	ID                             uuid.UUID    `json:"id"`
+	Version                        int32        `json:"-"`
+	ExternalID                     uuid.UUID    `json:"external_id"`
+	ClusterID                      *uuid.UUID   `json:"cluster_id"`
+	CountryCode                    string       `json:"country_code"`
+	CurrencyCode                   string       `json:"currency_code"`
+	Role                           ResellerRole `json:"role"`
+	EnrolledAt                     Date         `json:"enrolled_at"`
+	FiscalYear                     int          `json:"fiscal_year"`
+	CalculatedNetRevenueYearToDate *Money       `json:"calculated_net_revenue_year_to_date"`
+	CalculatedNetRevenueLastYear   *Money       `json:"calculated_net_revenue_last_year"`
+	CreatedAt                      time.Time    `json:"created_at"`
+	UpdatedAt                      *time.Time   `json:"updated_at"`
+}
+
+func newResellerResponse(reseller *Reseller) *ResellerResponse {
+	return &ResellerResponse{
+		ID:                             reseller.ID,
+		Version:                        reseller.Version,
+		ExternalID:                     reseller.ExternalID.V(),
+		ClusterID:                      reseller.ClusterID,
+		CountryCode:                    reseller.CountryCode.V(),
+		CurrencyCode:                   reseller.CurrencyCode.V(),
+		Role:                           reseller.ResellerRole,
+		EnrolledAt:                     reseller.EnrolledAt.V(),
+		FiscalYear:                     reseller.FiscalYear,
+		CalculatedNetRevenueYearToDate: reseller.CalculatedNetRevenueYearToDate,
+		CalculatedNetRevenueLastYear:   reseller.CalculatedNetRevenueLastYear,
+		CreatedAt:                      reseller.CreatedAt,
+		UpdatedAt:                      reseller.UpdatedAt,
+	}
+}
+
+type GetResellerQuery struct {
+	ID uuid.UUID
+}
+
+type GetResellerHandler struct {
+	Resellers ResellerStore
+}
+
+func (h GetResellerHandler) Handle(ctx context.Context, req GetResellerQuery) (*ResellerResponse, error) {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseResellerID)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	reseller, err := h.Resellers.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reseller == nil {
+		return nil, NewNotFoundError("Reseller", "ID", id.String())
+	}
+	return newResellerResponse(reseller), nil
+}
+
+type GetResellerByExternalIDQuery struct {
+	ExternalID uuid.UUID
+}
+
+type GetResellerByExternalIDHandler struct {
+	Resellers ResellerStore
+}
+
+func (h GetResellerByExternalIDHandler) Handle(ctx context.Context, req GetResellerByExternalIDQuery) (*ResellerResponse, error) {
+	parser := &RequestParseCollector{}
+	externalID := parser.Parse("ExternalID", req.ExternalID, ParseResellerExternalID)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	reseller, err := h.Resellers.GetByExternalID(ctx, externalID)
+	if err != nil {
+		return nil, err
+	}
+	if reseller == nil {
+		return nil, NewNotFoundError("Reseller", "ExternalID", externalID.String())
+	}
+	return newResellerResponse(reseller), nil
 }
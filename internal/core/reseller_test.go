@@ -0,0 +1,220 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReseller(at time.Time) Reseller {
+	r := NewReseller(
+		MustParseResellerID(uuid.New()),
+		MustParseResellerExternalID(uuid.New()),
+		MustParseCountryCode("DK"),
+		MustParseCurrencyCode("DKK"),
+		MustParseResellerEnrolledAt(NewDate(2026, 1, 1)),
+		at)
+	r.ClearDomainEvents()
+	return r
+}
+
+func TestParseResellerRole(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		invalid bool
+	}{
+		"orphan":     {"Orphan", false},
+		"head":       {"Head", false},
+		"member":     {"Member", false},
+		"lower case": {"head", true},
+		"empty":      {"", true},
+		"unknown":    {"Leader", true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseResellerRole(tt.value)
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestResellerRoleLifecycle(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestReseller(at)
+	assert.Equal(t, ResellerRoleOrphan, r.ResellerRole)
+
+	var e *DomainError
+	clusterID := uuid.New()
+	require.NoError(t, r.JoinCluster(clusterID, ResellerRoleHead, at))
+	require.ErrorAs(t, r.Unenroll(at), &e)
+	assert.Equal(t, ExpectedOrphanResellerRole, e.Code)
+
+	require.NoError(t, r.LeaveCluster(clusterID, at))
+	require.NoError(t, r.Unenroll(at))
+	assert.IsType(t, ResellerUnenrolledEvent{}, r.DomainEvents[len(r.DomainEvents)-1])
+}
+
+func TestResellerChangeCurrency(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestReseller(at)
+	ytd := NewMoney(MustParseDecimal("100"), r.CurrencyCode)
+	r.CalculatedNetRevenueYearToDate = &ytd
+
+	var e *DomainError
+	require.ErrorAs(t, r.ChangeCurrency(MustParseCurrencyCode("DKK"), at), &e)
+	assert.Equal(t, ExpectedDifferentCurrencyCode, e.Code)
+
+	require.NoError(t, r.ChangeCurrency(MustParseCurrencyCode("EUR"), at))
+	assert.Equal(t, "EUR", r.CurrencyCode.V())
+	assert.Nil(t, r.CalculatedNetRevenueYearToDate)
+	require.Len(t, r.DomainEvents, 1)
+	assert.Equal(t, "EUR", r.DomainEvents[0].(ResellerCurrencyChangedEvent).CurrencyCode)
+}
+
+func newTestResellerBilling(kind ResellerBillingKind, bookedAt Date, gross string) ResellerBilling {
+	at := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	code := MustParseCurrencyCode("DKK")
+	item := NewResellerBillingItem(
+		MustParseResellerBillingItemID(uuid.New()),
+		MustParseProductCode("HW"),
+		NewMoney(MustParseDecimal(gross), code),
+		at)
+	return NewResellerBilling(
+		MustParseResellerBillingID(uuid.New()),
+		MustParseDocumentNumber("INV-1"),
+		bookedAt,
+		kind,
+		code,
+		[]ResellerBillingItem{item},
+		at)
+}
+
+func TestNewResellerBillingCreditMemo(t *testing.T) {
+	invoice := newTestResellerBilling(ResellerBillingKindInvoice, NewDate(2026, 6, 1), "100.50")
+	assert.Equal(t, MustParseDecimal("100.50"), invoice.ResellerBillingItems[0].GrossRevenue.Amount)
+
+	memo := newTestResellerBilling(ResellerBillingKindCreditMemo, NewDate(2026, 6, 1), "100.50")
+	assert.Equal(t, MustParseDecimal("-100.50"), memo.ResellerBillingItems[0].GrossRevenue.Amount)
+}
+
+func TestResellerRecordBilling(t *testing.T) {
+	tests := map[string]struct {
+		bookedAt         Date
+		ytd              *string
+		expectedYTD      *string
+		expectedLastYear *string
+		expectedCode     int
+	}{
+		"this year":          {NewDate(2026, 1, 1), nil, ptr("12.5"), nil, 0},
+		"this year added to": {NewDate(2026, 6, 1), ptr("100"), ptr("112.5"), nil, 0},
+		"last year":          {NewDate(2025, 12, 31), ptr("100"), ptr("100"), ptr("12.5"), 0},
+		"before last year":   {NewDate(2024, 12, 31), nil, nil, nil, ResellerBillingExpectedRecent},
+		"future":             {NewDate(2026, 6, 2), nil, nil, nil, ResellerBillingExpectedPast},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			at := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+			r := newTestReseller(at)
+			if tt.ytd != nil {
+				ytd := NewMoney(MustParseDecimal(*tt.ytd), r.CurrencyCode)
+				r.CalculatedNetRevenueYearToDate = &ytd
+			}
+			billing := newTestResellerBilling(ResellerBillingKindInvoice, tt.bookedAt, "25")
+			net := NewMoney(MustParseDecimal("12.5"), r.CurrencyCode)
+
+			err := r.RecordBilling(billing, net, at)
+
+			if tt.expectedCode != 0 {
+				var e *DomainError
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, tt.expectedCode, e.Code)
+				assert.Empty(t, r.ResellerBillings)
+				assert.Empty(t, r.DomainEvents)
+				return
+			}
+			require.NoError(t, err)
+			assertMoney(t, tt.expectedYTD, r.CalculatedNetRevenueYearToDate)
+			assertMoney(t, tt.expectedLastYear, r.CalculatedNetRevenueLastYear)
+			assert.IsType(t, ResellerBillingRecordedEvent{}, r.DomainEvents[0])
+			assert.IsType(t, ResellerBillingItemRecordedEvent{}, r.DomainEvents[1])
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func assertMoney(t *testing.T, expected *string, actual *Money) {
+	if expected == nil {
+		assert.Nil(t, actual)
+		return
+	}
+	require.NotNil(t, actual)
+	assert.Equal(t, MustParseDecimal(*expected), actual.Amount)
+}
+
+func TestResellerRollOver(t *testing.T) {
+	tests := map[string]struct {
+		fiscalYear       int
+		ytd              *string
+		expectedLastYear *string
+		invalid          bool
+	}{
+		"next year":          {2027, ptr("100"), ptr("100"), false},
+		"next year unbilled": {2027, nil, nil, false},
+		"after next year":    {2028, ptr("100"), nil, false},
+		"same year":          {2026, ptr("100"), nil, true},
+		"year before":        {2025, ptr("100"), nil, true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			at := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+			r := newTestReseller(at)
+			if tt.ytd != nil {
+				ytd := NewMoney(MustParseDecimal(*tt.ytd), r.CurrencyCode)
+				r.CalculatedNetRevenueYearToDate = &ytd
+			}
+
+			err := r.RollOver(tt.fiscalYear, at)
+
+			if tt.invalid {
+				var e *DomainError
+				require.ErrorAs(t, err, &e)
+				assert.Equal(t, ResellerExpectedLaterYear, e.Code)
+				assert.Equal(t, 2026, r.FiscalYear)
+				assert.Empty(t, r.DomainEvents)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.fiscalYear, r.FiscalYear)
+			assert.Nil(t, r.CalculatedNetRevenueYearToDate)
+			assertMoney(t, tt.expectedLastYear, r.CalculatedNetRevenueLastYear)
+			assert.IsType(t, ResellerRolledOverEvent{}, r.DomainEvents[0])
+		})
+	}
+}
+
+func TestResellerRecordBillingRollsOver(t *testing.T) {
+	r := newTestReseller(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	ytd := NewMoney(MustParseDecimal("100"), r.CurrencyCode)
+	r.CalculatedNetRevenueYearToDate = &ytd
+	billing := newTestResellerBilling(ResellerBillingKindInvoice, NewDate(2026, 1, 2), "25")
+	net := NewMoney(MustParseDecimal("12.5"), r.CurrencyCode)
+
+	// Recorded before the year-end rollover, the billing doesn't add to
+	// year to date of 2025.
+	require.NoError(t, r.RecordBilling(billing, net, time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 2026, r.FiscalYear)
+	assertMoney(t, ptr("12.5"), r.CalculatedNetRevenueYearToDate)
+	assertMoney(t, ptr("100"), r.CalculatedNetRevenueLastYear)
+	assert.IsType(t, ResellerRolledOverEvent{}, r.DomainEvents[0])
+}
@@ -0,0 +1,732 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"uuid"
+)
+
+// Domain
+
+type RevenueGroupStore interface {
+	ExistByID(context.Context, RevenueGroupID) (bool, error)
+	ExistByCountryCode(context.Context, CountryCode) (bool, error)
+	GetByID(context.Context, RevenueGroupID) (*RevenueGroup, error)
+	GetByCountryCode(context.Context, CountryCode) (*RevenueGroup, error)
+}
+
+type RevenueGroupCreatedEvent struct {
+	domainEventCommon
+	ID           uuid.UUID `json:"id"`
+	CountryCode  string    `json:"country_code"`
+	CurrencyCode string    `json:"currency_code"`
+}
+
+type RevenueGroupLimitAddedEvent struct {
+	domainEventCommon
+	RevenueGroupID      uuid.UUID `json:"revenue_group_id"`
+	RevenueGroupLimitID uuid.UUID `json:"revenue_group_limit_id"`
+	CurrencyCode        string    `json:"currency_code"`
+	Authorized          Decimal   `json:"authorized"`
+	Advanced            Decimal   `json:"advanced"`
+	Premier             Decimal   `json:"premier"`
+	From                Date      `json:"from"`
+}
+
+type RevenueGroupLimitUpdatedEvent struct {
+	domainEventCommon
+	RevenueGroupID      uuid.UUID `json:"revenue_group_id"`
+	RevenueGroupLimitID uuid.UUID `json:"revenue_group_limit_id"`
+	Authorized          Decimal   `json:"authorized"`
+	Advanced            Decimal   `json:"advanced"`
+	Premier             Decimal   `json:"premier"`
+	From                Date      `json:"from"`
+}
+
+type RevenueGroupLimitRemovedEvent struct {
+	domainEventCommon
+	RevenueGroupID      uuid.UUID `json:"revenue_group_id"`
+	RevenueGroupLimitID uuid.UUID `json:"revenue_group_limit_id"`
+}
+
+// NoRevenueGroupLimitError signals that no limits of a revenue group are in
+// effect on a date, i.e., the revenue group has no limits or every limit is
+// from a later date.
+type NoRevenueGroupLimitError struct {
+	CountryCode string
+	On          Date
+}
+
+func NewNoRevenueGroupLimitError(countryCode CountryCode, on Date) *NoRevenueGroupLimitError {
+	return &NoRevenueGroupLimitError{CountryCode: countryCode.V(), On: on}
+}
+
+func (e *NoRevenueGroupLimitError) Error() string {
+	return fmt.Sprintf("no limits for revenue group %s in effect on %s", e.CountryCode, e.On)
+}
+
+const (
+	RevenueGroupExpectedFutureFromForAdd          = 1400
+	RevenueGroupExpectedFutureFromForUpdate       = 1401
+	RevenueGroupExpectedFutureFromForLimitRemoval = 1402
+	RevenueGroupExpectedDifferentLimit            = 1403
+)
+
+// RevenueGroupID
+
+type RevenueGroupID struct {
+	v uuid.UUID
+}
+
+func (r RevenueGroupID) V() uuid.UUID   { return r.v }
+func (r RevenueGroupID) String() string { return r.v.String() }
+
+func ParseRevenueGroupID(v uuid.UUID) (RevenueGroupID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return RevenueGroupID{}, err
+	}
+	return RevenueGroupID{v}, nil
+}
+
+func MustParseRevenueGroupID(v uuid.UUID) RevenueGroupID {
+	v1, err := ParseRevenueGroupID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// RevenueGroupLimitID
+
+type RevenueGroupLimitID struct {
+	v uuid.UUID
+}
+
+func (r RevenueGroupLimitID) V() uuid.UUID   { return r.v }
+func (r RevenueGroupLimitID) String() string { return r.v.String() }
+
+func ParseRevenueGroupLimitID(v uuid.UUID) (RevenueGroupLimitID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return RevenueGroupLimitID{}, err
+	}
+	return RevenueGroupLimitID{v}, nil
+}
+
+func MustParseRevenueGroupLimitID(v uuid.UUID) RevenueGroupLimitID {
+	v1, err := ParseRevenueGroupLimitID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// RevenueLimits
+
+// RevenueLimitMax keeps a limit within the database's numeric(16,3), and is
+// high enough for limits in low-value currencies like JPY.
+var (
+	RevenueLimitMin = NewDecimalFromInt(0)
+	RevenueLimitMax = NewDecimalFromInt(1_000_000_000_000)
+)
+
+// RevenueLimits are the net revenue, in the revenue group's currency, from
+// which a reseller reaches a tier. Below Authorized, a reseller has no tier.
+type RevenueLimits struct {
+	authorized Decimal
+	advanced   Decimal
+	premier    Decimal
+}
+
+func (r RevenueLimits) Authorized() Decimal { return r.authorized }
+func (r RevenueLimits) Advanced() Decimal   { return r.advanced }
+func (r RevenueLimits) Premier() Decimal    { return r.premier }
+
+// ParseRevenueLimits accepts limits with at most the minor units of the
+// revenue group's currency.
+func ParseRevenueLimits(code CurrencyCode, authorized, advanced, premier Decimal) (RevenueLimits, error) {
+	// A compound value type may report multiple validation errors per field.
+	errs := &FieldParseError{}
+	if err := ValidateDecimalInclusiveRange(authorized, RevenueLimitMin, RevenueLimitMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateDecimalInclusiveRange(advanced, RevenueLimitMin, RevenueLimitMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateDecimalInclusiveRange(premier, RevenueLimitMin, RevenueLimitMax); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateDecimalPlaces(authorized, 0, code.MinorUnits()); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateDecimalPlaces(advanced, 0, code.MinorUnits()); err != nil {
+		errs.Add(err.Error())
+	}
+	if err := ValidateDecimalPlaces(premier, 0, code.MinorUnits()); err != nil {
+		errs.Add(err.Error())
+	}
+	// Unlike discount percentages, equal limits would make a tier unreachable.
+	if !authorized.LessThan(advanced) {
+		message := fmt.Sprintf("Authorized %s must be less than Advanced %s", authorized, advanced)
+		errs.Add(message)
+	}
+	if !advanced.LessThan(premier) {
+		message := fmt.Sprintf("Advanced %s must be less than Premier %s", advanced, premier)
+		errs.Add(message)
+	}
+	if err := errs.NilOrError(); err != nil {
+		return RevenueLimits{}, err
+	}
+
+	return RevenueLimits{
+		authorized: authorized,
+		advanced:   advanced,
+		premier:    premier,
+	}, nil
+}
+
+func MustParseRevenueLimits(code CurrencyCode, authorized, advanced, premier Decimal) RevenueLimits {
+	v1, err := ParseRevenueLimits(code, authorized, advanced, premier)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// Tier returns the highest tier whose limit net revenue reaches, or nil below
+// Authorized.
+func (r RevenueLimits) Tier(netRevenue Decimal) *ResellerTier {
+	var tier ResellerTier
+	switch {
+	case !netRevenue.LessThan(r.premier):
+		tier = ResellerTierPremier
+	case !netRevenue.LessThan(r.advanced):
+		tier = ResellerTierAdvanced
+	case !netRevenue.LessThan(r.authorized):
+		tier = ResellerTierAuthorized
+	default:
+		return nil
+	}
+	return &tier
+}
+
+var (
+	RevenueGroupLimitFromMin = NewDate(2024, 1, 1)
+	RevenueGroupLimitFromMax = NewDate(2034, 12, 31)
+)
+
+type RevenueGroupLimitFrom struct {
+	v Date
+}
+
+func (f RevenueGroupLimitFrom) V() Date        { return f.v }
+func (f RevenueGroupLimitFrom) String() string { return f.v.String() }
+
+func ParseRevenueGroupLimitFrom(v Date) (RevenueGroupLimitFrom, error) {
+	if err := ValidateDateInclusiveRange(v, RevenueGroupLimitFromMin, RevenueGroupLimitFromMax); err != nil {
+		return RevenueGroupLimitFrom{}, err
+	}
+	return RevenueGroupLimitFrom{v}, nil
+}
+
+func MustParseRevenueGroupLimitFrom(v Date) RevenueGroupLimitFrom {
+	v1, err := ParseRevenueGroupLimitFrom(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+type RevenueGroupLimit struct {
+	Entity
+	Limits RevenueLimits
+	From   RevenueGroupLimitFrom
+}
+
+func NewRevenueGroupLimit(id RevenueGroupLimitID, limits RevenueLimits, from RevenueGroupLimitFrom, createdAt time.Time) RevenueGroupLimit {
+	return RevenueGroupLimit{
+		ID:        id.V(),
+		CreatedAt: createdAt,
+		UpdatedAt: nil,
+		Limits:    limits,
+		From:      from,
+	}
+}
+
+func (l *RevenueGroupLimit) Update(limits RevenueLimits, from RevenueGroupLimitFrom, updatedAt time.Time) error {
+	today := DateFromTime(updatedAt)
+	if !from.V().After(today) {
+		return NewDomainError(
+			RevenueGroupExpectedFutureFromForUpdate,
+			fmt.Sprintf("update revenue group limit requires from %s be after today %s", from, today))
+	}
+
+	if l.Limits == limits && l.From == from {
+		return NewDomainError(
+			RevenueGroupExpectedDifferentLimit,
+			fmt.Sprintf("update revenue group limit requires different limits and/or a from different from %s", from))
+	}
+
+	l.Limits = limits
+	l.From = from
+	l.UpdatedAt = &updatedAt
+	return nil
+}
+
+func (l *RevenueGroupLimit) Equal(other *RevenueGroupLimit) bool {
+	return EntityEqual(l, other)
+}
+
+// RevenueGroup holds the tier limits for resellers of a country. Limits are
+// in the revenue group's currency as resellers of a country may be billed in
+// different currencies.
+type RevenueGroup struct {
+	AggregateRoot
+	CountryCode        CountryCode
+	CurrencyCode       CurrencyCode
+	RevenueGroupLimits []*RevenueGroupLimit
+}
+
+func NewRevenueGroup(id RevenueGroupID, countryCode CountryCode, currencyCode CurrencyCode, createdAt time.Time) RevenueGroup {
+	rg := RevenueGroup{
+		ID:                 id.V(),
+		CreatedAt:          createdAt,
+		CountryCode:        countryCode,
+		CurrencyCode:       currencyCode,
+		RevenueGroupLimits: []*RevenueGroupLimit{},
+	}
+
+	rg.AddDomainEvent(RevenueGroupCreatedEvent{
+		OccurredAt:   createdAt,
+		ID:           id.V(),
+		CountryCode:  countryCode.V(),
+		CurrencyCode: currencyCode.V(),
+	})
+	return rg
+}
+
+func (rg *RevenueGroup) Equal(other *RevenueGroup) bool {
+	return EntityEqual(rg, other)
+}
+
+func (rg *RevenueGroup) AddLimit(limit RevenueGroupLimit, createdAt time.Time) error {
+	for _, l := range rg.RevenueGroupLimits {
+		if l.ID == limit.ID {
+			return NewConflictError("RevenueGroupLimit", "ID", limit.ID.String())
+		}
+		if l.From == limit.From {
+			return NewConflictError("RevenueGroupLimit", "From", limit.From.String())
+		}
+	}
+
+	today := DateFromTime(createdAt)
+	if !limit.From.V().After(today) {
+		return NewDomainError(
+			RevenueGroupExpectedFutureFromForAdd,
+			fmt.Sprintf("add revenue group limit requires from %s be after today %s", limit.From, today))
+	}
+
+	rg.RevenueGroupLimits = append(rg.RevenueGroupLimits, &limit)
+	rg.AddDomainEvent(RevenueGroupLimitAddedEvent{
+		OccurredAt:          createdAt,
+		RevenueGroupID:      rg.ID,
+		RevenueGroupLimitID: limit.ID,
+		CurrencyCode:        rg.CurrencyCode.V(),
+		Authorized:          limit.Limits.Authorized(),
+		Advanced:            limit.Limits.Advanced(),
+		Premier:             limit.Limits.Premier(),
+		From:                limit.From.V(),
+	})
+	return nil
+}
+
+func (rg *RevenueGroup) UpdateLimit(limitID RevenueGroupLimitID, limits RevenueLimits, from RevenueGroupLimitFrom, updatedAt time.Time) error {
+	var (
+		limitByID   *RevenueGroupLimit
+		limitByFrom *RevenueGroupLimit
+	)
+	for _, l := range rg.RevenueGroupLimits {
+		if l.ID == limitID.V() {
+			limitByID = l
+		}
+		if l.From == from {
+			limitByFrom = l
+		}
+	}
+	if limitByID == nil {
+		return NewNotFoundError("RevenueGroupLimit", "ID", limitID.String())
+	}
+	if limitByFrom != nil && limitByFrom.ID != limitID.V() {
+		return NewConflictError("RevenueGroupLimit", "From", from.String())
+	}
+
+	if err := limitByID.Update(limits, from, updatedAt); err != nil {
+		return fmt.Errorf("revenue group update limit: %w", err)
+	}
+
+	rg.AddDomainEvent(RevenueGroupLimitUpdatedEvent{
+		OccurredAt:          updatedAt,
+		RevenueGroupID:      rg.ID,
+		RevenueGroupLimitID: limitByID.ID,
+		Authorized:          limitByID.Limits.Authorized(),
+		Advanced:            limitByID.Limits.Advanced(),
+		Premier:             limitByID.Limits.Premier(),
+		From:                limitByID.From.V(),
+	})
+	return nil
+}
+
+func (rg *RevenueGroup) RemoveLimit(limitID RevenueGroupLimitID, updatedAt time.Time) error {
+	var limit *RevenueGroupLimit
+	for _, l := range rg.RevenueGroupLimits {
+		if l.ID == limitID.V() {
+			limit = l
+		}
+	}
+	if limit == nil {
+		return NewNotFoundError("RevenueGroupLimit", "ID", limitID.String())
+	}
+
+	today := DateFromTime(updatedAt)
+	if !limit.From.V().After(today) {
+		return NewDomainError(
+			RevenueGroupExpectedFutureFromForLimitRemoval,
+			fmt.Sprintf("remove revenue group limit requires from %s be after today %s", limit.From, today))
+	}
+
+	idx := slices.IndexFunc(rg.RevenueGroupLimits, limit.Equal)
+	Assert(idx != -1, "missing revenue group limit %s", limit.ID)
+
+	rg.RevenueGroupLimits = slices.Delete(rg.RevenueGroupLimits, idx, idx+1)
+	rg.AddDomainEvent(RevenueGroupLimitRemovedEvent{
+		OccurredAt:          updatedAt,
+		RevenueGroupID:      rg.ID,
+		RevenueGroupLimitID: limit.ID,
+	})
+	return nil
+}
+
+// EffectiveLimit returns the limits in effect on a date, i.e., the limits with
+// the latest from not after the date.
+func (rg *RevenueGroup) EffectiveLimit(on Date) (*RevenueGroupLimit, error) {
+	effective, ok := effectiveOn(rg.RevenueGroupLimits, func(l *RevenueGroupLimit) Date { return l.From.V() }, on)
+	if !ok {
+		return nil, NewNoRevenueGroupLimitError(rg.CountryCode, on)
+	}
+	return effective, nil
+}
+
+// Application
+
+type CreateRevenueGroupCommand struct {
+	ID           uuid.UUID
+	CountryCode  string
+	CurrencyCode string
+}
+
+type CreateRevenueGroupHandler struct {
+	RevenueGroups RevenueGroupStore
+	Currencies    CurrencyStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h CreateRevenueGroupHandler) Handle(ctx context.Context, req CreateRevenueGroupCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseRevenueGroupID)
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	currencyCode := parser.Parse("CurrencyCode", req.CurrencyCode, ParseCurrencyCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	exist, err := h.RevenueGroups.ExistByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("RevenueGroup", "ID", id.String())
+	}
+
+	exist, err = h.RevenueGroups.ExistByCountryCode(ctx, countryCode)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return NewConflictError("RevenueGroup", "CountryCode", countryCode.V())
+	}
+
+	// Net revenue is converted into the limits' currency for tiering, which
+	// requires the currency's exchange rates.
+	exist, err = h.Currencies.ExistByCode(ctx, currencyCode)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return NewNotFoundError("Currency", "Code", currencyCode.V())
+	}
+
+	revenueGroup := NewRevenueGroup(id, countryCode, currencyCode, h.Clock.NowUTC())
+	return h.Projector.Apply(ctx, &revenueGroup)
+}
+
+type RevenueLimitsInput struct {
+	Authorized Decimal
+	Advanced   Decimal
+	Premier    Decimal
+}
+
+func parseRevenueLimitsInput(code CurrencyCode) func(RevenueLimitsInput) (RevenueLimits, error) {
+	return func(rl RevenueLimitsInput) (RevenueLimits, error) {
+		return ParseRevenueLimits(code, rl.Authorized, rl.Advanced, rl.Premier)
+	}
+}
+
+type AddRevenueGroupLimitCommand struct {
+	ID              uuid.UUID
+	CountryCode     string
+	Limits          RevenueLimitsInput
+	From            Date
+	ExpectedVersion *int32
+}
+
+type AddRevenueGroupLimitHandler struct {
+	RevenueGroups RevenueGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h AddRevenueGroupLimitHandler) Handle(ctx context.Context, req AddRevenueGroupLimitCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseRevenueGroupLimitID)
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	from := parser.Parse("From", req.From, ParseRevenueGroupLimitFrom)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	revenueGroup, err := h.RevenueGroups.GetByCountryCode(ctx, countryCode)
+	if err != nil {
+		return err
+	}
+	if revenueGroup == nil {
+		return NewNotFoundError("RevenueGroup", "CountryCode", countryCode.V())
+	}
+	if err := revenueGroup.CheckVersion("RevenueGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	// Limits depend on the revenue group's currency, so they're parsed once
+	// the revenue group is known.
+	limits := parser.Parse("Limits", req.Limits, parseRevenueLimitsInput(revenueGroup.CurrencyCode))
+	if parser.HasErrors() {
+		return parser
+	}
+
+	now := h.Clock.NowUTC()
+	limit := NewRevenueGroupLimit(id, limits, from, now)
+	if err := revenueGroup.AddLimit(limit, now); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, revenueGroup)
+}
+
+type UpdateRevenueGroupLimitCommand struct {
+	ID              uuid.UUID
+	CountryCode     string
+	Limits          RevenueLimitsInput
+	From            Date
+	ExpectedVersion *int32
+}
+
+type UpdateRevenueGroupLimitHandler struct {
+	RevenueGroups RevenueGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h UpdateRevenueGroupLimitHandler) Handle(ctx context.Context, req UpdateRevenueGroupLimitCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseRevenueGroupLimitID)
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	from := parser.Parse("From", req.From, ParseRevenueGroupLimitFrom)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	revenueGroup, err := h.RevenueGroups.GetByCountryCode(ctx, countryCode)
+	if err != nil {
+		return err
+	}
+	if revenueGroup == nil {
+		return NewNotFoundError("RevenueGroup", "CountryCode", countryCode.V())
+	}
+	if err := revenueGroup.CheckVersion("RevenueGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	// Limits depend on the revenue group's currency, so they're parsed once
+	// the revenue group is known.
+	limits := parser.Parse("Limits", req.Limits, parseRevenueLimitsInput(revenueGroup.CurrencyCode))
+	if parser.HasErrors() {
+		return parser
+	}
+
+	if err := revenueGroup.UpdateLimit(id, limits, from, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, revenueGroup)
+}
+
+type RemoveRevenueGroupLimitCommand struct {
+	ID              uuid.UUID
+	CountryCode     string
+	ExpectedVersion *int32
+}
+
+type RemoveRevenueGroupLimitHandler struct {
+	RevenueGroups RevenueGroupStore
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+func (h RemoveRevenueGroupLimitHandler) Handle(ctx context.Context, req RemoveRevenueGroupLimitCommand) error {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseRevenueGroupLimitID)
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	if parser.HasErrors() {
+		return parser
+	}
+
+	revenueGroup, err := h.RevenueGroups.GetByCountryCode(ctx, countryCode)
+	if err != nil {
+		return err
+	}
+	if revenueGroup == nil {
+		return NewNotFoundError("RevenueGroup", "CountryCode", countryCode.V())
+	}
+	if err := revenueGroup.CheckVersion("RevenueGroup", req.ExpectedVersion); err != nil {
+		return err
+	}
+
+	if err := revenueGroup.RemoveLimit(id, h.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return h.Projector.Apply(ctx, revenueGroup)
+}
+
+type RevenueGroupLimitResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Authorized Money      `json:"authorized"`
+	Advanced   Money      `json:"advanced"`
+	Premier    Money      `json:"premier"`
+	From       Date       `json:"from"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+type RevenueGroupResponse struct {
+	ID           uuid.UUID                    `json:"id"`
+	Version      int32                        `json:"-"`
+	CountryCode  string                       `json:"country_code"`
+	CurrencyCode string                       `json:"currency_code"`
+	Limits       []*RevenueGroupLimitResponse `json:"limits"`
+	CreatedAt    time.Time                    `json:"created_at"`
+	UpdatedAt    *time.Time                   `json:"updated_at"`
+}
+
+func newRevenueGroupLimitResponse(l *RevenueGroupLimit, code CurrencyCode) *RevenueGroupLimitResponse {
+	return &RevenueGroupLimitResponse{
+		ID:         l.ID,
+		Authorized: NewMoney(l.Limits.Authorized(), code),
+		Advanced:   NewMoney(l.Limits.Advanced(), code),
+		Premier:    NewMoney(l.Limits.Premier(), code),
+		From:       l.From.V(),
+		CreatedAt:  l.CreatedAt,
+		UpdatedAt:  l.UpdatedAt,
+	}
+}
+
+func newRevenueGroupResponse(revenueGroup *RevenueGroup) *RevenueGroupResponse {
+	limits := make([]*RevenueGroupLimitResponse, len(revenueGroup.RevenueGroupLimits))
+	for i, l := range revenueGroup.RevenueGroupLimits {
+		limits[i] = newRevenueGroupLimitResponse(l, revenueGroup.CurrencyCode)
+	}
+
+	return &RevenueGroupResponse{
+		ID:           revenueGroup.ID,
+		Version:      revenueGroup.Version,
+		CountryCode:  revenueGroup.CountryCode.V(),
+		CurrencyCode: revenueGroup.CurrencyCode.V(),
+		Limits:       limits,
+		CreatedAt:    revenueGroup.CreatedAt,
+		UpdatedAt:    revenueGroup.UpdatedAt,
+	}
+}
+
+type GetRevenueGroupQuery struct {
+	CountryCode string
+}
+
+type GetRevenueGroupHandler struct {
+	RevenueGroups RevenueGroupStore
+}
+
+func (h GetRevenueGroupHandler) Handle(ctx context.Context, req GetRevenueGroupQuery) (*RevenueGroupResponse, error) {
+	parser := &RequestParseCollector{}
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	revenueGroup, err := h.RevenueGroups.GetByCountryCode(ctx, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	if revenueGroup == nil {
+		return nil, NewNotFoundError("RevenueGroup", "CountryCode", countryCode.V())
+	}
+	return newRevenueGroupResponse(revenueGroup), nil
+}
+
+// parseLimitEffectiveOn bounds the date asked about to the dates limits may be
+// from. Outside it no limits can be in effect or the date is a mistake.
+func parseLimitEffectiveOn(v Date) (Date, error) {
+	if err := ValidateDateInclusiveRange(v, RevenueGroupLimitFromMin, RevenueGroupLimitFromMax); err != nil {
+		return Date{}, err
+	}
+	return v, nil
+}
+
+type GetEffectiveRevenueGroupLimitQuery struct {
+	CountryCode string
+	On          Date
+}
+
+type GetEffectiveRevenueGroupLimitHandler struct {
+	RevenueGroups RevenueGroupStore
+}
+
+func (h GetEffectiveRevenueGroupLimitHandler) Handle(ctx context.Context, req GetEffectiveRevenueGroupLimitQuery) (*RevenueGroupLimitResponse, error) {
+	parser := &RequestParseCollector{}
+	countryCode := parser.Parse("CountryCode", req.CountryCode, ParseCountryCode)
+	on := parser.Parse("On", req.On, parseLimitEffectiveOn)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	revenueGroup, err := h.RevenueGroups.GetByCountryCode(ctx, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	if revenueGroup == nil {
+		return nil, NewNotFoundError("RevenueGroup", "CountryCode", countryCode.V())
+	}
+
+	limit, err := revenueGroup.EffectiveLimit(on)
+	if err != nil {
+		return nil, err
+	}
+	return newRevenueGroupLimitResponse(limit, revenueGroup.CurrencyCode), nil
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRevenueGroupWithLimits(countryCode string, limits map[Date][3]string) *RevenueGroup {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rg := NewRevenueGroup(MustParseRevenueGroupID(uuid.New()), MustParseCountryCode(countryCode), MustParseCurrencyCode("DKK"), createdAt)
+	for from, l := range limits {
+		rl := MustParseRevenueLimits(rg.CurrencyCode, MustParseDecimal(l[0]), MustParseDecimal(l[1]), MustParseDecimal(l[2]))
+		limit := NewRevenueGroupLimit(MustParseRevenueGroupLimitID(uuid.New()), rl, MustParseRevenueGroupLimitFrom(from), createdAt)
+		rg.RevenueGroupLimits = append(rg.RevenueGroupLimits, &limit)
+	}
+	rg.ClearDomainEvents()
+	return &rg
+}
+
+func TestParseRevenueLimits(t *testing.T) {
+	tests := map[string]struct {
+		code       string
+		authorized string
+		advanced   string
+		premier    string
+		invalid    bool
+	}{
+		"ascending":          {"DKK", "0", "100000", "500000.50", false},
+		"equal":              {"DKK", "100", "100", "200", true},
+		"descending":         {"DKK", "300", "200", "100", true},
+		"negative":           {"DKK", "-1", "100", "200", true},
+		"above max":          {"DKK", "1", "2", "1000000000000.01", true},
+		"too many places":    {"DKK", "1.001", "2", "3", true},
+		"advanced above max": {"DKK", "1", "1000000000001", "1000000000002", true},
+		"low-value currency": {"JPY", "10000000", "50000000", "1000000000000", false},
+		"no minor units":     {"JPY", "1.5", "2", "3", true},
+		"three minor units":  {"KWD", "1.001", "2", "3", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseRevenueLimits(MustParseCurrencyCode(tt.code), MustParseDecimal(tt.authorized), MustParseDecimal(tt.advanced), MustParseDecimal(tt.premier))
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEffectiveLimit(t *testing.T) {
+	rg := newTestRevenueGroupWithLimits("DK", map[Date][3]string{
+		NewDate(2026, 1, 1): {"100", "200", "300"},
+		NewDate(2027, 1, 1): {"150", "250", "350"},
+	})
+
+	tests := map[string]struct {
+		on       Date
+		expected string
+		invalid  bool
+	}{
+		"before first": {NewDate(2025, 12, 31), "", true},
+		"on first":     {NewDate(2026, 1, 1), "100", false},
+		"between":      {NewDate(2026, 12, 31), "100", false},
+		"after last":   {NewDate(2030, 1, 1), "150", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := rg.EffectiveLimit(tt.on)
+			if tt.invalid {
+				var noLimit *NoRevenueGroupLimitError
+				require.ErrorAs(t, err, &noLimit)
+				assert.Equal(t, "DK", noLimit.CountryCode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.expected), l.Limits.Authorized())
+		})
+	}
+}
+
+func TestRevenueGroupLimitFutureOnly(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	rg := newTestRevenueGroupWithLimits("DK", map[Date][3]string{
+		NewDate(2026, 1, 1): {"100", "200", "300"},
+	})
+	past := rg.RevenueGroupLimits[0]
+	limits := MustParseRevenueLimits(MustParseCurrencyCode("DKK"), MustParseDecimal("1"), MustParseDecimal("2"), MustParseDecimal("3"))
+	today := MustParseRevenueGroupLimitFrom(NewDate(2026, 6, 1))
+	tomorrow := MustParseRevenueGroupLimitFrom(NewDate(2026, 6, 2))
+
+	var e *DomainError
+	require.ErrorAs(t, rg.AddLimit(NewRevenueGroupLimit(MustParseRevenueGroupLimitID(uuid.New()), limits, today, now), now), &e)
+	assert.Equal(t, RevenueGroupExpectedFutureFromForAdd, e.Code)
+	require.ErrorAs(t, rg.UpdateLimit(MustParseRevenueGroupLimitID(past.ID), limits, today, now), &e)
+	assert.Equal(t, RevenueGroupExpectedFutureFromForUpdate, e.Code)
+	require.ErrorAs(t, rg.RemoveLimit(MustParseRevenueGroupLimitID(past.ID), now), &e)
+	assert.Equal(t, RevenueGroupExpectedFutureFromForLimitRemoval, e.Code)
+
+	future := NewRevenueGroupLimit(MustParseRevenueGroupLimitID(uuid.New()), limits, tomorrow, now)
+	require.NoError(t, rg.AddLimit(future, now))
+	require.ErrorAs(t, rg.UpdateLimit(MustParseRevenueGroupLimitID(future.ID), limits, tomorrow, now), &e)
+	assert.Equal(t, RevenueGroupExpectedDifferentLimit, e.Code)
+	require.NoError(t, rg.RemoveLimit(MustParseRevenueGroupLimitID(future.ID), now))
+	assert.Len(t, rg.RevenueGroupLimits, 1)
+	assert.Len(t, rg.DomainEvents, 2)
+}
+
+func TestRevenueLimitsTier(t *testing.T) {
+	limits := MustParseRevenueLimits(MustParseCurrencyCode("DKK"), MustParseDecimal("100"), MustParseDecimal("200"), MustParseDecimal("300"))
+
+	tests := map[string]struct {
+		netRevenue string
+		expected   *string
+	}{
+		"negative":         {"-1", nil},
+		"below authorized": {"99.99", nil},
+		"authorized":       {"100", ptr("Authorized")},
+		"below advanced":   {"199.99", ptr("Authorized")},
+		"advanced":         {"200", ptr("Advanced")},
+		"premier":          {"300", ptr("Premier")},
+		"above premier":    {"1000000", ptr("Premier")},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tier := limits.Tier(MustParseDecimal(tt.netRevenue))
+			if tt.expected == nil {
+				assert.Nil(t, tier)
+				return
+			}
+			require.NotNil(t, tier)
+			assert.Equal(t, MustParseResellerTier(*tt.expected), *tier)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"uuid"
+)
+
+// Domain
+
+type RolloverStore interface {
+	ExistByFiscalYear(context.Context, int) (bool, error)
+}
+
+type RolloverRecordedEvent struct {
+	domainEventCommon
+	ID         uuid.UUID `json:"id"`
+	FiscalYear int       `json:"fiscal_year"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+}
+
+// RolloverID
+
+type RolloverID struct {
+	v uuid.UUID
+}
+
+func (r RolloverID) V() uuid.UUID   { return r.v }
+func (r RolloverID) String() string { return r.v.String() }
+
+func ParseRolloverID(v uuid.UUID) (RolloverID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return RolloverID{}, err
+	}
+	return RolloverID{v}, nil
+}
+
+func MustParseRolloverID(v uuid.UUID) RolloverID {
+	v1, err := ParseRolloverID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// Rollover records that every reseller and cluster was rolled over from a
+// fiscal year into the next. Like a tiering, it's only recorded once all are
+// rolled over, and running it again rolls over the remaining ones.
+type Rollover struct {
+	AggregateRoot
+	FiscalYear int
+	Start      time.Time
+	End        time.Time
+}
+
+func NewRollover(id RolloverID, fiscalYear int, start time.Time, end time.Time) Rollover {
+	r := Rollover{
+		ID:         id.V(),
+		CreatedAt:  end,
+		FiscalYear: fiscalYear,
+		Start:      start,
+		End:        end,
+	}
+
+	r.AddDomainEvent(RolloverRecordedEvent{
+		OccurredAt: end,
+		ID:         id.V(),
+		FiscalYear: fiscalYear,
+		Start:      start,
+		End:        end,
+	})
+	return r
+}
+
+func (r *Rollover) Equal(other *Rollover) bool {
+	return EntityEqual(r, other)
+}
+
+// Application
+
+// RollOverYearCommand rolls over the fiscal year before today's, so it's run
+// on or after the first day of a year.
+type RollOverYearCommand struct {
+	ID uuid.UUID
+}
+
+type RolledOverCluster struct {
+	ClusterID                     uuid.UUID
+	CalculatedNetRevenueLastYear  *Money
+	CalculatedResellerTierMinimum *ResellerTier
+}
+
+// RolloverReport holds the outcome of the rollover by cluster. Resellers
+// rolled over when recording a billing in the new year, and clusters rolled
+// over by an earlier run, are skipped. Recorded is false when a cluster failed,
+// and the rollover must be run again.
+type RolloverReport struct {
+	FiscalYear       int
+	ResellersRolled  int
+	ResellersSkipped int
+	Clusters         []RolledOverCluster
+	ClustersSkipped  []uuid.UUID
+	ClustersFailed   []FailedCluster
+	Recorded         bool
+}
+
+type RollOverYearHandler struct {
+	Rollovers     RolloverStore
+	Resellers     ResellerStore
+	Clusters      ClusterStore
+	RevenueGroups RevenueGroupStore
+	Currencies    CurrencyStore
+	MinimumTier   MinimumTierPolicy
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+// Handle rolls over resellers before clusters, as a cluster's net revenue last
+// year is that of its members. A reseller can't fail to roll over, but a
+// cluster may fail, e.g., for lack of an exchange rate or limits in effect at
+// the end of the fiscal year, without preventing other clusters from being
+// rolled over.
+func (h RollOverYearHandler) Handle(ctx context.Context, req RollOverYearCommand) (*RolloverReport, error) {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseRolloverID)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	start := h.Clock.NowUTC()
+	fiscalYear := h.Clock.Today().Year() - 1
+	exist, err := h.Rollovers.ExistByFiscalYear(ctx, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, NewConflictError("Rollover", "FiscalYear", fmt.Sprint(fiscalYear))
+	}
+
+	report := &RolloverReport{FiscalYear: fiscalYear}
+	resellers, err := h.Resellers.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, reseller := range resellers {
+		if reseller.FiscalYear > fiscalYear {
+			report.ResellersSkipped++
+			continue
+		}
+		if err := reseller.RollOver(fiscalYear+1, h.Clock.NowUTC()); err != nil {
+			return nil, err
+		}
+		if err := h.Projector.Apply(ctx, reseller); err != nil {
+			return nil, err
+		}
+		report.ResellersRolled++
+	}
+
+	clusters, err := h.Clusters.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := roller{handler: h, fiscalYear: fiscalYear, clusterLookup: newClusterLookup(h.RevenueGroups, h.Currencies)}
+	for _, cluster := range clusters {
+		if cluster.FiscalYear > fiscalYear {
+			report.ClustersSkipped = append(report.ClustersSkipped, cluster.ID)
+			continue
+		}
+		if err := r.rollOver(ctx, cluster); err != nil {
+			report.ClustersFailed = append(report.ClustersFailed, FailedCluster{ClusterID: cluster.ID, Reason: err.Error()})
+			continue
+		}
+		report.Clusters = append(report.Clusters, RolledOverCluster{
+			ClusterID:                     cluster.ID,
+			CalculatedNetRevenueLastYear:  cluster.CalculatedNetRevenueLastYear,
+			CalculatedResellerTierMinimum: cluster.CalculatedResellerTierMinimum,
+		})
+	}
+
+	if len(report.ClustersFailed) > 0 {
+		return report, nil
+	}
+	rollover := NewRollover(id, fiscalYear, start, h.Clock.NowUTC())
+	if err := h.Projector.Apply(ctx, &rollover); err != nil {
+		return nil, err
+	}
+	report.Recorded = true
+	return report, nil
+}
+
+type roller struct {
+	*clusterLookup
+	handler    RollOverYearHandler
+	fiscalYear int
+}
+
+// rollOver sums the net revenue of the cluster's members last year, converted
+// at the rates in effect at the end of the fiscal year, like the last tiering
+// of the year would. Without net revenue last year for any member, the cluster
+// has none either.
+func (r *roller) rollOver(ctx context.Context, cluster *Cluster) error {
+	revenueGroup, err := r.revenueGroup(ctx, cluster.RevenueGroupID)
+	if err != nil {
+		return err
+	}
+
+	yearEnd := NewDate(r.fiscalYear, 12, 31)
+	var netRevenue *Money
+	for _, m := range cluster.Members {
+		resellerID := MustParseResellerID(m.ResellerID)
+		reseller, err := r.handler.Resellers.GetByID(ctx, resellerID)
+		if err != nil {
+			return err
+		}
+		if reseller == nil {
+			return NewNotFoundError("Reseller", "ID", resellerID.String())
+		}
+		if reseller.CalculatedNetRevenueLastYear == nil {
+			continue
+		}
+
+		amount, err := r.convert(ctx, *reseller.CalculatedNetRevenueLastYear, revenueGroup.CurrencyCode, yearEnd)
+		if err != nil {
+			return err
+		}
+		netRevenue = addNetRevenue(netRevenue, amount)
+	}
+
+	fiscalYear := r.fiscalYear + 1
+	minimum, err := r.handler.MinimumTier.Minimum(revenueGroup, netRevenue, NewDate(fiscalYear, 1, 1))
+	if err != nil {
+		return err
+	}
+	if err := cluster.RollOver(fiscalYear, netRevenue, minimum, r.handler.Clock.NowUTC()); err != nil {
+		return err
+	}
+	return r.handler.Projector.Apply(ctx, cluster)
+}
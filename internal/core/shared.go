@@ -3,10 +3,12 @@ package core
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 	"uuid"
@@ -84,6 +86,17 @@ func (a *AggregateRoot) ClearDomainEvents() {
 	a.DomainEvents = []DomainEvent{}
 }
 
+// CheckVersion compares a caller supplied concurrency token with the version
+// the aggregate was read at. A nil expected version means the caller opted
+// out, and the store's optimistic lock alone guards against lost updates
+// between read and write.
+func (a *AggregateRoot) CheckVersion(aggregate string, expected *int32) error {
+	if expected != nil && *expected != a.Version {
+		return NewDataStaleError(aggregate, a.ID)
+	}
+	return nil
+}
+
 type Aggregate interface {
 	GetAggregateRoot() *AggregateRoot
 }
@@ -241,50 +254,163 @@ func (e *FieldParseError) NilOrError() error {
 	return e
 }
 
-// ISO 4217 country codes per https://en.wikipedia.org/wiki/ISO_4217.
-var CountryCodes = map[string]struct{}{
-	"AD": {}, "AE": {}, "AF": {}, "AG": {}, "AI": {}, "AL": {}, "AM": {},
-	"AN": {}, "BE": {}, "BF": {}, "BG": {}, "BH": {}, "BI": {}, "BJ": {},
-	"BL": {}, "BM": {}, "BN": {}, "BO": {}, "BQ": {}, "AR": {}, "AS": {},
-	"AO": {}, "AQ": {}, "AT": {}, "AU": {}, "AW": {}, "AX": {}, "AZ": {},
-	"BA": {}, "BB": {}, "BD": {}, "CG": {}, "CH": {}, "CI": {}, "CK": {},
-	"CL": {}, "CM": {}, "CN": {}, "CO": {}, "CR": {}, "CU": {}, "CV": {},
-	"CW": {}, "CX": {}, "CY": {}, "CZ": {}, "DE": {}, "DJ": {}, "DK": {},
-	"DM": {}, "DO": {}, "DZ": {}, "EC": {}, "EE": {}, "EG": {}, "EH": {},
-	"ER": {}, "ES": {}, "ET": {}, "FI": {}, "FJ": {}, "FK": {}, "FM": {},
-	"FO": {}, "FR": {}, "GA": {}, "GB": {}, "GD": {}, "BR": {}, "BS": {},
-	"BT": {}, "BV": {}, "BW": {}, "BY": {}, "BZ": {}, "CA": {}, "CC": {},
-	"CD": {}, "CF": {}, "GF": {}, "GG": {}, "GH": {}, "GI": {}, "GL": {},
-	"GE": {}, "GM": {}, "GN": {}, "GP": {}, "GQ": {}, "GR": {}, "GS": {},
-	"GT": {}, "GU": {}, "GW": {}, "GY": {}, "HK": {}, "HM": {}, "HN": {},
-	"HR": {}, "HT": {}, "HU": {}, "ID": {}, "IE": {}, "IL": {}, "IM": {},
-	"IN": {}, "IO": {}, "IQ": {}, "IR": {}, "IS": {}, "IT": {}, "JE": {},
-	"JM": {}, "JO": {}, "JP": {}, "KE": {}, "KG": {}, "KH": {}, "KI": {},
-	"KM": {}, "KN": {}, "KP": {}, "KR": {}, "KW": {}, "KY": {}, "KZ": {},
-	"LA": {}, "LB": {}, "LC": {}, "LI": {}, "LK": {}, "LR": {}, "LS": {},
-	"LT": {}, "LU": {}, "LV": {}, "LY": {}, "MA": {}, "MC": {}, "MD": {},
-	"ME": {}, "MF": {}, "MG": {}, "MV": {}, "MT": {}, "MU": {}, "MW": {},
-	"MX": {}, "MY": {}, "MZ": {}, "NA": {}, "NC": {}, "NE": {}, "NF": {},
-	"NG": {}, "NI": {}, "NL": {}, "NO": {}, "NP": {}, "NR": {}, "NU": {},
-	"NZ": {}, "OM": {}, "PA": {}, "PE": {}, "PF": {}, "PG": {}, "PH": {},
-	"ML": {}, "MI": {}, "MH": {}, "MK": {}, "MM": {}, "MN": {}, "MO": {},
-	"MP": {}, "MQ": {}, "MR": {}, "MS": {}, "RU": {}, "RW": {}, "RO": {},
-	"RS": {}, "SA": {}, "SB": {}, "SC": {}, "SD": {}, "SE": {}, "SG": {},
-	"PN": {}, "PK": {}, "PL": {}, "PM": {}, "PR": {}, "PS": {}, "PT": {},
-	"PW": {}, "PY": {}, "QA": {}, "RE": {}, "VI": {}, "VA": {}, "VC": {},
-	"VE": {}, "VG": {}, "VN": {}, "VU": {}, "WF": {}, "WK": {}, "WS": {},
-	"XK": {}, "XT": {}, "YE": {}, "YT": {}, "ZA": {}, "ZM": {}, "ZW": {},
-	"SH": {}, "SI": {}, "SJ": {}, "SK": {}, "SL": {}, "SM": {}, "SN": {},
-	"SO": {}, "SR": {}, "SS": {}, "ST": {}, "SV": {}, "SX": {}, "SY": {},
-	"SZ": {}, "TC": {}, "TD": {}, "TF": {}, "TG": {}, "TH": {}, "TJ": {},
-	"TK": {}, "TL": {}, "TM": {}, "TN": {}, "TO": {}, "TR": {}, "TT": {},
-	"TV": {}, "TW": {}, "TZ": {}, "UA": {}, "UG": {}, "UM": {}, "US": {},
-	"UY": {}, "UZ": {},
-}
-
-// ISO 4217 country codes per https://en.wikipedia.org/wiki/ISO_4217.
-var CurrencyCodes = map[string]struct{}{
-	"USD": {}, "EUR": {}, "DKK": {},
+// Pagination
+
+const (
+	PageLimitMin     = 1
+	PageLimitMax     = 100
+	PageLimitDefault = 20
+)
+
+type PageLimit struct {
+	v int
+}
+
+func (l PageLimit) V() int { return l.v }
+
+// ParsePageLimit treats zero as the caller not asking for a specific limit.
+func ParsePageLimit(v int) (PageLimit, error) {
+	if v == 0 {
+		return PageLimit{PageLimitDefault}, nil
+	}
+	if err := ValidateIntInclusiveRange(v, PageLimitMin, PageLimitMax); err != nil {
+		return PageLimit{}, err
+	}
+	return PageLimit{v}, nil
+}
+
+const pageCursorPrefix = "v1:"
+
+// PageCursor is a position in a listing ordered by a unique key. To the caller
+// it's opaque: it's handed out with one page and passed back unmodified to get
+// the next. Keyset rather than offset pagination keeps pages stable when rows
+// are added or removed between requests.
+type PageCursor struct {
+	after string
+}
+
+func NewPageCursor(after string) PageCursor {
+	return PageCursor{after}
+}
+
+// After is the key of the last item on the previous page. It's empty for the
+// first page.
+func (c PageCursor) After() string { return c.after }
+
+func (c PageCursor) IsFirst() bool { return c.after == "" }
+
+func (c PageCursor) String() string {
+	if c.IsFirst() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(pageCursorPrefix + c.after))
+}
+
+func ParsePageCursor(v string) (PageCursor, error) {
+	if v == "" {
+		return PageCursor{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return PageCursor{}, fmt.Errorf("must be a cursor from a previous page, but was %s", v)
+	}
+	after, ok := strings.CutPrefix(string(b), pageCursorPrefix)
+	if !ok || after == "" {
+		return PageCursor{}, fmt.Errorf("must be a cursor from a previous page, but was %s", v)
+	}
+	return PageCursor{after}, nil
+}
+
+// nextPage expects items to be fetched with one more row than limit. The extra
+// row signals that a next page exists without a separate count query.
+func nextPage[T any](items []T, limit PageLimit, key func(T) string) ([]T, string) {
+	if len(items) <= limit.V() {
+		return items, ""
+	}
+	items = items[:limit.V()]
+	return items, NewPageCursor(key(items[len(items)-1])).String()
+}
+
+// effectiveOn returns the item in effect on a date, i.e., the item with the
+// latest from not after the date. An item stays in effect until the next item
+// takes over, so items have no end date. Without an item from on or before the
+// date, ok is false.
+func effectiveOn[T any](items []T, from func(T) Date, on Date) (effective T, ok bool) {
+	for _, item := range items {
+		if from(item).After(on) {
+			continue
+		}
+		if !ok || from(item).After(from(effective)) {
+			effective, ok = item, true
+		}
+	}
+	return effective, ok
+}
+
+type PeriodStatus string
+
+const (
+	PeriodPast    PeriodStatus = "past"
+	PeriodCurrent PeriodStatus = "current"
+	PeriodFuture  PeriodStatus = "future"
+)
+
+// Period is the interval [From, To) during which an item is in effect. To is
+// the from of the next item, and nil for the last item as it stays in effect
+// indefinitely.
+type Period[T any] struct {
+	Item T
+	From Date
+	To   *Date
+}
+
+func (p Period[T]) Status(today Date) PeriodStatus {
+	if p.From.After(today) {
+		return PeriodFuture
+	}
+	if p.To != nil && !p.To.After(today) {
+		return PeriodPast
+	}
+	return PeriodCurrent
+}
+
+// Gap is the interval [From, To) during which no item is in effect. To is nil
+// when there are no items.
+type Gap struct {
+	From Date
+	To   *Date
+}
+
+// timeline returns the periods of items ordered by from. As from is unique
+// among items, periods are contiguous by construction and the only possible
+// gap is before the first period: any date from requiredFrom must have an item
+// in effect.
+func timeline[T any](items []T, from func(T) Date, requiredFrom *Date) ([]Period[T], []Gap) {
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b T) int {
+		return from(a).Compare(from(b))
+	})
+
+	periods := make([]Period[T], len(items))
+	for i, item := range items {
+		periods[i] = Period[T]{Item: item, From: from(item)}
+		if i > 0 {
+			to := from(item)
+			periods[i-1].To = &to
+		}
+	}
+
+	var gaps []Gap
+	if requiredFrom != nil {
+		switch {
+		case len(periods) == 0:
+			gaps = append(gaps, Gap{From: *requiredFrom})
+		case requiredFrom.Before(periods[0].From):
+			to := periods[0].From
+			gaps = append(gaps, Gap{From: *requiredFrom, To: &to})
+		}
+	}
+	return periods, gaps
 }
 
 type CurrencyCode struct {
@@ -308,10 +434,46 @@ func MustParseCurrencyCode(v string) CurrencyCode {
 	return v1
 }
 
-// Money in this system doesn't require the accuracy afforded by a decimal type.
+func (c CurrencyCode) MinorUnits() int { return ISO4217Currencies[c.v].MinorUnits }
+
+type CountryCode struct {
+	v string
+}
+
+func (c CountryCode) V() string { return c.v }
+
+func ParseCountryCode(v string) (CountryCode, error) {
+	if err := ValidateStringCountryCode(v); err != nil {
+		return CountryCode{}, err
+	}
+	return CountryCode{v}, nil
+}
+
+func MustParseCountryCode(v string) CountryCode {
+	v1, err := ParseCountryCode(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// MoneyAmountMax is the largest absolute amount accepted from outside, well
+// above any amount billed to a reseller.
+var MoneyAmountMax = NewDecimalFromInt(1_000_000_000_000)
+
 type Money struct {
-	Amount float64
-	Code   string
+	Amount Decimal `json:"amount"`
+	Code   string  `json:"code"`
+}
+
+// NewMoney rounds amount to the minor units of its currency, e.g., to whole
+// yen or to 1/100 of a krone. Rounding is half to even to avoid the upward
+// bias of rounding half up when summing many amounts.
+func NewMoney(amount Decimal, code CurrencyCode) Money {
+	return Money{
+		Amount: amount.Round(int32(code.MinorUnits())),
+		Code:   code.V(),
+	}
 }
 
 const layout = "2006-01-02"
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePageLimit(t *testing.T) {
+	tests := map[string]struct {
+		value    int
+		expected int
+		invalid  bool
+	}{
+		"default": {0, PageLimitDefault, false},
+		"min":     {PageLimitMin, PageLimitMin, false},
+		"max":     {PageLimitMax, PageLimitMax, false},
+		"below":   {-1, 0, true},
+		"above":   {PageLimitMax + 1, 0, true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := ParsePageLimit(tt.value)
+			if tt.invalid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, l.V())
+		})
+	}
+}
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	c := NewPageCursor("2026-07-01")
+
+	parsed, err := ParsePageCursor(c.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, "2026-07-01", parsed.After())
+}
+
+func TestParsePageCursorInvalid(t *testing.T) {
+	tests := []string{"not base64!", "djI6eA", "djE6"}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := ParsePageCursor(tt)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt)
+		})
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	key := func(i int) string { return strconv.Itoa(i) }
+	limit := PageLimit{2}
+
+	items, next := nextPage([]int{1, 2}, limit, key)
+	assert.Equal(t, []int{1, 2}, items)
+	assert.Empty(t, next)
+
+	items, next = nextPage([]int{1, 2, 3}, limit, key)
+	assert.Equal(t, []int{1, 2}, items)
+	cursor, err := ParsePageCursor(next)
+	require.NoError(t, err)
+	assert.Equal(t, "2", cursor.After())
+}
+
+func TestEffectiveOn(t *testing.T) {
+	from := func(d Date) Date { return d }
+	// Unordered, as items are held in the order they were added.
+	items := []Date{NewDate(2026, 7, 1), NewDate(2026, 1, 1), NewDate(2026, 4, 1)}
+	tests := map[string]struct {
+		on       Date
+		expected Date
+		ok       bool
+	}{
+		"before first": {NewDate(2025, 12, 31), Date{}, false},
+		"on first":     {NewDate(2026, 1, 1), NewDate(2026, 1, 1), true},
+		"between":      {NewDate(2026, 5, 1), NewDate(2026, 4, 1), true},
+		"after last":   {NewDate(2027, 1, 1), NewDate(2026, 7, 1), true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			effective, ok := effectiveOn(items, from, tt.on)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, effective)
+		})
+	}
+}
+
+func TestNewMoney(t *testing.T) {
+	tests := map[string]struct {
+		amount   string
+		code     string
+		expected string
+	}{
+		"two units":      {"10.125", "DKK", "10.12"},
+		"two units up":   {"10.135", "DKK", "10.14"},
+		"zero units":     {"1234.5", "JPY", "1234"},
+		"zero units up":  {"1235.5", "JPY", "1236"},
+		"three units":    {"1.2345", "BHD", "1.234"},
+		"already scaled": {"99.99", "SEK", "99.99"},
+		"negative":       {"-10.125", "DKK", "-10.12"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := NewMoney(MustParseDecimal(tt.amount), MustParseCurrencyCode(tt.code))
+			assert.Equal(t, MustParseDecimal(tt.expected), m.Amount)
+			assert.Equal(t, tt.code, m.Code)
+		})
+	}
+}
@@ -12,23 +12,35 @@ import (
 type TierDiscountStore interface {
 	ExistByID(context.Context, TierDiscountID) (bool, error)
 	GetByID(context.Context, TierDiscountID) (*TierDiscount, error)
+	List(context.Context, TierDiscountCriteria) ([]*TierDiscount, error)
+}
+
+// TierDiscountCriteria selects tier discounts ordered by from, descending if
+// Descending is set. A nil filter field doesn't filter, and a zero limit
+// doesn't limit. Both from bounds are inclusive.
+type TierDiscountCriteria struct {
+	FromMin    *Date
+	FromMax    *Date
+	AfterFrom  *Date
+	Descending bool
+	Limit      int
 }
 
 type TierDiscountCreatedEvent struct {
 	domainEventCommon
 	ID         uuid.UUID
-	Authorized float64
-	Advanced   float64
-	Premier    float64
+	Authorized Decimal
+	Advanced   Decimal
+	Premier    Decimal
 	From       Date
 }
 
 type TierDiscountUpdatedEvent struct {
 	domainEventCommon
 	ID         uuid.UUID
-	Authorized float64
-	Advanced   float64
-	Premier    float64
+	Authorized Decimal
+	Advanced   Decimal
+	Premier    Decimal
 	From       Date
 }
 
@@ -37,11 +49,25 @@ type TierDiscountRemovedEvent struct {
 	ID uuid.UUID
 }
 
+// NoTierDiscountError signals that no tier discount is in effect on a date,
+// i.e., there are no tier discounts or every one is from a later date.
+type NoTierDiscountError struct {
+	On Date
+}
+
+func NewNoTierDiscountError(on Date) *NoTierDiscountError {
+	return &NoTierDiscountError{On: on}
+}
+
+func (e *NoTierDiscountError) Error() string {
+	return fmt.Sprintf("no tier discount in effect on %s", e.On)
+}
+
 const (
-	TierDiscountCreateRequiresFututureFrom = 1600
-	TierDiscountUpdateRequiresFutureFrom   = 1601
-	TierDiscountRemoveRequiresFutureFrom   = 1602
-	TierDiscountUpdateRequiresChange       = 1603
+	TierDiscountCreateRequiresFututureFrom = 1700
+	TierDiscountUpdateRequiresFutureFrom   = 1701
+	TierDiscountRemoveRequiresFutureFrom   = 1702
+	TierDiscountUpdateRequiresChange       = 1703
 )
 
 // TierDiscountID
@@ -71,49 +97,68 @@ func MustParseTierDiscountId(v uuid.UUID) TierDiscountID {
 // DiscountPercentages
 
 const (
-	TierDiscountPercentageMin              float64 = 0.
-	TierDiscountPercentageMax              float64 = 100.
-	TierDiscountPercentageDecimalPlacesMin         = 0
-	TierDiscountPercentageDecimalPlacesMax         = 2
+	TierDiscountPercentageDecimalPlacesMin = 0
+	TierDiscountPercentageDecimalPlacesMax = 2
+)
+
+var (
+	TierDiscountPercentageMin = NewDecimalFromInt(0)
+	TierDiscountPercentageMax = NewDecimalFromInt(100)
 )
 
 type DiscountPercentages struct {
-	authorized float64
-	advanced   float64
-	premier    float64
+	authorized Decimal
+	advanced   Decimal
+	premier    Decimal
 }
 
-func (c DiscountPercentages) Authorized() float64 { return c.authorized }
-func (c DiscountPercentages) Advanced() float64   { return c.advanced }
-func (c DiscountPercentages) Premier() float64    { return c.premier }
+func (c DiscountPercentages) Authorized() Decimal { return c.authorized }
+func (c DiscountPercentages) Advanced() Decimal   { return c.advanced }
+func (c DiscountPercentages) Premier() Decimal    { return c.premier }
+
+// Of returns the percentage of a tier, and zero for no tier.
+func (c DiscountPercentages) Of(tier *ResellerTier) Decimal {
+	if tier == nil {
+		return NewDecimalFromInt(0)
+	}
+	switch *tier {
+	case ResellerTierAuthorized:
+		return c.authorized
+	case ResellerTierAdvanced:
+		return c.advanced
+	case ResellerTierPremier:
+		return c.premier
+	}
+	panic(fmt.Sprintf("unhandled tier: %s", *tier))
+}
 
-func ParseDiscountPercentages(authorized, advanced, premier float64) (DiscountPercentages, error) {
+func ParseDiscountPercentages(authorized, advanced, premier Decimal) (DiscountPercentages, error) {
 	// A compound value type may report multiple validation errors per field.
 	errs := &FieldParseError{}
-	if err := ValidateFloat64InclusiveRange(authorized, TierDiscountPercentageMin, TierDiscountPercentageMax); err != nil {
+	if err := ValidateDecimalInclusiveRange(authorized, TierDiscountPercentageMin, TierDiscountPercentageMax); err != nil {
 		errs.Add(err.Error()) // TODO(rh): field name would be missing from error.
 	}
-	if err := ValidateFloat64InclusiveRange(advanced, TierDiscountPercentageMin, TierDiscountPercentageMax); err != nil {
+	if err := ValidateDecimalInclusiveRange(advanced, TierDiscountPercentageMin, TierDiscountPercentageMax); err != nil {
 		errs.Add(err.Error())
 	}
-	if err := ValidateFloat64InclusiveRange(premier, TierDiscountPercentageMin, TierDiscountPercentageMax); err != nil {
+	if err := ValidateDecimalInclusiveRange(premier, TierDiscountPercentageMin, TierDiscountPercentageMax); err != nil {
 		errs.Add(err.Error())
 	}
-	if err := ValidateFloat64DecimalPlaces(authorized, TierDiscountPercentageDecimalPlacesMin, TierDiscountPercentageDecimalPlacesMax); err != nil {
+	if err := ValidateDecimalPlaces(authorized, TierDiscountPercentageDecimalPlacesMin, TierDiscountPercentageDecimalPlacesMax); err != nil {
 		errs.Add(err.Error())
 	}
-	if err := ValidateFloat64DecimalPlaces(advanced, TierDiscountPercentageDecimalPlacesMin, TierDiscountPercentageDecimalPlacesMax); err != nil {
+	if err := ValidateDecimalPlaces(advanced, TierDiscountPercentageDecimalPlacesMin, TierDiscountPercentageDecimalPlacesMax); err != nil {
 		errs.Add(err.Error())
 	}
-	if err := ValidateFloat64DecimalPlaces(premier, TierDiscountPercentageDecimalPlacesMin, TierDiscountPercentageDecimalPlacesMax); err != nil {
+	if err := ValidateDecimalPlaces(premier, TierDiscountPercentageDecimalPlacesMin, TierDiscountPercentageDecimalPlacesMax); err != nil {
 		errs.Add(err.Error())
 	}
-	if authorized > advanced {
-		message := fmt.Sprintf("Authorized %g must be less than or equal to Advanced %g", authorized, advanced)
+	if authorized.GreaterThan(advanced) {
+		message := fmt.Sprintf("Authorized %s must be less than or equal to Advanced %s", authorized, advanced)
 		errs.Add(message)
 	}
-	if advanced > premier {
-		message := fmt.Sprintf("Advanced %g must be less than or equal to Premier %g", advanced, premier)
+	if advanced.GreaterThan(premier) {
+		message := fmt.Sprintf("Advanced %s must be less than or equal to Premier %s", advanced, premier)
 		errs.Add(message)
 	}
 	if err := errs.NilOrError(); err != nil {
@@ -127,7 +172,7 @@ func ParseDiscountPercentages(authorized, advanced, premier float64) (DiscountPe
 	}, nil
 }
 
-func MustParseDiscountPercentages(authorized, advanced, premier float64) DiscountPercentages {
+func MustParseDiscountPercentages(authorized, advanced, premier Decimal) DiscountPercentages {
 	v1, err := ParseDiscountPercentages(authorized, advanced, premier)
 	if err != nil {
 		panic(err)
@@ -230,12 +275,41 @@ func (td *TierDiscount) Remove(removeAt time.Time) error {
 	return nil
 }
 
+// EffectiveTierDiscount returns the tier discount in effect on a date, i.e.,
+// the one with the latest from not after the date. Like an exchange rate, a
+// tier discount stays in effect until the next one takes over.
+func EffectiveTierDiscount(tierDiscounts []*TierDiscount, on Date) (*TierDiscount, error) {
+	effective, ok := effectiveOn(tierDiscounts, tierDiscountFrom, on)
+	if !ok {
+		return nil, NewNoTierDiscountError(on)
+	}
+	return effective, nil
+}
+
+func tierDiscountFrom(td *TierDiscount) Date { return td.From.V() }
+
+// TierDiscountTimeline returns the periods of tier discounts ordered by from.
+// Any date from requiredFrom must have a tier discount in effect.
+func TierDiscountTimeline(tierDiscounts []*TierDiscount, requiredFrom *Date) ([]Period[*TierDiscount], []Gap) {
+	return timeline(tierDiscounts, tierDiscountFrom, requiredFrom)
+}
+
 // Application
 
+// parseTierDiscountEffectiveOn bounds the date asked about to the dates a tier
+// discount may be from. Outside it no tier discount can be in effect or the
+// date is a mistake.
+func parseTierDiscountEffectiveOn(v Date) (Date, error) {
+	if err := ValidateDateInclusiveRange(v, TierDiscountFromMin, TierDiscountFromMax); err != nil {
+		return Date{}, err
+	}
+	return v, nil
+}
+
 type DiscountPercentagesInput struct {
-	Authorized float64
-	Advanced   float64
-	Premier    float64
+	Authorized Decimal
+	Advanced   Decimal
+	Premier    Decimal
 }
 
 type CreateTierDiscountCommand struct {
@@ -276,9 +350,10 @@ func (h CreateTierDiscountHandler) Handle(ctx context.Context, req CreateTierDis
 }
 
 type UpdateTierDiscountCommand struct {
-	ID          uuid.UUID
-	Percentages DiscountPercentagesInput
-	From        Date
+	ID              uuid.UUID
+	Percentages     DiscountPercentagesInput
+	From            Date
+	ExpectedVersion *int32
 }
 
 type UpdateTierDiscountHandler struct {
@@ -291,7 +366,7 @@ func (h UpdateTierDiscountHandler) Handle(ctx context.Context, req UpdateTierDis
 	parser := &RequestParseCollector{}
 	id := parser.Parse("ID", req.ID, ParseTierDiscountID)
 	percentages := parser.Parse("Percentages", req.Percentages, func(dp DiscountPercentagesInput) (DiscountPercentages, error) {
-		return ParseDiscountPercentages(dp.Advanced, dp.Advanced, dp.Premier)
+		return ParseDiscountPercentages(dp.Authorized, dp.Advanced, dp.Premier)
 	})
 	from := parser.Parse("From", req.From, ParseTierDiscountFrom)
 	if parser.HasErrors() {
@@ -305,6 +380,9 @@ func (h UpdateTierDiscountHandler) Handle(ctx context.Context, req UpdateTierDis
 	if tierDiscount == nil {
 		return NewNotFoundError("TierDiscount", "ID", id.String())
 	}
+	if err := tierDiscount.CheckVersion("TierDiscount", req.ExpectedVersion); err != nil {
+		return err
+	}
 
 	if err := tierDiscount.Update(percentages, from, h.Clock.NowUTC()); err != nil {
 		return err
@@ -313,7 +391,8 @@ func (h UpdateTierDiscountHandler) Handle(ctx context.Context, req UpdateTierDis
 }
 
 type RemoveTierDiscountCommand struct {
-	ID uuid.UUID
+	ID              uuid.UUID
+	ExpectedVersion *int32
 }
 
 type RemoveTierDiscountHandler struct {
@@ -336,6 +415,9 @@ func (h RemoveTierDiscountHandler) Handle(ctx context.Context, req RemoveTierDis
 	if tierDiscount == nil {
 		return NewNotFoundError("TierDiscount", "ID", id.String())
 	}
+	if err := tierDiscount.CheckVersion("TierDiscount", req.ExpectedVersion); err != nil {
+		return err
+	}
 
 	if err := tierDiscount.Remove(h.Clock.NowUTC()); err != nil {
 		return err
@@ -347,11 +429,29 @@ type GetTierDiscountQuery struct {
 	ID uuid.UUID
 }
 
+// TierDiscountResponse decouples the public contract from the TierDiscount
+// aggregate so that adding fields to the aggregate doesn't leak its internals,
+// such as domain events, to callers.
+type TierDiscountResponse struct {
+	ID          uuid.UUID                   `json:"id"`
+	Version     int32                       `json:"-"`
+	Percentages DiscountPercentagesResponse `json:"percentages"`
+	From        Date                        `json:"from"`
+	CreatedAt   time.Time                   `json:"created_at"`
+	UpdatedAt   *time.Time                  `json:"updated_at"`
+}
+
+type DiscountPercentagesResponse struct {
+	Authorized Decimal `json:"authorized"`
+	Advanced   Decimal `json:"advanced"`
+	Premier    Decimal `json:"premier"`
+}
+
 type GetTierDiscountHandler struct {
 	TierDiscounts TierDiscountStore
 }
 
-func (h GetTierDiscountHandler) Handle(ctx context.Context, req GetTierDiscountQuery) (*TierDiscount, error) {
+func (h GetTierDiscountHandler) Handle(ctx context.Context, req GetTierDiscountQuery) (*TierDiscountResponse, error) {
 	parser := &RequestParseCollector{}
 	id := parser.Parse("ID", req.ID, ParseTierDiscountID)
 	if parser.HasErrors() {
@@ -365,5 +465,179 @@ func (h GetTierDiscountHandler) Handle(ctx context.Context, req GetTierDiscountQ
 	if tierDiscount == nil {
 		return nil, NewNotFoundError("TierDiscount", "ID", id.String())
 	}
-	return tierDiscount, nil
+
+	return newTierDiscountResponse(tierDiscount), nil
+}
+
+func newTierDiscountResponse(tierDiscount *TierDiscount) *TierDiscountResponse {
+	return &TierDiscountResponse{
+		ID:      tierDiscount.ID,
+		Version: tierDiscount.Version,
+		Percentages: DiscountPercentagesResponse{
+			Authorized: tierDiscount.Percentages.Authorized(),
+			Advanced:   tierDiscount.Percentages.Advanced(),
+			Premier:    tierDiscount.Percentages.Premier(),
+		},
+		From:      tierDiscount.From.V(),
+		CreatedAt: tierDiscount.CreatedAt,
+		UpdatedAt: tierDiscount.UpdatedAt,
+	}
+}
+
+type ListTierDiscountsQuery struct {
+	FromMin *Date
+	FromMax *Date
+	Cursor  string
+	Limit   int
+}
+
+type ListTierDiscountsResponse struct {
+	Items      []*TierDiscountResponse `json:"items"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+type ListTierDiscountsHandler struct {
+	TierDiscounts TierDiscountStore
+}
+
+func (h ListTierDiscountsHandler) Handle(ctx context.Context, req ListTierDiscountsQuery) (*ListTierDiscountsResponse, error) {
+	parser := &RequestParseCollector{}
+	cursor := parser.Parse("Cursor", req.Cursor, parseTierDiscountCursor)
+	limit := parser.Parse("Limit", req.Limit, ParsePageLimit)
+	if req.FromMin != nil && req.FromMax != nil && req.FromMin.After(*req.FromMax) {
+		parser.add("FromMax", fmt.Sprintf("must be on or after FromMin %s, but was %s", req.FromMin, req.FromMax))
+	}
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	tierDiscounts, err := h.TierDiscounts.List(ctx, TierDiscountCriteria{
+		FromMin:   req.FromMin,
+		FromMax:   req.FromMax,
+		AfterFrom: cursor,
+		Limit:     limit.V() + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page, next := nextPage(tierDiscounts, limit, func(td *TierDiscount) string { return td.From.String() })
+	items := make([]*TierDiscountResponse, len(page))
+	for i, td := range page {
+		items[i] = newTierDiscountResponse(td)
+	}
+	return &ListTierDiscountsResponse{
+		Items:      items,
+		NextCursor: next,
+	}, nil
+}
+
+func parseTierDiscountCursor(v string) (*Date, error) {
+	cursor, err := ParsePageCursor(v)
+	if err != nil || cursor.IsFirst() {
+		return nil, err
+	}
+	from, err := ParseDate(cursor.After())
+	if err != nil {
+		return nil, fmt.Errorf("must be a cursor from a previous page, but was %s", v)
+	}
+	return &from, nil
+}
+
+type GetEffectiveTierDiscountQuery struct {
+	On Date
+}
+
+type GetEffectiveTierDiscountHandler struct {
+	TierDiscounts TierDiscountStore
+}
+
+func (h GetEffectiveTierDiscountHandler) Handle(ctx context.Context, req GetEffectiveTierDiscountQuery) (*TierDiscountResponse, error) {
+	parser := &RequestParseCollector{}
+	on := parser.Parse("On", req.On, parseTierDiscountEffectiveOn)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	// Only the latest tier discount from on or before the date can be in
+	// effect, so only it is loaded.
+	tierDiscounts, err := h.TierDiscounts.List(ctx, TierDiscountCriteria{FromMax: &on, Descending: true, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	tierDiscount, err := EffectiveTierDiscount(tierDiscounts, on)
+	if err != nil {
+		return nil, err
+	}
+	return newTierDiscountResponse(tierDiscount), nil
+}
+
+type GetTierDiscountTimelineQuery struct {
+	// RequiredFrom is the first date a tier discount must be in effect, e.g.,
+	// the first order date. Without it no gaps are reported.
+	RequiredFrom *Date
+}
+
+type TierDiscountPeriodResponse struct {
+	TierDiscountID uuid.UUID                   `json:"tier_discount_id"`
+	Percentages    DiscountPercentagesResponse `json:"percentages"`
+	From           Date                        `json:"from"`
+	To             *Date                       `json:"to"`
+	Status         PeriodStatus                `json:"status"`
+}
+
+type TierDiscountGapResponse struct {
+	From Date  `json:"from"`
+	To   *Date `json:"to"`
+}
+
+type TierDiscountTimelineResponse struct {
+	Today   Date                          `json:"today"`
+	Periods []*TierDiscountPeriodResponse `json:"periods"`
+	Gaps    []*TierDiscountGapResponse    `json:"gaps"`
+}
+
+type GetTierDiscountTimelineHandler struct {
+	TierDiscounts TierDiscountStore
+	Clock         Clock
+}
+
+func (h GetTierDiscountTimelineHandler) Handle(ctx context.Context, req GetTierDiscountTimelineQuery) (*TierDiscountTimelineResponse, error) {
+	parser := &RequestParseCollector{}
+	if req.RequiredFrom != nil {
+		parser.Parse("RequiredFrom", *req.RequiredFrom, parseTierDiscountEffectiveOn)
+	}
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	tierDiscounts, err := h.TierDiscounts.List(ctx, TierDiscountCriteria{})
+	if err != nil {
+		return nil, err
+	}
+
+	today := h.Clock.Today()
+	periods, gaps := TierDiscountTimeline(tierDiscounts, req.RequiredFrom)
+	res := &TierDiscountTimelineResponse{
+		Today:   today,
+		Periods: make([]*TierDiscountPeriodResponse, len(periods)),
+		Gaps:    make([]*TierDiscountGapResponse, len(gaps)),
+	}
+	for i, p := range periods {
+		res.Periods[i] = &TierDiscountPeriodResponse{
+			TierDiscountID: p.Item.ID,
+			Percentages: DiscountPercentagesResponse{
+				Authorized: p.Item.Percentages.Authorized(),
+				Advanced:   p.Item.Percentages.Advanced(),
+				Premier:    p.Item.Percentages.Premier(),
+			},
+			From:   p.From,
+			To:     p.To,
+			Status: p.Status(today),
+		}
+	}
+	for i, g := range gaps {
+		res.Gaps[i] = &TierDiscountGapResponse{From: g.From, To: g.To}
+	}
+	return res, nil
 }
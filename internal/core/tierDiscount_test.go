@@ -0,0 +1,119 @@
+package core
+
+import (
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTierDiscounts(percentages map[Date][3]string) []*TierDiscount {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var tierDiscounts []*TierDiscount
+	for from, p := range percentages {
+		dp := MustParseDiscountPercentages(MustParseDecimal(p[0]), MustParseDecimal(p[1]), MustParseDecimal(p[2]))
+		td := NewTierDiscount(MustParseTierDiscountId(uuid.New()), dp, MustParseTierDiscountFrom(from), createdAt)
+		tierDiscounts = append(tierDiscounts, &td)
+	}
+	return tierDiscounts
+}
+
+func TestEffectiveTierDiscount(t *testing.T) {
+	tierDiscounts := newTestTierDiscounts(map[Date][3]string{
+		NewDate(2026, 7, 1): {"3", "6", "9"},
+		NewDate(2026, 1, 1): {"1", "2", "3"},
+	})
+
+	tests := map[string]struct {
+		on       Date
+		expected string
+		invalid  bool
+	}{
+		"before first": {NewDate(2025, 12, 31), "", true},
+		"on first":     {NewDate(2026, 1, 1), "1", false},
+		"between":      {NewDate(2026, 6, 30), "1", false},
+		"on last":      {NewDate(2026, 7, 1), "3", false},
+		"after last":   {NewDate(2027, 1, 1), "3", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			td, err := EffectiveTierDiscount(tierDiscounts, tt.on)
+			if tt.invalid {
+				var noTierDiscount *NoTierDiscountError
+				require.ErrorAs(t, err, &noTierDiscount)
+				assert.Equal(t, tt.on, noTierDiscount.On)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, MustParseDecimal(tt.expected), td.Percentages.Authorized())
+		})
+	}
+}
+
+func TestDiscountPercentagesOf(t *testing.T) {
+	p := MustParseDiscountPercentages(MustParseDecimal("1.5"), MustParseDecimal("2.5"), MustParseDecimal("5"))
+
+	tests := map[string]struct {
+		tier     *ResellerTier
+		expected string
+	}{
+		"no tier":    {nil, "0"},
+		"authorized": {tierPtr(ResellerTierAuthorized), "1.5"},
+		"advanced":   {tierPtr(ResellerTierAdvanced), "2.5"},
+		"premier":    {tierPtr(ResellerTierPremier), "5"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Zero(t, MustParseDecimal(tt.expected).Cmp(p.Of(tt.tier)))
+		})
+	}
+}
+
+func TestTierDiscountTimeline(t *testing.T) {
+	tierDiscounts := newTestTierDiscounts(map[Date][3]string{
+		NewDate(2026, 7, 1): {"3", "6", "9"},
+		NewDate(2026, 1, 1): {"1", "2", "3"},
+		NewDate(2026, 4, 1): {"2", "4", "6"},
+	})
+	today := NewDate(2026, 4, 15)
+
+	periods, gaps := TierDiscountTimeline(tierDiscounts, nil)
+
+	require.Len(t, periods, 3)
+	assert.Empty(t, gaps)
+	assert.Equal(t, NewDate(2026, 1, 1), periods[0].From)
+	assert.Equal(t, NewDate(2026, 4, 1), *periods[0].To)
+	assert.Equal(t, PeriodPast, periods[0].Status(today))
+	assert.Equal(t, NewDate(2026, 4, 1), periods[1].From)
+	assert.Equal(t, NewDate(2026, 7, 1), *periods[1].To)
+	assert.Equal(t, PeriodCurrent, periods[1].Status(today))
+	assert.Equal(t, MustParseDecimal("4"), periods[1].Item.Percentages.Advanced())
+	assert.Equal(t, NewDate(2026, 7, 1), periods[2].From)
+	assert.Nil(t, periods[2].To)
+	assert.Equal(t, PeriodFuture, periods[2].Status(today))
+}
+
+func TestTierDiscountTimelineGaps(t *testing.T) {
+	first := NewDate(2026, 1, 1)
+	tests := map[string]struct {
+		percentages  map[Date][3]string
+		requiredFrom Date
+		expected     []Gap
+	}{
+		"no tier discounts": {nil, NewDate(2025, 12, 1), []Gap{{From: NewDate(2025, 12, 1)}}},
+		"before":            {map[Date][3]string{first: {"1", "2", "3"}}, NewDate(2025, 12, 1), []Gap{{From: NewDate(2025, 12, 1), To: &first}}},
+		"on first":          {map[Date][3]string{first: {"1", "2", "3"}}, first, nil},
+		"after first":       {map[Date][3]string{first: {"1", "2", "3"}}, NewDate(2026, 1, 2), nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, gaps := TierDiscountTimeline(newTestTierDiscounts(tt.percentages), &tt.requiredFrom)
+			assert.Equal(t, tt.expected, gaps)
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"uuid"
+)
+
+// Domain
+
+type TieringStore interface {
+	ExistByTierAt(context.Context, Date) (bool, error)
+}
+
+type TieringRecordedEvent struct {
+	domainEventCommon
+	ID     uuid.UUID `json:"id"`
+	TierAt Date      `json:"tier_at"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// ResellerTier is the tier a reseller reaches from the net revenue of its
+// cluster. A reseller below Authorized has no tier, which is represented by a
+// nil tier rather than a separate value.
+type ResellerTier string
+
+const (
+	ResellerTierAuthorized ResellerTier = "Authorized"
+	ResellerTierAdvanced   ResellerTier = "Advanced"
+	ResellerTierPremier    ResellerTier = "Premier"
+)
+
+var ResellerTiers = map[ResellerTier]struct{}{
+	ResellerTierAuthorized: {}, ResellerTierAdvanced: {}, ResellerTierPremier: {},
+}
+
+func ParseResellerTier(v string) (ResellerTier, error) {
+	if _, ok := ResellerTiers[ResellerTier(v)]; !ok {
+		return "", fmt.Errorf("must be one of Authorized, Advanced, or Premier, but was %s", v)
+	}
+	return ResellerTier(v), nil
+}
+
+func MustParseResellerTier(v string) ResellerTier {
+	v1, err := ParseResellerTier(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// resellerTiersAscending orders tiers from lowest to highest.
+var resellerTiersAscending = []ResellerTier{ResellerTierAuthorized, ResellerTierAdvanced, ResellerTierPremier}
+
+// rankResellerTier ranks no tier as zero and Premier as the highest.
+func rankResellerTier(t *ResellerTier) int {
+	if t == nil {
+		return 0
+	}
+	return slices.Index(resellerTiersAscending, *t) + 1
+}
+
+// TierBasis explains a cluster's tier, which is the higher of the tier reached
+// by year-to-date net revenue and the minimum tier from last year.
+type TierBasis string
+
+const (
+	TierBasisYearToDate TierBasis = "YearToDate"
+	TierBasisMinimum    TierBasis = "Minimum"
+)
+
+// MinimumTierPolicy protects a cluster's tier based on last year's
+// achievement: a cluster that reached a tier last year can't drop more than
+// drop tiers below it this year. With a drop of zero, a cluster keeps last
+// year's tier, and with a drop of three, no tier is protected.
+type MinimumTierPolicy struct {
+	drop int
+}
+
+func (p MinimumTierPolicy) Drop() int { return p.drop }
+
+func ParseMinimumTierPolicy(drop int) (MinimumTierPolicy, error) {
+	if drop < 0 || drop > len(resellerTiersAscending) {
+		return MinimumTierPolicy{}, fmt.Errorf("drop must be between 0 and %d, but was %d", len(resellerTiersAscending), drop)
+	}
+	return MinimumTierPolicy{drop}, nil
+}
+
+func MustParseMinimumTierPolicy(drop int) MinimumTierPolicy {
+	v, err := ParseMinimumTierPolicy(drop)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Floor returns the minimum tier for a cluster that reached lastYear, or nil
+// for no minimum.
+func (p MinimumTierPolicy) Floor(lastYear *ResellerTier) *ResellerTier {
+	rank := rankResellerTier(lastYear) - p.drop
+	if rank <= 0 {
+		return nil
+	}
+	tier := resellerTiersAscending[rank-1]
+	return &tier
+}
+
+// Minimum returns the minimum tier for the year of on. The tier reached last
+// year is that of net revenue last year compared with the limits in effect at
+// the end of last year. Without net revenue last year, there's no minimum.
+// Neither is there without limits in effect at the end of last year, e.g., for
+// a revenue group whose first limits are from this year, as no tier could be
+// reached.
+func (p MinimumTierPolicy) Minimum(revenueGroup *RevenueGroup, netRevenueLastYear *Money, on Date) (*ResellerTier, error) {
+	if netRevenueLastYear == nil {
+		return nil, nil
+	}
+	limit, err := revenueGroup.EffectiveLimit(NewDate(on.Year()-1, 12, 31))
+	var noLimit *NoRevenueGroupLimitError
+	if errors.As(err, &noLimit) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.Floor(limit.Limits.Tier(netRevenueLastYear.Amount)), nil
+}
+
+// TieringID
+
+type TieringID struct {
+	v uuid.UUID
+}
+
+func (t TieringID) V() uuid.UUID   { return t.v }
+func (t TieringID) String() string { return t.v.String() }
+
+func ParseTieringID(v uuid.UUID) (TieringID, error) {
+	if err := ValidateUUIDNotZero(v); err != nil {
+		return TieringID{}, err
+	}
+	return TieringID{v}, nil
+}
+
+func MustParseTieringID(v uuid.UUID) TieringID {
+	v1, err := ParseTieringID(v)
+	if err != nil {
+		panic(err)
+	}
+	return v1
+}
+
+// Tiering records that every cluster was tiered on a date. It's only recorded
+// once all clusters are tiered, so a tiering that failed part way leaves no
+// record, and running it again tiers the remaining clusters.
+type Tiering struct {
+	AggregateRoot
+	TierAt Date
+	Start  time.Time
+	End    time.Time
+}
+
+func NewTiering(id TieringID, tierAt Date, start time.Time, end time.Time) Tiering {
+	t := Tiering{
+		ID:        id.V(),
+		CreatedAt: end,
+		TierAt:    tierAt,
+		Start:     start,
+		End:       end,
+	}
+
+	t.AddDomainEvent(TieringRecordedEvent{
+		OccurredAt: end,
+		ID:         id.V(),
+		TierAt:     tierAt,
+		Start:      start,
+		End:        end,
+	})
+	return t
+}
+
+func (t *Tiering) Equal(other *Tiering) bool {
+	return EntityEqual(t, other)
+}
+
+// Application
+
+type TierClustersCommand struct {
+	ID uuid.UUID
+}
+
+// TieredCluster explains a cluster's tier by its year-to-date and minimum
+// tiers, e.g., a cluster is Advanced because of last year's minimum.
+type TieredCluster struct {
+	ClusterID                        uuid.UUID
+	ResellerTier                     *ResellerTier
+	ResellerTierBasis                TierBasis
+	CalculatedNetRevenueYearToDate   Money
+	CalculatedResellerTierYearToDate *ResellerTier
+	CalculatedResellerTierMinimum    *ResellerTier
+	CalculatedNetRevenueProjected    Money
+	CalculatedResellerTierProjected  *ResellerTier
+}
+
+type FailedCluster struct {
+	ClusterID uuid.UUID
+	Reason    string
+}
+
+// TieringReport holds the outcome of tiering by cluster. Clusters tiered by an
+// earlier run on the same date are skipped. Recorded is false when a cluster
+// failed, and the tiering must be run again.
+type TieringReport struct {
+	TierAt   Date
+	Tiered   []TieredCluster
+	Skipped  []uuid.UUID
+	Failed   []FailedCluster
+	Recorded bool
+}
+
+type TierClustersHandler struct {
+	Tierings      TieringStore
+	Clusters      ClusterStore
+	Resellers     ResellerStore
+	RevenueGroups RevenueGroupStore
+	Currencies    CurrencyStore
+	Projection    NetRevenueProjection
+	MinimumTier   MinimumTierPolicy
+	Projector     StoreProjector
+	Clock         Clock
+}
+
+// Handle tiers and projects each cluster in a StoreProjector.Apply of its own.
+// A cluster failing, e.g., for lack of an exchange rate or limits in effect,
+// doesn't prevent other clusters from being tiered.
+func (h TierClustersHandler) Handle(ctx context.Context, req TierClustersCommand) (*TieringReport, error) {
+	parser := &RequestParseCollector{}
+	id := parser.Parse("ID", req.ID, ParseTieringID)
+	if parser.HasErrors() {
+		return nil, parser
+	}
+
+	start := h.Clock.NowUTC()
+	tierAt := h.Clock.Today()
+	exist, err := h.Tierings.ExistByTierAt(ctx, tierAt)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, NewConflictError("Tiering", "TierAt", tierAt.String())
+	}
+
+	clusters, err := h.Clusters.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TieringReport{TierAt: tierAt}
+	t := tierer{handler: h, tierAt: tierAt, clusterLookup: newClusterLookup(h.RevenueGroups, h.Currencies)}
+	for _, cluster := range clusters {
+		if cluster.TieredOn(tierAt) {
+			report.Skipped = append(report.Skipped, cluster.ID)
+			continue
+		}
+		if err := t.tier(ctx, cluster); err != nil {
+			report.Failed = append(report.Failed, FailedCluster{ClusterID: cluster.ID, Reason: err.Error()})
+			continue
+		}
+		tier, basis := cluster.ResellerTier()
+		report.Tiered = append(report.Tiered, TieredCluster{
+			ClusterID:                        cluster.ID,
+			ResellerTier:                     tier,
+			ResellerTierBasis:                basis,
+			CalculatedNetRevenueYearToDate:   *cluster.CalculatedNetRevenueYearToDate,
+			CalculatedResellerTierYearToDate: cluster.CalculatedResellerTierYearToDate,
+			CalculatedResellerTierMinimum:    cluster.CalculatedResellerTierMinimum,
+			CalculatedNetRevenueProjected:    *cluster.CalculatedNetRevenueProjected,
+			CalculatedResellerTierProjected:  cluster.CalculatedResellerTierProjected,
+		})
+	}
+
+	if len(report.Failed) > 0 {
+		return report, nil
+	}
+	tiering := NewTiering(id, tierAt, start, h.Clock.NowUTC())
+	if err := h.Projector.Apply(ctx, &tiering); err != nil {
+		return nil, err
+	}
+	report.Recorded = true
+	return report, nil
+}
+
+type tierer struct {
+	*clusterLookup
+	handler TierClustersHandler
+	tierAt  Date
+}
+
+func (t *tierer) tier(ctx context.Context, cluster *Cluster) error {
+	revenueGroup, err := t.revenueGroup(ctx, cluster.RevenueGroupID)
+	if err != nil {
+		return err
+	}
+	limit, err := revenueGroup.EffectiveLimit(t.tierAt)
+	if err != nil {
+		return err
+	}
+
+	netRevenue := NewMoney(NewDecimalFromInt(0), revenueGroup.CurrencyCode)
+	projected := NewMoney(NewDecimalFromInt(0), revenueGroup.CurrencyCode)
+	for _, m := range cluster.Members {
+		resellerID := MustParseResellerID(m.ResellerID)
+		reseller, err := t.handler.Resellers.GetByID(ctx, resellerID)
+		if err != nil {
+			return err
+		}
+		if reseller == nil {
+			return NewNotFoundError("Reseller", "ID", resellerID.String())
+		}
+
+		if reseller.CalculatedNetRevenueYearToDate != nil {
+			amount, err := t.convert(ctx, *reseller.CalculatedNetRevenueYearToDate, revenueGroup.CurrencyCode)
+			if err != nil {
+				return err
+			}
+			netRevenue.Amount = netRevenue.Amount.Add(amount.Amount)
+		}
+
+		resellerProjected, err := t.handler.Projection.Project(ctx, reseller, t.tierAt)
+		if err != nil {
+			return err
+		}
+		amount, err := t.convert(ctx, resellerProjected, revenueGroup.CurrencyCode)
+		if err != nil {
+			return err
+		}
+		projected.Amount = projected.Amount.Add(amount.Amount)
+	}
+
+	minimum, err := t.handler.MinimumTier.Minimum(revenueGroup, cluster.CalculatedNetRevenueLastYear, t.tierAt)
+	if err != nil {
+		return err
+	}
+
+	now := t.handler.Clock.NowUTC()
+	if err := cluster.Tier(netRevenue, limit.Limits.Tier(netRevenue.Amount), minimum, t.tierAt, now); err != nil {
+		return err
+	}
+	// Projected net revenue is compared with limits in effect today, as limits
+	// in effect at year end may yet change.
+	if err := cluster.Project(projected, limit.Limits.Tier(projected.Amount), t.tierAt, now); err != nil {
+		return err
+	}
+	return t.handler.Projector.Apply(ctx, cluster)
+}
+
+// convert uses the rates in effect on the tiering date rather than on booking
+// dates, so that a cluster's net revenue is comparable to its limits.
+func (t *tierer) convert(ctx context.Context, amount Money, to CurrencyCode) (Money, error) {
+	return t.clusterLookup.convert(ctx, amount, to, t.tierAt)
+}
+
+// clusterLookup caches revenue groups and currencies across clusters, as many
+// clusters share them.
+type clusterLookup struct {
+	*currencyConverter
+	revenueGroupStore RevenueGroupStore
+	revenueGroups     map[uuid.UUID]*RevenueGroup
+}
+
+func newClusterLookup(revenueGroups RevenueGroupStore, currencies CurrencyStore) *clusterLookup {
+	return &clusterLookup{
+		currencyConverter: newCurrencyConverter(currencies),
+		revenueGroupStore: revenueGroups,
+		revenueGroups:     map[uuid.UUID]*RevenueGroup{},
+	}
+}
+
+func (l *clusterLookup) revenueGroup(ctx context.Context, id uuid.UUID) (*RevenueGroup, error) {
+	if rg, ok := l.revenueGroups[id]; ok {
+		return rg, nil
+	}
+	revenueGroupID := MustParseRevenueGroupID(id)
+	rg, err := l.revenueGroupStore.GetByID(ctx, revenueGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if rg == nil {
+		return nil, NewNotFoundError("RevenueGroup", "ID", revenueGroupID.String())
+	}
+	l.revenueGroups[id] = rg
+	return rg, nil
+}
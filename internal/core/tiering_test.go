@@ -0,0 +1,67 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tierPtr(t ResellerTier) *ResellerTier { return &t }
+
+func TestParseMinimumTierPolicy(t *testing.T) {
+	for _, drop := range []int{0, 3} {
+		_, err := ParseMinimumTierPolicy(drop)
+		require.NoError(t, err)
+	}
+	for _, drop := range []int{-1, 4} {
+		_, err := ParseMinimumTierPolicy(drop)
+		require.Error(t, err)
+	}
+}
+
+func TestMinimumTierPolicyFloor(t *testing.T) {
+	tests := map[string]struct {
+		drop     int
+		lastYear *ResellerTier
+		expected *ResellerTier
+	}{
+		"no tier last year":    {0, nil, nil},
+		"keep premier":         {0, tierPtr(ResellerTierPremier), tierPtr(ResellerTierPremier)},
+		"premier drops one":    {1, tierPtr(ResellerTierPremier), tierPtr(ResellerTierAdvanced)},
+		"premier drops two":    {2, tierPtr(ResellerTierPremier), tierPtr(ResellerTierAuthorized)},
+		"authorized drops one": {1, tierPtr(ResellerTierAuthorized), nil},
+		"no protection":        {3, tierPtr(ResellerTierPremier), nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := MustParseMinimumTierPolicy(tt.drop)
+			assert.Equal(t, tt.expected, p.Floor(tt.lastYear))
+		})
+	}
+}
+
+func TestMinimumTierPolicyMinimum(t *testing.T) {
+	// Limits in effect at the end of last year apply, not those of this year.
+	rg := newTestRevenueGroupWithLimits("DK", map[Date][3]string{
+		NewDate(2025, 1, 1): {"100", "200", "300"},
+		NewDate(2026, 1, 1): {"1000", "2000", "3000"},
+	})
+	p := MustParseMinimumTierPolicy(1)
+	on := NewDate(2026, 3, 1)
+
+	minimum, err := p.Minimum(rg, nil, on)
+	require.NoError(t, err)
+	assert.Nil(t, minimum)
+
+	lastYear := NewMoney(MustParseDecimal("300"), MustParseCurrencyCode("DKK"))
+	minimum, err = p.Minimum(rg, &lastYear, on)
+	require.NoError(t, err)
+	assert.Equal(t, tierPtr(ResellerTierAdvanced), minimum)
+
+	// No limits were in effect at the end of 2024.
+	minimum, err = p.Minimum(rg, &lastYear, NewDate(2025, 3, 1))
+	require.NoError(t, err)
+	assert.Nil(t, minimum)
+}
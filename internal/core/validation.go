@@ -2,7 +2,6 @@ package core
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"uuid"
 )
@@ -89,23 +88,50 @@ func ValidateStringCurrencyCode(value string) error {
 	return nil
 }
 
-func ValidateFloat64InclusiveRange(value float64, min, max float64) error {
-	// TODO(rh): bug: pass in 1.123456789 and the error becomes ""Decimal places must be between 0 and 6 inclusive, but 1.123457 has 9"
+func ValidateStringCountryCode(value string) error {
+	_, ok := CountryCodes[value]
+	if !ok {
+		return fmt.Errorf("must be one of the allowed country codes, but was %s", value)
+	}
+	return nil
+}
+
+func ValidateStringInclusiveLength(value string, min, max int) error {
+	if len(value) < min || len(value) > max {
+		return fmt.Errorf("length must be between %d and %d inclusive, but %s has %d", min, max, value, len(value))
+	}
+	return nil
+}
+
+// ValidateStringCode accepts identifiers assigned by the business, such as a
+// product code, made up of upper case letters, digits, and hyphens.
+func ValidateStringCode(value string) error {
+	for _, r := range value {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("must contain only upper case letters A-Z, digits, and hyphens, but was %s", value)
+		}
+	}
+	return nil
+}
+
+func ValidateIntInclusiveRange(value int, min, max int) error {
 	if value < min || value > max {
-		return fmt.Errorf("must be between %g and %g inclusive, but was %g", min, max, value)
+		return fmt.Errorf("must be between %d and %d inclusive, but was %d", min, max, value)
 	}
 	return nil
 }
 
-func ValidateFloat64DecimalPlaces(value float64, min, max int) error {
-	s := strconv.FormatFloat(value, 'f', -1, 64)
-	i := strings.IndexByte(s, '.')
-	places := 0
-	if i != -1 {
-		places = len(s) - i - 1
+func ValidateDecimalInclusiveRange(value Decimal, min, max Decimal) error {
+	if value.LessThan(min) || value.GreaterThan(max) {
+		return fmt.Errorf("must be between %s and %s inclusive, but was %s", min, max, value)
 	}
+	return nil
+}
+
+func ValidateDecimalPlaces(value Decimal, min, max int) error {
+	places := int(value.Scale())
 	if places < min || places > max {
-		return fmt.Errorf("decimal places must be between %d and %d inclusive, but %f has %d", min, max, value, places)
+		return fmt.Errorf("decimal places must be between %d and %d inclusive, but %s has %d", min, max, value, places)
 	}
 	return nil
 }
@@ -36,6 +36,8 @@ func TestValidateStringCurrencyCode(t *testing.T) {
 		expected bool
 	}{
 		{"DKK", false},
+		{"SEK", false},
+		{"JPY", false},
 		{"ABC", true},
 	}
 
@@ -50,55 +52,135 @@ func TestValidateStringCurrencyCode(t *testing.T) {
 	}
 }
 
-func TestValidateFloat64InclusiveRange(t *testing.T) {
+func TestValidateStringCountryCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected bool
+	}{
+		{"DK", false},
+		{"JP", false},
+		{"AN", true},
+		{"DNK", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			err := ValidateStringCountryCode(tt.code)
+			if tt.expected {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.code)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateStringInclusiveLength(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"", true},
+		{"A", false},
+		{"ABCDEFGHIJ", false},
+		{"ABCDEFGHIJK", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := ValidateStringInclusiveLength(tt.value, 1, 10)
+			if tt.expected {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), fmt.Sprintf("%d", len(tt.value)))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateStringCode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"SW-100", false},
+		{"HW2", false},
+		{"sw-100", true},
+		{"SW 100", true},
+		{"SW_100", true},
+		{"SWÆ", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			err := ValidateStringCode(tt.value)
+			if tt.expected {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.value)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDecimalInclusiveRange(t *testing.T) {
 	tests := map[string]struct {
-		value    float64
-		min      float64
-		max      float64
+		value    string
+		min      string
+		max      string
 		expected bool
 	}{
-		"below":        {1.5, 2.0, 3.0, true},
-		"below equal":  {2.0, 2.0, 3.0, false},
-		"within":       {2.5, 2.0, 3.0, false},
-		"higher equal": {3.0, 2.0, 3.0, false},
-		"higher":       {3.5, 2.0, 3.0, true},
+		"below":        {"1.5", "2", "3", true},
+		"below equal":  {"2", "2", "3", false},
+		"within":       {"2.5", "2", "3", false},
+		"higher equal": {"3", "2", "3", false},
+		"higher":       {"3.5", "2", "3", true},
 	}
 
 	for name, tt := range tests {
 		t.Run(string(name), func(t *testing.T) {
-			err := ValidateFloat64InclusiveRange(tt.value, tt.min, tt.max)
+			err := ValidateDecimalInclusiveRange(MustParseDecimal(tt.value), MustParseDecimal(tt.min), MustParseDecimal(tt.max))
 			if tt.expected {
 				require.Error(t, err)
-				require.Contains(t, err.Error(), fmt.Sprintf("%g", tt.value))
-				require.Contains(t, err.Error(), fmt.Sprintf("%g", tt.min))
-				require.Contains(t, err.Error(), fmt.Sprintf("%g", tt.max))
+				require.Contains(t, err.Error(), tt.value)
+				require.Contains(t, err.Error(), tt.min)
+				require.Contains(t, err.Error(), tt.max)
+			} else {
+				require.NoError(t, err)
 			}
 		})
 	}
 }
 
-func TestValidateFloat64DecimalPlaces(t *testing.T) {
+func TestValidateDecimalPlaces(t *testing.T) {
 	tests := map[string]struct {
-		value    float64
+		value    string
 		min      int
 		max      int
 		places   int
 		expected bool
 	}{
-		"no decimals":  {1, 0, 0, 0, false},
-		"one decimal":  {1.1, 0, 1, 1, false},
-		"two decimals": {1.12, 0, 1, 2, true},
+		"no decimals":       {"1", 0, 0, 0, false},
+		"one decimal":       {"1.1", 0, 1, 1, false},
+		"two decimals":      {"1.12", 0, 1, 2, true},
+		"trailing zeros":    {"1.100", 0, 1, 1, false},
+		"not rounded first": {"1.123456789", 0, 6, 9, true},
 	}
 
 	for name, tt := range tests {
 		t.Run(string(name), func(t *testing.T) {
-			err := ValidateFloat64DecimalPlaces(tt.value, tt.min, tt.max)
+			err := ValidateDecimalPlaces(MustParseDecimal(tt.value), tt.min, tt.max)
 			if tt.expected {
 				require.Error(t, err)
-				require.Contains(t, err.Error(), fmt.Sprintf("%f", tt.value))
+				require.Contains(t, err.Error(), MustParseDecimal(tt.value).String())
 				require.Contains(t, err.Error(), fmt.Sprintf("%d", tt.min))
 				require.Contains(t, err.Error(), fmt.Sprintf("%d", tt.max))
 				require.Contains(t, err.Error(), fmt.Sprintf("%d", tt.places))
+			} else {
+				require.NoError(t, err)
 			}
 		})
 	}
@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ronnieholm/resellerloyalty/internal/core"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	DBUrl                string `mapstructure:"db_url"`
+	HTTPAddr             string `mapstructure:"http_addr"`
 	DailyTieringSchedule string `mapstructure:"daily_tiering_schedule"` // TODO(rh): make DailyTiering a subsection similar to OutboxProcessor.
-	OutboxProcessor      struct {
+	TierProjectionMethod string `mapstructure:"tier_projection_method"`
+	MinimumTierDrop      int    `mapstructure:"minimum_tier_drop"`
+	RolloverSchedule     string `mapstructure:"rollover_schedule"`
+	// IdempotencyKeyPurgeSchedule deletes idempotency keys past their TTL.
+	IdempotencyKeyPurgeSchedule string `mapstructure:"idempotency_key_purge_schedule"`
+	OutboxProcessor             struct {
 		BatchSize uint64 `mapstructure:"batch_size"`
 		Schedule  string `mapstructure:"schedule"`
 	} `mapstructure:"outbox_processor"`
@@ -37,14 +44,41 @@ func LoadConfig(path string) (Config, error) {
 	if c.DBUrl == "" {
 		return Config{}, fmt.Errorf("DB_URL is required")
 	}
+	if c.HTTPAddr == "" {
+		return Config{}, fmt.Errorf("HTTP_ADDR is required")
+	}
 	if c.DailyTieringSchedule == "" {
 		return Config{}, fmt.Errorf("DAILY_TIERING_SCHEDULE is required")
 	}
+	if _, err := ParseSchedule(c.DailyTieringSchedule); err != nil {
+		return Config{}, fmt.Errorf("DAILY_TIERING_SCHEDULE is invalid: %w", err)
+	}
+	if c.RolloverSchedule == "" {
+		return Config{}, fmt.Errorf("ROLLOVER_SCHEDULE is required")
+	}
+	if _, err := ParseSchedule(c.RolloverSchedule); err != nil {
+		return Config{}, fmt.Errorf("ROLLOVER_SCHEDULE is invalid: %w", err)
+	}
+	if c.IdempotencyKeyPurgeSchedule == "" {
+		return Config{}, fmt.Errorf("IDEMPOTENCY_KEY_PURGE_SCHEDULE is required")
+	}
+	if _, err := ParseSchedule(c.IdempotencyKeyPurgeSchedule); err != nil {
+		return Config{}, fmt.Errorf("IDEMPOTENCY_KEY_PURGE_SCHEDULE is invalid: %w", err)
+	}
+	if _, err := core.ParseTierProjectionMethod(c.TierProjectionMethod); err != nil {
+		return Config{}, fmt.Errorf("TIER_PROJECTION_METHOD is invalid: %w", err)
+	}
+	if _, err := core.ParseMinimumTierPolicy(c.MinimumTierDrop); err != nil {
+		return Config{}, fmt.Errorf("MINIMUM_TIER_DROP is invalid: %w", err)
+	}
 	if c.OutboxProcessor.BatchSize < 1 || c.OutboxProcessor.BatchSize > 1024 {
 		return Config{}, fmt.Errorf("OUTBOX_PROCESSOR_BATCH_SIZE must be between 1 and 1024")
 	}
-	if c.DailyTieringSchedule == "" {
+	if c.OutboxProcessor.Schedule == "" {
 		return Config{}, fmt.Errorf("OUTBOX_PROCESSOR_SCHEDULE is required")
 	}
+	if _, err := ParseSchedule(c.OutboxProcessor.Schedule); err != nil {
+		return Config{}, fmt.Errorf("OUTBOX_PROCESSOR_SCHEDULE is invalid: %w", err)
+	}
 	return c, nil
 }
@@ -2,9 +2,14 @@ package infrastructure
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"reflect"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -84,6 +89,113 @@ func WithLogging[Req any, Res any](next Handler[Req, Res]) Handler[Req, Res] {
 	}
 }
 
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey attaches a caller supplied key to ctx. Hosts, such as the
+// HTTP service, set it from the request and WithIdempotency picks it up. The
+// key isn't part of commands as it's a transport concern rather than a domain
+// one.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+func idempotencyKeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyCtx{}).(string)
+	return key, ok && key != ""
+}
+
+// IdempotencyKeyReusedError signals that a key was first used with a different
+// command or payload. Replaying the first outcome would be wrong, and
+// executing the command would defeat the purpose of the key.
+type IdempotencyKeyReusedError struct {
+	Key string
+}
+
+func (e *IdempotencyKeyReusedError) Error() string {
+	return fmt.Sprintf("idempotency key %s was used with a different request", e.Key)
+}
+
+// WithIdempotency replays the first recorded outcome of a command when called
+// again with the same idempotency key. Without a key in the context, the
+// command executes as usual.
+//
+// Recording the outcome happens in a transaction separate from the command's
+// own. Should the process die in between, the key stays pending and retries
+// are rejected as conflicting until the reservation's TTL passes. Then a retry
+// takes over the key and executes the command again. If the command took
+// effect before the process died, the retry fails like any repeated command,
+// e.g., with a conflict on a duplicate ID.
+//
+// A completed key replays its outcome until its TTL passes. Then the key is
+// taken over like an unused key, and purged on a schedule.
+func WithIdempotency[Req any, Res any](keys *PgIdempotencyStore, clock core.Clock, next Handler[Req, Res]) Handler[Req, Res] {
+	return func(ctx context.Context, r Req) (res Res, err error) {
+		key, ok := idempotencyKeyFrom(ctx)
+		if !ok {
+			return next(ctx, r)
+		}
+
+		b, err := json.Marshal(r)
+		if err != nil {
+			return res, fmt.Errorf("fingerprint %T: %w", r, err)
+		}
+		sum := sha256.Sum256(append([]byte(fmt.Sprintf("%T:", r)), b...))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		record, reserved, err := keys.Reserve(ctx, key, fingerprint, clock.NowUTC())
+		if err != nil {
+			return res, err
+		}
+		if !reserved {
+			if record.Fingerprint != fingerprint {
+				return res, &IdempotencyKeyReusedError{Key: key}
+			}
+			if record.Outcome == outcomePending {
+				return res, core.NewConflictError("IdempotencyKey", "Key", key)
+			}
+			return res, record.replay()
+		}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				_ = keys.Release(context.Background(), key)
+				panic(rec)
+			}
+		}()
+
+		res, err = next(ctx, r)
+		outcome, payload, recordable := classifyOutcome(err, expectsVersion(r))
+		if !recordable {
+			// Release even when the error is the context being canceled, or
+			// the key stays pending until its reservation's TTL passes.
+			if rErr := keys.Release(context.WithoutCancel(ctx), key); rErr != nil {
+				err = errors.Join(err, rErr)
+			}
+			return res, err
+		}
+
+		// The command has taken effect. Failing to record its outcome mustn't
+		// turn success into failure for the caller.
+		if cErr := keys.Complete(ctx, key, outcome, payload, clock.NowUTC()); cErr != nil {
+			slog.ErrorContext(ctx, "recording idempotency outcome failed",
+				slog.String("key", key),
+				slog.Any("error", cErr))
+		}
+		return res, err
+	}
+}
+
+// expectsVersion tells whether a command carries a caller supplied expected
+// version, i.e., a non-nil ExpectedVersion field.
+func expectsVersion(r any) bool {
+	v := reflect.Indirect(reflect.ValueOf(r))
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	f := v.FieldByName("ExpectedVersion")
+	return f.IsValid() && f.Kind() == reflect.Pointer && !f.IsNil()
+}
+
 // Decorate avoids repeating the common chain of decorators for every handler.
 func Decorate[Req any, Res any](h func(context.Context, Req) (Res, error)) Handler[Req, Res] {
 	handler := Handler[Req, Res](h)
@@ -94,6 +206,14 @@ func Decorate[Req any, Res any](h func(context.Context, Req) (Res, error)) Handl
 	return handler
 }
 
+// DecorateCommand is Decorate with idempotency innermost so that replayed
+// outcomes are still logged and timed. Queries are naturally idempotent and
+// don't need it.
+func DecorateCommand[Req any](keys *PgIdempotencyStore, clock core.Clock, h func(context.Context, Req) (Empty, error)) Handler[Req, Empty] {
+	handler := WithIdempotency(keys, clock, Handler[Req, Empty](h))
+	return Decorate(handler)
+}
+
 type DispatcherOption func(*dispatcherOptions)
 
 // DispatcherOptions is the dependencies for which substitution is supported in
@@ -126,13 +246,78 @@ type Dispatcher struct {
 	AddExchangeRate    Handler[core.AddExchangeRateCommand, Empty]
 	UpdateExchangeRate Handler[core.UpdateExchangeRateCommand, Empty]
 	RemoveExchangeRate Handler[core.RemoveExchangeRateCommand, Empty]
-	GetCurrency        Handler[core.GetCurrencyQuery, *core.CurrencyResponse]
+
+	ReplaceFutureExchangeRates Handler[core.ReplaceFutureExchangeRatesCommand, Empty]
+	ImportExchangeRates        Handler[core.ImportExchangeRatesCommand, *core.ImportExchangeRatesReport]
+
+	GetCurrency    Handler[core.GetCurrencyQuery, *core.CurrencyResponse]
+	ListCurrencies Handler[core.ListCurrenciesQuery, *core.ListCurrenciesResponse]
+
+	GetEffectiveExchangeRate Handler[core.GetEffectiveExchangeRateQuery, *core.ExchangeRateResponse]
+	ConvertMoney             Handler[core.ConvertMoneyQuery, *core.Money]
+	GetExchangeRateTimeline  Handler[core.GetExchangeRateTimelineQuery, *core.ExchangeRateTimelineResponse]
 
 	// TierDiscount
-	CreateTierDiscount Handler[core.CreateTierDiscountCommand, Empty]
-	UpdateTierDiscount Handler[core.UpdateTierDiscountCommand, Empty]
-	RemoveTierDiscount Handler[core.RemoveTierDiscountCommand, Empty]
-	GetTierDiscount    Handler[core.GetTierDiscountQuery, *core.TierDiscount]
+	CreateTierDiscount       Handler[core.CreateTierDiscountCommand, Empty]
+	UpdateTierDiscount       Handler[core.UpdateTierDiscountCommand, Empty]
+	RemoveTierDiscount       Handler[core.RemoveTierDiscountCommand, Empty]
+	GetTierDiscount          Handler[core.GetTierDiscountQuery, *core.TierDiscountResponse]
+	ListTierDiscounts        Handler[core.ListTierDiscountsQuery, *core.ListTierDiscountsResponse]
+	GetEffectiveTierDiscount Handler[core.GetEffectiveTierDiscountQuery, *core.TierDiscountResponse]
+	GetTierDiscountTimeline  Handler[core.GetTierDiscountTimelineQuery, *core.TierDiscountTimelineResponse]
+
+	// Product
+	CreateProduct        Handler[core.CreateProductCommand, Empty]
+	AssignProductGroup   Handler[core.AssignProductGroupCommand, Empty]
+	UnassignProductGroup Handler[core.UnassignProductGroupCommand, Empty]
+	RemoveProduct        Handler[core.RemoveProductCommand, Empty]
+	GetProduct           Handler[core.GetProductQuery, *core.ProductResponse]
+	ListProducts         Handler[core.ListProductsQuery, *core.ListProductsResponse]
+
+	// ProductGroup
+	CreateProductGroup             Handler[core.CreateProductGroupCommand, Empty]
+	RemoveProductGroup             Handler[core.RemoveProductGroupCommand, Empty]
+	AddProductGroupWeight          Handler[core.AddProductGroupWeightCommand, Empty]
+	UpdateProductGroupWeight       Handler[core.UpdateProductGroupWeightCommand, Empty]
+	RemoveProductGroupWeight       Handler[core.RemoveProductGroupWeightCommand, Empty]
+	GetProductGroup                Handler[core.GetProductGroupQuery, *core.ProductGroupResponse]
+	ListProductGroups              Handler[core.ListProductGroupsQuery, *core.ListProductGroupsResponse]
+	GetEffectiveProductGroupWeight Handler[core.GetEffectiveProductGroupWeightQuery, *core.ProductGroupWeightResponse]
+
+	// RevenueGroup
+	CreateRevenueGroup            Handler[core.CreateRevenueGroupCommand, Empty]
+	AddRevenueGroupLimit          Handler[core.AddRevenueGroupLimitCommand, Empty]
+	UpdateRevenueGroupLimit       Handler[core.UpdateRevenueGroupLimitCommand, Empty]
+	RemoveRevenueGroupLimit       Handler[core.RemoveRevenueGroupLimitCommand, Empty]
+	GetRevenueGroup               Handler[core.GetRevenueGroupQuery, *core.RevenueGroupResponse]
+	GetEffectiveRevenueGroupLimit Handler[core.GetEffectiveRevenueGroupLimitQuery, *core.RevenueGroupLimitResponse]
+
+	// Reseller
+	EnrollReseller          Handler[core.EnrollResellerCommand, Empty]
+	ChangeResellerCurrency  Handler[core.ChangeResellerCurrencyCommand, Empty]
+	UnenrollReseller        Handler[core.UnenrollResellerCommand, Empty]
+	RecordBilling           Handler[core.RecordBillingCommand, Empty]
+	GetReseller             Handler[core.GetResellerQuery, *core.ResellerResponse]
+	GetResellerByExternalID Handler[core.GetResellerByExternalIDQuery, *core.ResellerResponse]
+
+	// Cluster
+	CreateCluster            Handler[core.CreateClusterCommand, Empty]
+	AddClusterMember         Handler[core.AddClusterMemberCommand, Empty]
+	RemoveClusterMember      Handler[core.RemoveClusterMemberCommand, Empty]
+	ChangeClusterHead        Handler[core.ChangeClusterHeadCommand, Empty]
+	RemoveCluster            Handler[core.RemoveClusterCommand, Empty]
+	GetCluster               Handler[core.GetClusterQuery, *core.ClusterResponse]
+	GetClusterTierProjection Handler[core.GetClusterTierProjectionQuery, *core.ClusterTierProjectionResponse]
+
+	// Tiering
+	TierClusters Handler[core.TierClustersCommand, *core.TieringReport]
+
+	// Rollover
+	RollOverYear Handler[core.RollOverYearCommand, *core.RolloverReport]
+
+	// DiscountQuote
+	GetDiscountQuote  Handler[core.GetDiscountQuoteQuery, *core.DiscountQuoteResponse]
+	GetDiscountQuotes Handler[core.GetDiscountQuotesQuery, *core.DiscountQuotesResponse]
 }
 
 func NewDispatcher(ctx context.Context, config Config, opts ...DispatcherOption) Dispatcher {
@@ -174,9 +359,33 @@ func NewDispatcher(ctx context.Context, config Config, opts ...DispatcherOption)
 	tierDiscountStore := &PgTierDiscountStore{
 		Pool: pool,
 	}
+	productStore := &PgProductStore{
+		Pool: pool,
+	}
+	productGroupStore := &PgProductGroupStore{
+		Pool: pool,
+	}
+	resellerStore := &PgResellerStore{
+		Pool: pool,
+	}
+	revenueGroupStore := &PgRevenueGroupStore{
+		Pool: pool,
+	}
+	clusterStore := &PgClusterStore{
+		Pool: pool,
+	}
+	tieringStore := &PgTieringStore{
+		Pool: pool,
+	}
+	rolloverStore := &PgRolloverStore{
+		Pool: pool,
+	}
 	projector := &PgStoreProjector{
 		Pool: pool,
 	}
+	idempotencyStore := &PgIdempotencyStore{
+		Pool: pool,
+	}
 
 	// The benefit of setting up dependencies before any calls are dispatched is
 	// that allocations are kept to a minimum across the lifetime of the
@@ -218,9 +427,33 @@ func NewDispatcher(ctx context.Context, config Config, opts ...DispatcherOption)
 		Projector:  projector,
 		Clock:      o.clock,
 	}
+	replaceFutureExchangeRates := core.ReplaceFutureExchangeRatesHandler{
+		Currencies: currencyStore,
+		Projector:  projector,
+		Clock:      o.clock,
+	}
+	importExchangeRates := core.ImportExchangeRatesHandler{
+		Currencies: currencyStore,
+		Projector:  projector,
+		Clock:      o.clock,
+	}
 	getCurrency := core.GetCurrencyHandler{
 		Currencies: currencyStore,
 	}
+	listCurrencies := core.ListCurrenciesHandler{
+		Currencies: currencyStore,
+		Clock:      o.clock,
+	}
+	getEffectiveExchangeRate := core.GetEffectiveExchangeRateHandler{
+		Currencies: currencyStore,
+	}
+	convertMoney := core.ConvertMoneyHandler{
+		Currencies: currencyStore,
+	}
+	getExchangeRateTimeline := core.GetExchangeRateTimelineHandler{
+		Currencies: currencyStore,
+		Clock:      o.clock,
+	}
 
 	// TierDiscount
 	createTierDiscount := core.CreateTierDiscountHandler{
@@ -241,6 +474,230 @@ func NewDispatcher(ctx context.Context, config Config, opts ...DispatcherOption)
 	getTierDiscount := core.GetTierDiscountHandler{
 		TierDiscounts: tierDiscountStore,
 	}
+	listTierDiscounts := core.ListTierDiscountsHandler{
+		TierDiscounts: tierDiscountStore,
+	}
+	getEffectiveTierDiscount := core.GetEffectiveTierDiscountHandler{
+		TierDiscounts: tierDiscountStore,
+	}
+	getTierDiscountTimeline := core.GetTierDiscountTimelineHandler{
+		TierDiscounts: tierDiscountStore,
+		Clock:         o.clock,
+	}
+
+	// Product
+	createProduct := core.CreateProductHandler{
+		Products:  productStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	assignProductGroup := core.AssignProductGroupHandler{
+		Products:      productStore,
+		ProductGroups: productGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	unassignProductGroup := core.UnassignProductGroupHandler{
+		Products:  productStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	removeProduct := core.RemoveProductHandler{
+		Products:  productStore,
+		Resellers: resellerStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	getProduct := core.GetProductHandler{
+		Products: productStore,
+	}
+	listProducts := core.ListProductsHandler{
+		Products: productStore,
+	}
+
+	// ProductGroup
+	createProductGroup := core.CreateProductGroupHandler{
+		ProductGroups: productGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	removeProductGroup := core.RemoveProductGroupHandler{
+		ProductGroups: productGroupStore,
+		Products:      productStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	addProductGroupWeight := core.AddProductGroupWeightHandler{
+		ProductGroups: productGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	updateProductGroupWeight := core.UpdateProductGroupWeightHandler{
+		ProductGroups: productGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	removeProductGroupWeight := core.RemoveProductGroupWeightHandler{
+		ProductGroups: productGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	getProductGroup := core.GetProductGroupHandler{
+		ProductGroups: productGroupStore,
+	}
+	listProductGroups := core.ListProductGroupsHandler{
+		ProductGroups: productGroupStore,
+	}
+	getEffectiveProductGroupWeight := core.GetEffectiveProductGroupWeightHandler{
+		ProductGroups: productGroupStore,
+	}
+
+	// RevenueGroup
+	createRevenueGroup := core.CreateRevenueGroupHandler{
+		RevenueGroups: revenueGroupStore,
+		Currencies:    currencyStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	addRevenueGroupLimit := core.AddRevenueGroupLimitHandler{
+		RevenueGroups: revenueGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	updateRevenueGroupLimit := core.UpdateRevenueGroupLimitHandler{
+		RevenueGroups: revenueGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	removeRevenueGroupLimit := core.RemoveRevenueGroupLimitHandler{
+		RevenueGroups: revenueGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	getRevenueGroup := core.GetRevenueGroupHandler{
+		RevenueGroups: revenueGroupStore,
+	}
+	getEffectiveRevenueGroupLimit := core.GetEffectiveRevenueGroupLimitHandler{
+		RevenueGroups: revenueGroupStore,
+	}
+
+	// Reseller
+	enrollReseller := core.EnrollResellerHandler{
+		Resellers:  resellerStore,
+		Currencies: currencyStore,
+		Projector:  projector,
+		Clock:      o.clock,
+	}
+	changeResellerCurrency := core.ChangeResellerCurrencyHandler{
+		Resellers:  resellerStore,
+		Currencies: currencyStore,
+		Projector:  projector,
+		Clock:      o.clock,
+	}
+	unenrollReseller := core.UnenrollResellerHandler{
+		Resellers: resellerStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	recordBilling := core.RecordBillingHandler{
+		Resellers:  resellerStore,
+		Clusters:   clusterStore,
+		Currencies: currencyStore,
+		NetRevenue: core.NetRevenueCalculator{
+			Products:      productStore,
+			ProductGroups: productGroupStore,
+		},
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	getReseller := core.GetResellerHandler{
+		Resellers: resellerStore,
+	}
+	getResellerByExternalID := core.GetResellerByExternalIDHandler{
+		Resellers: resellerStore,
+	}
+
+	// Cluster
+	createCluster := core.CreateClusterHandler{
+		Clusters:      clusterStore,
+		Resellers:     resellerStore,
+		RevenueGroups: revenueGroupStore,
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+	addClusterMember := core.AddClusterMemberHandler{
+		Clusters:  clusterStore,
+		Resellers: resellerStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	removeClusterMember := core.RemoveClusterMemberHandler{
+		Clusters:  clusterStore,
+		Resellers: resellerStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	changeClusterHead := core.ChangeClusterHeadHandler{
+		Clusters:  clusterStore,
+		Resellers: resellerStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	removeCluster := core.RemoveClusterHandler{
+		Clusters:  clusterStore,
+		Resellers: resellerStore,
+		Projector: projector,
+		Clock:     o.clock,
+	}
+	getCluster := core.GetClusterHandler{
+		Clusters: clusterStore,
+	}
+	getClusterTierProjection := core.GetClusterTierProjectionHandler{
+		Clusters: clusterStore,
+	}
+
+	// Tiering
+	tierClusters := core.TierClustersHandler{
+		Tierings:      tieringStore,
+		Clusters:      clusterStore,
+		Resellers:     resellerStore,
+		RevenueGroups: revenueGroupStore,
+		Currencies:    currencyStore,
+		Projection: core.NetRevenueProjection{
+			Method:     core.MustParseTierProjectionMethod(config.TierProjectionMethod),
+			Resellers:  resellerStore,
+			Currencies: currencyStore,
+		},
+		MinimumTier: core.MustParseMinimumTierPolicy(config.MinimumTierDrop),
+		Projector:   projector,
+		Clock:       o.clock,
+	}
+
+	// Rollover
+	rollOverYear := core.RollOverYearHandler{
+		Rollovers:     rolloverStore,
+		Resellers:     resellerStore,
+		Clusters:      clusterStore,
+		RevenueGroups: revenueGroupStore,
+		Currencies:    currencyStore,
+		MinimumTier:   core.MustParseMinimumTierPolicy(config.MinimumTierDrop),
+		Projector:     projector,
+		Clock:         o.clock,
+	}
+
+	// DiscountQuote
+	getDiscountQuote := core.GetDiscountQuoteHandler{
+		Resellers:     resellerStore,
+		Clusters:      clusterStore,
+		Products:      productStore,
+		TierDiscounts: tierDiscountStore,
+	}
+	getDiscountQuotes := core.GetDiscountQuotesHandler{
+		Resellers:     resellerStore,
+		Clusters:      clusterStore,
+		Products:      productStore,
+		TierDiscounts: tierDiscountStore,
+	}
 
 	return Dispatcher{
 		PgxPool: pool,
@@ -251,37 +708,160 @@ func NewDispatcher(ctx context.Context, config Config, opts ...DispatcherOption)
 		// patch the signature as below. To avoid polluting core, (2) is chosen.
 
 		// Currency
-		CreateCurrency: Decorate(func(ctx context.Context, req core.CreateCurrencyCommand) (Empty, error) {
+		CreateCurrency: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.CreateCurrencyCommand) (Empty, error) {
 			return Empty{}, createCurrency.Handle(ctx, req)
 		}),
-		RemoveCurrency: Decorate(func(ctx context.Context, req core.RemoveCurrencyCommand) (Empty, error) {
+		RemoveCurrency: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveCurrencyCommand) (Empty, error) {
 			return Empty{}, removeCurrency.Handle(ctx, req)
 		}),
-		AddExchangeRate: Decorate(func(ctx context.Context, req core.AddExchangeRateCommand) (Empty, error) {
+		AddExchangeRate: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.AddExchangeRateCommand) (Empty, error) {
 			return Empty{}, addExchangeRate.Handle(ctx, req)
 		}),
-		UpdateExchangeRate: Decorate(func(ctx context.Context, req core.UpdateExchangeRateCommand) (Empty, error) {
+		UpdateExchangeRate: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.UpdateExchangeRateCommand) (Empty, error) {
 			return Empty{}, updateExchangeRate.Handle(ctx, req)
 		}),
-		RemoveExchangeRate: Decorate(func(ctx context.Context, req core.RemoveExchangeRateCommand) (Empty, error) {
+		RemoveExchangeRate: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveExchangeRateCommand) (Empty, error) {
 			return Empty{}, removeExchangeRate.Handle(ctx, req)
 		}),
-		GetCurrency: Decorate(getCurrency.Handle),
+		ReplaceFutureExchangeRates: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.ReplaceFutureExchangeRatesCommand) (Empty, error) {
+			return Empty{}, replaceFutureExchangeRates.Handle(ctx, req)
+		}),
+		// Unlike other commands, import returns a report, and as it's run from
+		// the command line, an idempotency key has no use.
+		ImportExchangeRates: Decorate(importExchangeRates.Handle),
+		GetCurrency:         Decorate(getCurrency.Handle),
+		ListCurrencies:      Decorate(listCurrencies.Handle),
+
+		GetEffectiveExchangeRate: Decorate(getEffectiveExchangeRate.Handle),
+		ConvertMoney:             Decorate(convertMoney.Handle),
+		GetExchangeRateTimeline:  Decorate(getExchangeRateTimeline.Handle),
 
 		// TierDiscount
-		CreateTierDiscount: Decorate(func(ctx context.Context, req core.CreateTierDiscountCommand) (Empty, error) {
+		CreateTierDiscount: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.CreateTierDiscountCommand) (Empty, error) {
 			return Empty{}, createTierDiscount.Handle(ctx, req)
 		}),
-		RemoveTierDiscount: Decorate(func(ctx context.Context, req core.RemoveTierDiscountCommand) (Empty, error) {
+		RemoveTierDiscount: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveTierDiscountCommand) (Empty, error) {
 			return Empty{}, removeTierDiscount.Handle(ctx, req)
 		}),
-		UpdateTierDiscount: Decorate(func(ctx context.Context, req core.UpdateTierDiscountCommand) (Empty, error) {
+		UpdateTierDiscount: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.UpdateTierDiscountCommand) (Empty, error) {
 			return Empty{}, updateTierDiscount.Handle(ctx, req)
 		}),
-		GetTierDiscount: Decorate(getTierDiscount.Handle),
+		GetTierDiscount:          Decorate(getTierDiscount.Handle),
+		ListTierDiscounts:        Decorate(listTierDiscounts.Handle),
+		GetEffectiveTierDiscount: Decorate(getEffectiveTierDiscount.Handle),
+		GetTierDiscountTimeline:  Decorate(getTierDiscountTimeline.Handle),
+
+		// Product
+		CreateProduct: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.CreateProductCommand) (Empty, error) {
+			return Empty{}, createProduct.Handle(ctx, req)
+		}),
+		AssignProductGroup: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.AssignProductGroupCommand) (Empty, error) {
+			return Empty{}, assignProductGroup.Handle(ctx, req)
+		}),
+		UnassignProductGroup: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.UnassignProductGroupCommand) (Empty, error) {
+			return Empty{}, unassignProductGroup.Handle(ctx, req)
+		}),
+		RemoveProduct: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveProductCommand) (Empty, error) {
+			return Empty{}, removeProduct.Handle(ctx, req)
+		}),
+		GetProduct:   Decorate(getProduct.Handle),
+		ListProducts: Decorate(listProducts.Handle),
+
+		// ProductGroup
+		CreateProductGroup: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.CreateProductGroupCommand) (Empty, error) {
+			return Empty{}, createProductGroup.Handle(ctx, req)
+		}),
+		RemoveProductGroup: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveProductGroupCommand) (Empty, error) {
+			return Empty{}, removeProductGroup.Handle(ctx, req)
+		}),
+		AddProductGroupWeight: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.AddProductGroupWeightCommand) (Empty, error) {
+			return Empty{}, addProductGroupWeight.Handle(ctx, req)
+		}),
+		UpdateProductGroupWeight: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.UpdateProductGroupWeightCommand) (Empty, error) {
+			return Empty{}, updateProductGroupWeight.Handle(ctx, req)
+		}),
+		RemoveProductGroupWeight: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveProductGroupWeightCommand) (Empty, error) {
+			return Empty{}, removeProductGroupWeight.Handle(ctx, req)
+		}),
+		GetProductGroup:                Decorate(getProductGroup.Handle),
+		ListProductGroups:              Decorate(listProductGroups.Handle),
+		GetEffectiveProductGroupWeight: Decorate(getEffectiveProductGroupWeight.Handle),
+
+		// RevenueGroup
+		CreateRevenueGroup: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.CreateRevenueGroupCommand) (Empty, error) {
+			return Empty{}, createRevenueGroup.Handle(ctx, req)
+		}),
+		AddRevenueGroupLimit: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.AddRevenueGroupLimitCommand) (Empty, error) {
+			return Empty{}, addRevenueGroupLimit.Handle(ctx, req)
+		}),
+		UpdateRevenueGroupLimit: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.UpdateRevenueGroupLimitCommand) (Empty, error) {
+			return Empty{}, updateRevenueGroupLimit.Handle(ctx, req)
+		}),
+		RemoveRevenueGroupLimit: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveRevenueGroupLimitCommand) (Empty, error) {
+			return Empty{}, removeRevenueGroupLimit.Handle(ctx, req)
+		}),
+		GetRevenueGroup:               Decorate(getRevenueGroup.Handle),
+		GetEffectiveRevenueGroupLimit: Decorate(getEffectiveRevenueGroupLimit.Handle),
+
+		// Reseller
+		EnrollReseller: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.EnrollResellerCommand) (Empty, error) {
+			return Empty{}, enrollReseller.Handle(ctx, req)
+		}),
+		ChangeResellerCurrency: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.ChangeResellerCurrencyCommand) (Empty, error) {
+			return Empty{}, changeResellerCurrency.Handle(ctx, req)
+		}),
+		UnenrollReseller: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.UnenrollResellerCommand) (Empty, error) {
+			return Empty{}, unenrollReseller.Handle(ctx, req)
+		}),
+		RecordBilling: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RecordBillingCommand) (Empty, error) {
+			return Empty{}, recordBilling.Handle(ctx, req)
+		}),
+		GetReseller:             Decorate(getReseller.Handle),
+		GetResellerByExternalID: Decorate(getResellerByExternalID.Handle),
+
+		// Cluster
+		CreateCluster: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.CreateClusterCommand) (Empty, error) {
+			return Empty{}, createCluster.Handle(ctx, req)
+		}),
+		AddClusterMember: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.AddClusterMemberCommand) (Empty, error) {
+			return Empty{}, addClusterMember.Handle(ctx, req)
+		}),
+		RemoveClusterMember: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveClusterMemberCommand) (Empty, error) {
+			return Empty{}, removeClusterMember.Handle(ctx, req)
+		}),
+		ChangeClusterHead: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.ChangeClusterHeadCommand) (Empty, error) {
+			return Empty{}, changeClusterHead.Handle(ctx, req)
+		}),
+		RemoveCluster: DecorateCommand(idempotencyStore, o.clock, func(ctx context.Context, req core.RemoveClusterCommand) (Empty, error) {
+			return Empty{}, removeCluster.Handle(ctx, req)
+		}),
+		GetCluster:               Decorate(getCluster.Handle),
+		GetClusterTierProjection: Decorate(getClusterTierProjection.Handle),
+
+		// Tiering
+		// Like import, tiering returns a report and is run on a schedule
+		// rather than by a caller with an idempotency key. Tiering at most
+		// once per date makes it idempotent regardless.
+		TierClusters: Decorate(tierClusters.Handle),
+
+		// Rollover
+		// Like tiering, rolling over at most once per fiscal year makes it
+		// idempotent.
+		RollOverYear: Decorate(rollOverYear.Handle),
+
+		// DiscountQuote
+		GetDiscountQuote:  Decorate(getDiscountQuote.Handle),
+		GetDiscountQuotes: Decorate(getDiscountQuotes.Handle),
 	}
 }
 
+// PurgeIdempotencyKeys deletes idempotency keys past their TTL. It's run on a
+// schedule rather than dispatched as a command.
+func (d *Dispatcher) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	keys := PgIdempotencyStore{Pool: d.PgxPool}
+	return keys.Purge(ctx, d.clock.NowUTC())
+}
+
 func (d *Dispatcher) Close() {
 	d.PgxPool.Close()
 }
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"fmt"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyOutcome(t *testing.T) {
+	stale := core.NewDataStaleError("Currency", uuid.Nil())
+	tests := map[string]struct {
+		err            error
+		expectsVersion bool
+		outcome        string
+		recordable     bool
+	}{
+		"success":                    {nil, false, outcomeSuccess, true},
+		"conflict":                   {core.NewConflictError("Currency", "Code", "USD"), false, outcomeConflict, true},
+		"domain":                     {core.NewDomainError(core.CurrencyAddRequiresFutureFrom, "x"), false, outcomeDomain, true},
+		"stale with version":         {stale, true, outcomeDataStale, true},
+		"stale without version":      {stale, false, "", false},
+		"wrapped stale with version": {fmt.Errorf("wrap: %w", stale), true, outcomeDataStale, true},
+		"unexpected":                 {fmt.Errorf("connection refused"), true, "", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			outcome, _, recordable := classifyOutcome(tt.err, tt.expectsVersion)
+			assert.Equal(t, tt.recordable, recordable)
+			assert.Equal(t, tt.outcome, outcome)
+		})
+	}
+}
+
+func TestExpectsVersion(t *testing.T) {
+	version := int32(1)
+	tests := map[string]struct {
+		req      any
+		expected bool
+	}{
+		"with version":     {core.UpdateExchangeRateCommand{ExpectedVersion: &version}, true},
+		"without version":  {core.UpdateExchangeRateCommand{}, false},
+		"no version field": {core.CreateCurrencyCommand{}, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, expectsVersion(tt.req))
+		})
+	}
+}
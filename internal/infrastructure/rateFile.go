@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+)
+
+// Rate file readers only extract rows as text. Parsing and validating rows is
+// left to core.ImportExchangeRatesHandler so that a malformed row is reported
+// rather than failing the file. Only a file that can't be read as its format
+// is an error.
+
+// ReadECBExchangeRates reads the ECB eurofxref format:
+//
+//	<gesmes:Envelope>
+//	  <Cube>
+//	    <Cube time="2026-10-15">
+//	      <Cube currency="USD" rate="1.0856"/>
+//	    </Cube>
+//	  </Cube>
+//	</gesmes:Envelope>
+//
+// Daily, 90 day, and historical files share the format, differing only in the
+// number of time elements. ECB quotes rates as units of the currency per EUR,
+// so rows are marked as inverse and EUR is taken to be the reporting currency.
+func ReadECBExchangeRates(r io.Reader) ([]core.ExchangeRateImportRow, error) {
+	dec := xml.NewDecoder(r)
+	var (
+		rows []core.ExchangeRateImportRow
+		from string
+	)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read ecb xml: %w", err)
+		}
+
+		el, ok := tok.(xml.StartElement)
+		if !ok || el.Name.Local != "Cube" {
+			continue
+		}
+		attrs := make(map[string]string, len(el.Attr))
+		for _, a := range el.Attr {
+			attrs[a.Name.Local] = a.Value
+		}
+		if t, ok := attrs["time"]; ok {
+			from = t
+			continue
+		}
+		code, ok := attrs["currency"]
+		if !ok {
+			continue
+		}
+		line, _ := dec.InputPos()
+		rows = append(rows, core.ExchangeRateImportRow{
+			Line:    line,
+			ID:      uuid.NewV7(),
+			Code:    code,
+			Rate:    attrs["rate"],
+			From:    from,
+			Inverse: true,
+		})
+	}
+}
+
+// ReadCSVExchangeRates reads rows of code, rate, and from, e.g.,
+//
+//	code,rate,from
+//	USD,0.9211,2026-10-15
+//
+// Unlike in ECB files, a rate is the value of one unit of the currency in the
+// reporting currency. The header row is optional.
+func ReadCSVExchangeRates(r io.Reader) ([]core.ExchangeRateImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var rows []core.ExchangeRateImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if line == 1 && strings.EqualFold(record[0], "code") {
+			continue
+		}
+		rows = append(rows, core.ExchangeRateImportRow{
+			Line: line,
+			ID:   uuid.NewV7(),
+			Code: record[0],
+			Rate: record[1],
+			From: record[2],
+		})
+	}
+}
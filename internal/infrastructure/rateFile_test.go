@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadECBExchangeRates(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-10-15">
+			<Cube currency="USD" rate="1.0856"/>
+			<Cube currency="DKK" rate="7.4603"/>
+		</Cube>
+		<Cube time="2026-10-14">
+			<Cube currency="USD" rate="1.0848"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+	rows, err := ReadECBExchangeRates(strings.NewReader(xml))
+
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, 6, rows[0].Line)
+	assert.Equal(t, "USD", rows[0].Code)
+	assert.Equal(t, "1.0856", rows[0].Rate)
+	assert.Equal(t, "2026-10-15", rows[0].From)
+	assert.True(t, rows[0].Inverse)
+	assert.Equal(t, "DKK", rows[1].Code)
+	assert.Equal(t, "2026-10-15", rows[1].From)
+	assert.Equal(t, "2026-10-14", rows[2].From)
+	assert.NotEqual(t, rows[0].ID, rows[1].ID)
+}
+
+func TestReadECBExchangeRatesMalformed(t *testing.T) {
+	_, err := ReadECBExchangeRates(strings.NewReader(`<Cube><Cube time="2026-10-15">`))
+	require.Error(t, err)
+}
+
+func TestReadCSVExchangeRates(t *testing.T) {
+	tests := map[string]struct {
+		csv   string
+		lines []int
+	}{
+		"header":    {"code,rate,from\nUSD,1.0856,2026-10-15\nDKK, 7.4603, 2026-10-15\n", []int{2, 3}},
+		"no header": {"USD,1.0856,2026-10-15\n", []int{1}},
+		"comment":   {"# ECB\nUSD,1.0856,2026-10-15\n", []int{2}},
+		"invalid":   {"USD,abc,2026-13-01\n", []int{1}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rows, err := ReadCSVExchangeRates(strings.NewReader(tt.csv))
+			require.NoError(t, err)
+			require.Len(t, rows, len(tt.lines))
+			for i, line := range tt.lines {
+				assert.Equal(t, line, rows[i].Line)
+			}
+		})
+	}
+}
+
+func TestReadCSVExchangeRatesFieldCount(t *testing.T) {
+	_, err := ReadCSVExchangeRates(strings.NewReader("USD,1.0856\n"))
+	require.Error(t, err)
+}
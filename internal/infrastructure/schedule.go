@@ -0,0 +1,181 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a cron expression with a leading seconds field:
+//
+//	second minute hour day-of-month month day-of-week
+//
+// A field is *, a value, a range a-b, or a list of those separated by commas.
+// * and ranges may be followed by a step /n. Like cron, when both day fields
+// are restricted, a time matches either of them. Times are in UTC.
+type Schedule struct {
+	second, minute, hour, dom, month, dow uint64
+	domStar, dowStar                      bool
+}
+
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = []scheduleField{
+	{"second", 0, 59},
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day-of-month", 1, 31},
+	{"month", 1, 12},
+	{"day-of-week", 0, 6},
+}
+
+func ParseSchedule(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(scheduleFields) {
+		return Schedule{}, fmt.Errorf("schedule %q must have %d fields, but had %d", spec, len(scheduleFields), len(fields))
+	}
+
+	bitsets := make([]uint64, len(fields))
+	for i, f := range fields {
+		b, err := parseScheduleField(f, scheduleFields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: %w", spec, err)
+		}
+		bitsets[i] = b
+	}
+	return Schedule{
+		second:  bitsets[0],
+		minute:  bitsets[1],
+		hour:    bitsets[2],
+		dom:     bitsets[3],
+		month:   bitsets[4],
+		dow:     bitsets[5],
+		domStar: strings.HasPrefix(fields[3], "*"),
+		dowStar: strings.HasPrefix(fields[5], "*"),
+	}, nil
+}
+
+func parseScheduleField(v string, f scheduleField) (uint64, error) {
+	var b uint64
+	for part := range strings.SplitSeq(v, ",") {
+		expr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepExpr)
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("%s step must be a positive number, but was %s", f.name, stepExpr)
+			}
+			step = s
+		}
+
+		var lo, hi int
+		switch {
+		case expr == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(expr, "-"):
+			loExpr, hiExpr, _ := strings.Cut(expr, "-")
+			var err error
+			if lo, err = parseScheduleValue(loExpr, f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseScheduleValue(hiExpr, f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s range must be ascending, but was %s", f.name, expr)
+			}
+		default:
+			if hasStep {
+				return 0, fmt.Errorf("%s step requires * or a range, but was %s", f.name, part)
+			}
+			n, err := parseScheduleValue(expr, f)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = n, n
+		}
+
+		for i := lo; i <= hi; i += step {
+			b |= 1 << i
+		}
+	}
+	return b, nil
+}
+
+func parseScheduleValue(v string, f scheduleField) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("%s must be between %d and %d, but was %s", f.name, f.min, f.max, v)
+	}
+	return n, nil
+}
+
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first time after after that matches the schedule, or the
+// zero time if none does within five years, e.g., for February 30th.
+func (s Schedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(5, 0, 0)
+
+	// Rather than stepping a second at a time, skip ahead to the start of the
+	// next month, day, hour, or minute when that field doesn't match.
+	for t.Before(limit) {
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if s.second&(1<<t.Second()) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// RunSchedule runs job at every time matching schedule until ctx is done. A
+// job runs to completion before the next time is computed, so runs never
+// overlap, and a run that takes longer than the interval skips the times it
+// missed.
+func RunSchedule(ctx context.Context, schedule Schedule, job func(context.Context)) error {
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule has no next run")
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+			job(ctx)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleInvalid(t *testing.T) {
+	tests := map[string]string{
+		"too few fields":     "0 * * * *",
+		"too many fields":    "0 0 * * * * *",
+		"second above max":   "60 * * * * *",
+		"day below min":      "0 0 0 0 * *",
+		"descending range":   "0 0 5-1 * * *",
+		"zero step":          "*/0 * * * * *",
+		"step without range": "0/5 * * * * *",
+		"not a number":       "a * * * * *",
+	}
+
+	for name, spec := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSchedule(spec)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2026-10-16 is a Friday.
+	after := time.Date(2026, 10, 16, 10, 30, 15, 500, time.UTC)
+
+	tests := map[string]struct {
+		spec     string
+		expected time.Time
+	}{
+		"every minute":            {"0 */1 * * * *", time.Date(2026, 10, 16, 10, 31, 0, 0, time.UTC)},
+		"every second":            {"* * * * * *", time.Date(2026, 10, 16, 10, 30, 16, 0, time.UTC)},
+		"daily later today":       {"0 0 12 * * *", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)},
+		"daily tomorrow":          {"0 0 2 * * *", time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)},
+		"list":                    {"0 15,45 10 * * *", time.Date(2026, 10, 16, 10, 45, 0, 0, time.UTC)},
+		"range with step":         {"0 0 1-23/6 * * *", time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)},
+		"first of month":          {"0 0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		"new year":                {"0 0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"monday":                  {"0 0 0 * * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		"day of month or of week": {"0 0 0 20 * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		"leap day":                {"0 0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		"never":                   {"0 0 0 30 2 *", time.Time{}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, s.Next(after))
+		})
+	}
+}
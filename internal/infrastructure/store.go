@@ -28,7 +28,7 @@ type currencyFlat struct {
 	CCreatedAt time.Time
 	CUpdatedAt *time.Time
 	EID        *uuid.UUID
-	ERate      *float64
+	ERate      *core.Decimal
 	EFrom      *core.Date
 	ECreatedAt *time.Time
 	EUpdatedAt *time.Time
@@ -82,13 +82,17 @@ func (cs PgCurrencyStore) ExistByCode(ctx context.Context, code core.CurrencyCod
 	return found, nil
 }
 
-func (cs PgCurrencyStore) mapCurrencies(flat []*currencyFlat) map[uuid.UUID]*core.Currency {
-	currencies := map[uuid.UUID]*core.Currency{} // TODO(rh): Beware order isn't preserved.
+// mapCurrencies preserves the order in which currencies first appear in flat,
+// so ORDER BY in a query carries over to the result.
+func (cs PgCurrencyStore) mapCurrencies(flat []*currencyFlat) []*core.Currency {
+	var ordered []*core.Currency
+	currencies := map[uuid.UUID]*core.Currency{}
 	for _, c := range flat {
 		c2, ok := currencies[c.CID]
 		if !ok {
 			q := c.currency()
 			currencies[c.CID] = q
+			ordered = append(ordered, q)
 			c2 = q
 		}
 
@@ -99,7 +103,7 @@ func (cs PgCurrencyStore) mapCurrencies(flat []*currencyFlat) map[uuid.UUID]*cor
 			c2.ExchangeRates = append(c2.ExchangeRates, e2)
 		}
 	}
-	return currencies
+	return ordered
 }
 
 func (cs PgCurrencyStore) GetByCode(ctx context.Context, code core.CurrencyCode) (*core.Currency, error) {
@@ -119,19 +123,46 @@ func (cs PgCurrencyStore) GetByCode(ctx context.Context, code core.CurrencyCode)
 	}
 	c := cs.mapCurrencies(currencies)
 	core.Assert(len(c) == 1, "data inconsistency")
-	for _, v := range c {
-		return v, nil
+	return c[0], nil
+}
+
+func (cs PgCurrencyStore) List(ctx context.Context, criteria core.CurrencyCriteria) ([]*core.Currency, error) {
+	// Limit applies to currencies, not to the joined rows, so it goes in a
+	// subquery.
+	var sql = `
+		SELECT c.id, c.code, c.version, c.created_at, c.updated_at,
+  			   e.id, e.rate, e.from, e.created_at, e.updated_at
+		FROM (
+			SELECT c.*
+			FROM currency c
+			WHERE ($1::varchar IS NULL OR c.code > $1)
+			  AND ($2::boolean IS NULL OR $2 = EXISTS (
+				  SELECT 1 FROM exchange_rate e WHERE e.currency_id = c.id AND e."from" > $3))
+			ORDER BY c.code
+			LIMIT $4
+		) c
+		LEFT JOIN exchange_rate e ON c.id = e.currency_id
+		ORDER BY c.code, e."from"`
+	var after *string
+	if criteria.AfterCode != nil {
+		v := criteria.AfterCode.V()
+		after = &v
 	}
-	panic("unreachable")
+	rows, _ := cs.Pool.Query(ctx, sql, after, criteria.HasFutureExchangeRates, criteria.Today, criteria.Limit)
+	currencies, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[currencyFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return cs.mapCurrencies(currencies), nil
 }
 
 // TierDiscount
 
 type tierDiscountFlat struct {
 	ID         uuid.UUID
-	Authorized float64
-	Advanced   float64
-	Premier    float64
+	Authorized core.Decimal
+	Advanced   core.Decimal
+	Premier    core.Decimal
 	From       core.Date
 	Version    int32
 	CreatedAt  time.Time
@@ -166,10 +197,10 @@ func (r PgTierDiscountStore) ExistByID(ctx context.Context, id core.TierDiscount
 	return found, nil
 }
 
-func (r PgTierDiscountStore) mapTierDiscount(flat []*tierDiscountFlat) map[uuid.UUID]*core.TierDiscount {
-	tierDiscounts := map[uuid.UUID]*core.TierDiscount{}
-	for _, td := range flat {
-		tierDiscounts[td.ID] = td.tierDiscount()
+func (r PgTierDiscountStore) mapTierDiscount(flat []*tierDiscountFlat) []*core.TierDiscount {
+	tierDiscounts := make([]*core.TierDiscount, len(flat))
+	for i, td := range flat {
+		tierDiscounts[i] = td.tierDiscount()
 	}
 	return tierDiscounts
 }
@@ -189,10 +220,956 @@ func (r PgTierDiscountStore) GetByID(ctx context.Context, id core.TierDiscountID
 	}
 	td := r.mapTierDiscount(tierDiscounts)
 	core.Assert(len(td) == 1, "data inconsistency")
-	for _, v := range td {
-		return v, nil
+	return td[0], nil
+}
+
+func (r PgTierDiscountStore) List(ctx context.Context, criteria core.TierDiscountCriteria) ([]*core.TierDiscount, error) {
+	var sql = `
+		SELECT td.id, td.authorized, td.advanced, td.premier, td.from, td.version, td.created_at, td.updated_at
+		FROM tier_discount td
+		WHERE ($1::date IS NULL OR td."from" >= $1)
+		  AND ($2::date IS NULL OR td."from" <= $2)
+		  AND ($3::date IS NULL OR td."from" > $3)
+		ORDER BY CASE WHEN $5::boolean THEN td."from" END DESC, td."from"
+		LIMIT NULLIF($4, 0)`
+	rows, _ := r.Pool.Query(ctx, sql, criteria.FromMin, criteria.FromMax, criteria.AfterFrom, criteria.Limit, criteria.Descending)
+	tierDiscounts, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[tierDiscountFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return r.mapTierDiscount(tierDiscounts), nil
+}
+
+// Product
+
+type productFlat struct {
+	ID               uuid.UUID
+	Code             string
+	Version          int32
+	CreatedAt        time.Time
+	UpdatedAt        *time.Time
+	ProductGroupID   *uuid.UUID
+	ProductGroupCode *string
+}
+
+func (p productFlat) product() *core.Product {
+	product := &core.Product{
+		Version:   p.Version,
+		ID:        p.ID,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+		Code:      core.MustParseProductCode(p.Code),
+	}
+	if p.ProductGroupID != nil {
+		product.ProductGroup = &core.ProductGroupRef{
+			ID:   *p.ProductGroupID,
+			Code: core.MustParseProductGroupCode(*p.ProductGroupCode),
+		}
+	}
+	return product
+}
+
+type PgProductStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (ps PgProductStore) ExistByID(ctx context.Context, id core.ProductID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM product WHERE id = $1)"
+	found := false
+	err := ps.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by id: %s: %w", id.V(), err)
+	}
+	return found, nil
+}
+
+func (ps PgProductStore) ExistByCode(ctx context.Context, code core.ProductCode) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM product WHERE code = $1)"
+	found := false
+	err := ps.Pool.QueryRow(ctx, sql, code.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by code: %s: %w", code.V(), err)
+	}
+	return found, nil
+}
+
+func (ps PgProductStore) mapProducts(flat []*productFlat) []*core.Product {
+	products := make([]*core.Product, len(flat))
+	for i, p := range flat {
+		products[i] = p.product()
+	}
+	return products
+}
+
+func (ps PgProductStore) GetByCode(ctx context.Context, code core.ProductCode) (*core.Product, error) {
+	var sql = `
+		SELECT p.id, p.code, p.version, p.created_at, p.updated_at, pg.id, pg.code
+		FROM product p
+		LEFT JOIN product_group pg ON p.product_group_id = pg.id
+		WHERE p.code = $1`
+	rows, _ := ps.Pool.Query(ctx, sql, code.V())
+	products, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[productFlat])
+	if err != nil {
+		return nil, fmt.Errorf("get by code: %s: %w", code.V(), err)
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+	p := ps.mapProducts(products)
+	core.Assert(len(p) == 1, "data inconsistency")
+	return p[0], nil
+}
+
+func (ps PgProductStore) List(ctx context.Context, criteria core.ProductCriteria) ([]*core.Product, error) {
+	var sql = `
+		SELECT p.id, p.code, p.version, p.created_at, p.updated_at, pg.id, pg.code
+		FROM product p
+		LEFT JOIN product_group pg ON p.product_group_id = pg.id
+		WHERE ($1::varchar IS NULL OR pg.code = $1)
+		  AND ($2::varchar IS NULL OR p.code > $2)
+		ORDER BY p.code
+		LIMIT $3`
+	var productGroupCode, after *string
+	if criteria.ProductGroupCode != nil {
+		v := criteria.ProductGroupCode.V()
+		productGroupCode = &v
+	}
+	if criteria.AfterCode != nil {
+		v := criteria.AfterCode.V()
+		after = &v
+	}
+	rows, _ := ps.Pool.Query(ctx, sql, productGroupCode, after, criteria.Limit)
+	products, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[productFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return ps.mapProducts(products), nil
+}
+
+// ProductGroup
+
+type productGroupFlat struct {
+	PGID        uuid.UUID
+	PGCode      string
+	PGVersion   int32
+	PGCreatedAt time.Time
+	PGUpdatedAt *time.Time
+	WID         *uuid.UUID
+	WPercentage *core.Decimal
+	WFrom       *core.Date
+	WCreatedAt  *time.Time
+	WUpdatedAt  *time.Time
+}
+
+func (pg productGroupFlat) productGroup() *core.ProductGroup {
+	return &core.ProductGroup{
+		Version:             pg.PGVersion,
+		ID:                  pg.PGID,
+		CreatedAt:           pg.PGCreatedAt,
+		UpdatedAt:           pg.PGUpdatedAt,
+		Code:                core.MustParseProductGroupCode(pg.PGCode),
+		ProductGroupWeights: []*core.ProductGroupWeight{},
+	}
+}
+
+func (pg productGroupFlat) productGroupWeight() *core.ProductGroupWeight {
+	if pg.WID == nil {
+		return nil
+	}
+	return &core.ProductGroupWeight{
+		ID:         *pg.WID,
+		CreatedAt:  *pg.WCreatedAt,
+		UpdatedAt:  pg.WUpdatedAt,
+		Percentage: core.MustParseWeight(*pg.WPercentage),
+		From:       core.MustParseProductGroupWeightFrom(*pg.WFrom),
+	}
+}
+
+type PgProductGroupStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (ps PgProductGroupStore) ExistByID(ctx context.Context, id core.ProductGroupID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM product_group WHERE id = $1)"
+	found := false
+	err := ps.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by id: %s: %w", id.V(), err)
+	}
+	return found, nil
+}
+
+func (ps PgProductGroupStore) ExistByCode(ctx context.Context, code core.ProductGroupCode) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM product_group WHERE code = $1)"
+	found := false
+	err := ps.Pool.QueryRow(ctx, sql, code.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by code: %s: %w", code.V(), err)
+	}
+	return found, nil
+}
+
+// mapProductGroups preserves the order in which product groups first appear
+// in flat, so ORDER BY in a query carries over to the result.
+func (ps PgProductGroupStore) mapProductGroups(flat []*productGroupFlat) []*core.ProductGroup {
+	var ordered []*core.ProductGroup
+	productGroups := map[uuid.UUID]*core.ProductGroup{}
+	for _, pg := range flat {
+		pg2, ok := productGroups[pg.PGID]
+		if !ok {
+			pg2 = pg.productGroup()
+			productGroups[pg.PGID] = pg2
+			ordered = append(ordered, pg2)
+		}
+
+		if w := pg.productGroupWeight(); w != nil {
+			pg2.ProductGroupWeights = append(pg2.ProductGroupWeights, w)
+		}
+	}
+	return ordered
+}
+
+func (ps PgProductGroupStore) GetByCode(ctx context.Context, code core.ProductGroupCode) (*core.ProductGroup, error) {
+	var sql = `
+		SELECT pg.id, pg.code, pg.version, pg.created_at, pg.updated_at,
+			   w.id, w.percentage, w.from, w.created_at, w.updated_at
+		FROM product_group pg
+		LEFT JOIN product_group_weight w ON pg.id = w.product_group_id
+		WHERE pg.code = $1
+		ORDER BY w."from"`
+	rows, _ := ps.Pool.Query(ctx, sql, code.V())
+	productGroups, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[productGroupFlat])
+	if err != nil {
+		return nil, fmt.Errorf("get by code: %s: %w", code.V(), err)
+	}
+	if len(productGroups) == 0 {
+		return nil, nil
+	}
+	pg := ps.mapProductGroups(productGroups)
+	core.Assert(len(pg) == 1, "data inconsistency")
+	return pg[0], nil
+}
+
+func (ps PgProductGroupStore) List(ctx context.Context, criteria core.ProductGroupCriteria) ([]*core.ProductGroup, error) {
+	// Limit applies to product groups, not to the joined rows, so it goes in a
+	// subquery.
+	var sql = `
+		SELECT pg.id, pg.code, pg.version, pg.created_at, pg.updated_at,
+			   w.id, w.percentage, w.from, w.created_at, w.updated_at
+		FROM (
+			SELECT pg.*
+			FROM product_group pg
+			WHERE ($1::varchar IS NULL OR pg.code > $1)
+			ORDER BY pg.code
+			LIMIT $2
+		) pg
+		LEFT JOIN product_group_weight w ON pg.id = w.product_group_id
+		ORDER BY pg.code, w."from"`
+	var after *string
+	if criteria.AfterCode != nil {
+		v := criteria.AfterCode.V()
+		after = &v
+	}
+	rows, _ := ps.Pool.Query(ctx, sql, after, criteria.Limit)
+	productGroups, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[productGroupFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return ps.mapProductGroups(productGroups), nil
+}
+
+// RevenueGroup
+
+type revenueGroupFlat struct {
+	RGID           uuid.UUID
+	RGCountryCode  string
+	RGCurrencyCode string
+	RGVersion      int32
+	RGCreatedAt    time.Time
+	RGUpdatedAt    *time.Time
+	LID            *uuid.UUID
+	LAuthorized    *core.Decimal
+	LAdvanced      *core.Decimal
+	LPremier       *core.Decimal
+	LFrom          *core.Date
+	LCreatedAt     *time.Time
+	LUpdatedAt     *time.Time
+}
+
+func (rg revenueGroupFlat) revenueGroup() *core.RevenueGroup {
+	return &core.RevenueGroup{
+		Version:            rg.RGVersion,
+		ID:                 rg.RGID,
+		CreatedAt:          rg.RGCreatedAt,
+		UpdatedAt:          rg.RGUpdatedAt,
+		CountryCode:        core.MustParseCountryCode(rg.RGCountryCode),
+		CurrencyCode:       core.MustParseCurrencyCode(rg.RGCurrencyCode),
+		RevenueGroupLimits: []*core.RevenueGroupLimit{},
+	}
+}
+
+func (rg revenueGroupFlat) revenueGroupLimit() *core.RevenueGroupLimit {
+	if rg.LID == nil {
+		return nil
+	}
+	return &core.RevenueGroupLimit{
+		ID:        *rg.LID,
+		CreatedAt: *rg.LCreatedAt,
+		UpdatedAt: rg.LUpdatedAt,
+		Limits:    core.MustParseRevenueLimits(core.MustParseCurrencyCode(rg.RGCurrencyCode), *rg.LAuthorized, *rg.LAdvanced, *rg.LPremier),
+		From:      core.MustParseRevenueGroupLimitFrom(*rg.LFrom),
+	}
+}
+
+type PgRevenueGroupStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (rs PgRevenueGroupStore) ExistByID(ctx context.Context, id core.RevenueGroupID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM revenue_group WHERE id = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by id: %s: %w", id.V(), err)
+	}
+	return found, nil
+}
+
+func (rs PgRevenueGroupStore) ExistByCountryCode(ctx context.Context, code core.CountryCode) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM revenue_group WHERE country_code = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, code.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by country code: %s: %w", code.V(), err)
+	}
+	return found, nil
+}
+
+func (rs PgRevenueGroupStore) mapRevenueGroups(flat []*revenueGroupFlat) []*core.RevenueGroup {
+	var ordered []*core.RevenueGroup
+	revenueGroups := map[uuid.UUID]*core.RevenueGroup{}
+	for _, rg := range flat {
+		rg2, ok := revenueGroups[rg.RGID]
+		if !ok {
+			rg2 = rg.revenueGroup()
+			revenueGroups[rg.RGID] = rg2
+			ordered = append(ordered, rg2)
+		}
+
+		if l := rg.revenueGroupLimit(); l != nil {
+			rg2.RevenueGroupLimits = append(rg2.RevenueGroupLimits, l)
+		}
+	}
+	return ordered
+}
+
+func (rs PgRevenueGroupStore) getBy(ctx context.Context, column string, value any) (*core.RevenueGroup, error) {
+	var sql = fmt.Sprintf(`
+		SELECT rg.id, rg.country_code, rg.currency_code, rg.version, rg.created_at, rg.updated_at,
+			   l.id, l.authorized, l.advanced, l.premier, l.from, l.created_at, l.updated_at
+		FROM revenue_group rg
+		LEFT JOIN revenue_group_limit l ON rg.id = l.revenue_group_id
+		WHERE rg.%s = $1
+		ORDER BY l."from"`, column)
+	rows, _ := rs.Pool.Query(ctx, sql, value)
+	revenueGroups, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[revenueGroupFlat])
+	if err != nil {
+		return nil, err
+	}
+	if len(revenueGroups) == 0 {
+		return nil, nil
+	}
+	rg := rs.mapRevenueGroups(revenueGroups)
+	core.Assert(len(rg) == 1, "data inconsistency")
+	return rg[0], nil
+}
+
+func (rs PgRevenueGroupStore) GetByID(ctx context.Context, id core.RevenueGroupID) (*core.RevenueGroup, error) {
+	rg, err := rs.getBy(ctx, "id", id.V())
+	if err != nil {
+		return nil, fmt.Errorf("get by id: %s: %w", id.V(), err)
+	}
+	return rg, nil
+}
+
+func (rs PgRevenueGroupStore) GetByCountryCode(ctx context.Context, code core.CountryCode) (*core.RevenueGroup, error) {
+	rg, err := rs.getBy(ctx, "country_code", code.V())
+	if err != nil {
+		return nil, fmt.Errorf("get by country code: %s: %w", code.V(), err)
+	}
+	return rg, nil
+}
+
+// Cluster
+
+type clusterFlat struct {
+	CID                               uuid.UUID
+	CExternalID                       uuid.UUID
+	CRevenueGroupID                   uuid.UUID
+	CCalculatedResellerTierMinimum    *string
+	CCalculatedResellerTierYearToDate *string
+	CCalculatedNetRevenueYearToDate   *core.Decimal
+	CCalculatedResellerTierProjected  *string
+	CCalculatedNetRevenueProjected    *core.Decimal
+	CCalculatedNetRevenueLastYear     *core.Decimal
+	CLastTieredAt                     *core.Date
+	CFiscalYear                       int
+	CVersion                          int32
+	CCreatedAt                        time.Time
+	CUpdatedAt                        *time.Time
+	RGCurrencyCode                    string
+	RID                               *uuid.UUID
+	RRole                             *string
+}
+
+func (c clusterFlat) cluster() *core.Cluster {
+	cluster := &core.Cluster{
+		Version:        c.CVersion,
+		ID:             c.CID,
+		CreatedAt:      c.CCreatedAt,
+		UpdatedAt:      c.CUpdatedAt,
+		ExternalID:     core.MustParseClusterExternalID(c.CExternalID),
+		RevenueGroupID: c.CRevenueGroupID,
+		LastTieredAt:   c.CLastTieredAt,
+		FiscalYear:     c.CFiscalYear,
+	}
+	if c.CCalculatedResellerTierMinimum != nil {
+		tier := core.MustParseResellerTier(*c.CCalculatedResellerTierMinimum)
+		cluster.CalculatedResellerTierMinimum = &tier
+	}
+	if c.CCalculatedResellerTierYearToDate != nil {
+		tier := core.MustParseResellerTier(*c.CCalculatedResellerTierYearToDate)
+		cluster.CalculatedResellerTierYearToDate = &tier
+	}
+	currencyCode := core.MustParseCurrencyCode(c.RGCurrencyCode)
+	if c.CCalculatedNetRevenueYearToDate != nil {
+		m := core.NewMoney(*c.CCalculatedNetRevenueYearToDate, currencyCode)
+		cluster.CalculatedNetRevenueYearToDate = &m
+	}
+	if c.CCalculatedResellerTierProjected != nil {
+		tier := core.MustParseResellerTier(*c.CCalculatedResellerTierProjected)
+		cluster.CalculatedResellerTierProjected = &tier
+	}
+	if c.CCalculatedNetRevenueProjected != nil {
+		m := core.NewMoney(*c.CCalculatedNetRevenueProjected, currencyCode)
+		cluster.CalculatedNetRevenueProjected = &m
+	}
+	if c.CCalculatedNetRevenueLastYear != nil {
+		m := core.NewMoney(*c.CCalculatedNetRevenueLastYear, currencyCode)
+		cluster.CalculatedNetRevenueLastYear = &m
+	}
+	return cluster
+}
+
+func (c clusterFlat) clusterMember() *core.ClusterMember {
+	if c.RID == nil {
+		return nil
+	}
+	return &core.ClusterMember{
+		ResellerID: *c.RID,
+		Role:       core.MustParseResellerRole(*c.RRole),
+	}
+}
+
+type PgClusterStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (cs PgClusterStore) ExistByID(ctx context.Context, id core.ClusterID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM cluster WHERE id = $1)"
+	found := false
+	err := cs.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by id: %s: %w", id.V(), err)
+	}
+	return found, nil
+}
+
+func (cs PgClusterStore) ExistByExternalID(ctx context.Context, externalID core.ClusterExternalID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM cluster WHERE external_id = $1)"
+	found := false
+	err := cs.Pool.QueryRow(ctx, sql, externalID.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by external id: %s: %w", externalID.V(), err)
+	}
+	return found, nil
+}
+
+func (cs PgClusterStore) mapClusters(flat []*clusterFlat) []*core.Cluster {
+	var ordered []*core.Cluster
+	clusters := map[uuid.UUID]*core.Cluster{}
+	for _, c := range flat {
+		c2, ok := clusters[c.CID]
+		if !ok {
+			c2 = c.cluster()
+			clusters[c.CID] = c2
+			ordered = append(ordered, c2)
+		}
+
+		if m := c.clusterMember(); m != nil {
+			c2.Members = append(c2.Members, *m)
+		}
+	}
+	return ordered
+}
+
+// Members come from resellers as the reseller owns cluster membership. The
+// revenue group's currency is that of the cluster's calculated net revenue.
+const clusterSelect = `
+	SELECT c.id, c.external_id, c.revenue_group_id, c.calculated_reseller_tier_minimum,
+		   c.calculated_reseller_tier_year_to_date, c.calculated_net_revenue_year_to_date,
+		   c.calculated_reseller_tier_projected, c.calculated_net_revenue_projected,
+		   c.calculated_net_revenue_last_year, c.last_tiered_at, c.fiscal_year,
+		   c.version, c.created_at, c.updated_at,
+		   rg.currency_code,
+		   r.id, r.role
+	FROM cluster c
+	JOIN revenue_group rg ON c.revenue_group_id = rg.id
+	LEFT JOIN reseller r ON c.id = r.cluster_id`
+
+func (cs PgClusterStore) GetByID(ctx context.Context, id core.ClusterID) (*core.Cluster, error) {
+	var sql = clusterSelect + `
+		WHERE c.id = $1
+		ORDER BY r.role = 'Head' DESC, r.id`
+	rows, _ := cs.Pool.Query(ctx, sql, id.V())
+	clusters, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[clusterFlat])
+	if err != nil {
+		return nil, fmt.Errorf("get by id: %s: %w", id.V(), err)
+	}
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+	c := cs.mapClusters(clusters)
+	core.Assert(len(c) == 1, "data inconsistency")
+	return c[0], nil
+}
+
+func (cs PgClusterStore) List(ctx context.Context) ([]*core.Cluster, error) {
+	var sql = clusterSelect + `
+		ORDER BY c.id, r.role = 'Head' DESC, r.id`
+	rows, _ := cs.Pool.Query(ctx, sql)
+	clusters, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[clusterFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	return cs.mapClusters(clusters), nil
+}
+
+type clusterTierFlat struct {
+	ClusterID                        uuid.UUID
+	TierAt                           core.Date
+	CalculatedResellerTierYearToDate *string
+	CalculatedResellerTierMinimum    *string
+}
+
+func (cs PgClusterStore) GetTierOn(ctx context.Context, id core.ClusterID, on core.Date) (*core.ClusterTier, error) {
+	var sql = `
+		SELECT ct.cluster_id, ct.tier_at, ct.calculated_reseller_tier_year_to_date, ct.calculated_reseller_tier_minimum
+		FROM cluster_tier ct
+		WHERE ct.cluster_id = $1 AND ct.tier_at <= $2
+		ORDER BY ct.tier_at DESC
+		LIMIT 1`
+	rows, _ := cs.Pool.Query(ctx, sql, id.V(), on)
+	tiers, err := pgx.CollectRows(rows, pgx.RowToStructByPos[clusterTierFlat])
+	if err != nil {
+		return nil, fmt.Errorf("get tier on: %s: %s: %w", id.V(), on, err)
+	}
+	if len(tiers) == 0 {
+		return nil, nil
 	}
-	panic("unreachable")
+	t := tiers[0]
+	tier := &core.ClusterTier{ClusterID: t.ClusterID, TierAt: t.TierAt}
+	if t.CalculatedResellerTierYearToDate != nil {
+		yearToDate := core.MustParseResellerTier(*t.CalculatedResellerTierYearToDate)
+		tier.CalculatedResellerTierYearToDate = &yearToDate
+	}
+	if t.CalculatedResellerTierMinimum != nil {
+		minimum := core.MustParseResellerTier(*t.CalculatedResellerTierMinimum)
+		tier.CalculatedResellerTierMinimum = &minimum
+	}
+	return tier, nil
+}
+
+// Tiering
+
+type PgTieringStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (ts PgTieringStore) ExistByTierAt(ctx context.Context, tierAt core.Date) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM tiering WHERE tier_at = $1)"
+	found := false
+	err := ts.Pool.QueryRow(ctx, sql, tierAt).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by tier at: %s: %w", tierAt, err)
+	}
+	return found, nil
+}
+
+// Rollover
+
+type PgRolloverStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (rs PgRolloverStore) ExistByFiscalYear(ctx context.Context, fiscalYear int) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM rollover WHERE fiscal_year = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, fiscalYear).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by fiscal year: %d: %w", fiscalYear, err)
+	}
+	return found, nil
+}
+
+// Reseller
+
+type resellerFlat struct {
+	ID                             uuid.UUID
+	ExternalID                     uuid.UUID
+	ClusterID                      *uuid.UUID
+	CountryCode                    string
+	CurrencyCode                   string
+	Role                           string
+	EnrolledAt                     core.Date
+	CalculatedNetRevenueYearToDate *core.Decimal
+	CalculatedNetRevenueLastYear   *core.Decimal
+	FiscalYear                     int
+	Version                        int32
+	CreatedAt                      time.Time
+	UpdatedAt                      *time.Time
+}
+
+func (r resellerFlat) reseller() *core.Reseller {
+	currencyCode := core.MustParseCurrencyCode(r.CurrencyCode)
+	reseller := &core.Reseller{
+		Version:      r.Version,
+		ID:           r.ID,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+		ExternalID:   core.MustParseResellerExternalID(r.ExternalID),
+		ClusterID:    r.ClusterID,
+		CountryCode:  core.MustParseCountryCode(r.CountryCode),
+		CurrencyCode: currencyCode,
+		EnrolledAt:   core.MustParseResellerEnrolledAt(r.EnrolledAt),
+		ResellerRole: core.MustParseResellerRole(r.Role),
+		FiscalYear:   r.FiscalYear,
+	}
+	if r.CalculatedNetRevenueYearToDate != nil {
+		m := core.NewMoney(*r.CalculatedNetRevenueYearToDate, currencyCode)
+		reseller.CalculatedNetRevenueYearToDate = &m
+	}
+	if r.CalculatedNetRevenueLastYear != nil {
+		m := core.NewMoney(*r.CalculatedNetRevenueLastYear, currencyCode)
+		reseller.CalculatedNetRevenueLastYear = &m
+	}
+	return reseller
+}
+
+type PgResellerStore struct {
+	Pool *pgxpool.Pool
+}
+
+func (rs PgResellerStore) ExistByID(ctx context.Context, id core.ResellerID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM reseller WHERE id = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by id: %s: %w", id.V(), err)
+	}
+	return found, nil
+}
+
+func (rs PgResellerStore) ExistByExternalID(ctx context.Context, externalID core.ResellerExternalID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM reseller WHERE external_id = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, externalID.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists by external id: %s: %w", externalID.V(), err)
+	}
+	return found, nil
+}
+
+func (rs PgResellerStore) ExistBillingByID(ctx context.Context, id core.ResellerBillingID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM billing WHERE id = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists billing by id: %s: %w", id.V(), err)
+	}
+	return found, nil
+}
+
+func (rs PgResellerStore) ExistBillingByDocumentNumber(ctx context.Context, documentNumber core.DocumentNumber) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM billing WHERE document_number = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, documentNumber.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists billing by document number: %s: %w", documentNumber.V(), err)
+	}
+	return found, nil
+}
+
+func (rs PgResellerStore) ExistBillingItemByProduct(ctx context.Context, id core.ProductID) (bool, error) {
+	sql := "SELECT EXISTS (SELECT 1 FROM billing_item WHERE product_id = $1)"
+	found := false
+	err := rs.Pool.QueryRow(ctx, sql, id.V()).Scan(&found)
+	if err != nil {
+		return found, fmt.Errorf("exists billing item by product: %s: %w", id.String(), err)
+	}
+	return found, nil
+}
+
+type bookedNetRevenueFlat struct {
+	BookedAt             core.Date
+	CurrencyCode         string
+	CalculatedNetRevenue core.Decimal
+}
+
+func (rs PgResellerStore) ListBillingNetRevenue(ctx context.Context, id core.ResellerID, from core.Date, to core.Date) ([]core.BookedNetRevenue, error) {
+	sql := `
+		SELECT booked_at, currency_code, calculated_net_revenue
+		FROM billing
+		WHERE reseller_id = $1 AND booked_at BETWEEN $2 AND $3
+		ORDER BY booked_at, id`
+	rows, _ := rs.Pool.Query(ctx, sql, id.V(), from, to)
+	flat, err := pgx.CollectRows(rows, pgx.RowToStructByPos[bookedNetRevenueFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list billing net revenue: %s: %w", id.V(), err)
+	}
+	revenues := make([]core.BookedNetRevenue, len(flat))
+	for i, f := range flat {
+		revenues[i] = core.BookedNetRevenue{
+			BookedAt:   f.BookedAt,
+			NetRevenue: core.NewMoney(f.CalculatedNetRevenue, core.MustParseCurrencyCode(f.CurrencyCode)),
+		}
+	}
+	return revenues, nil
+}
+
+const resellerColumns = `
+	id, external_id, cluster_id, country_code, currency_code, role, enrolled_at,
+	calculated_net_revenue_year_to_date, calculated_net_revenue_last_year, fiscal_year,
+	version, created_at, updated_at`
+
+func (rs PgResellerStore) getBy(ctx context.Context, column string, value uuid.UUID) (*core.Reseller, error) {
+	sql := fmt.Sprintf("SELECT %s FROM reseller WHERE %s = $1", resellerColumns, column)
+	rows, _ := rs.Pool.Query(ctx, sql, value)
+	resellers, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[resellerFlat])
+	if err != nil {
+		return nil, err
+	}
+	if len(resellers) == 0 {
+		return nil, nil
+	}
+	core.Assert(len(resellers) == 1, "data inconsistency")
+	return resellers[0].reseller(), nil
+}
+
+func (rs PgResellerStore) List(ctx context.Context) ([]*core.Reseller, error) {
+	sql := fmt.Sprintf("SELECT %s FROM reseller ORDER BY id", resellerColumns)
+	rows, _ := rs.Pool.Query(ctx, sql)
+	flat, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[resellerFlat])
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+	resellers := make([]*core.Reseller, len(flat))
+	for i, r := range flat {
+		resellers[i] = r.reseller()
+	}
+	return resellers, nil
+}
+
+func (rs PgResellerStore) GetByID(ctx context.Context, id core.ResellerID) (*core.Reseller, error) {
+	reseller, err := rs.getBy(ctx, "id", id.V())
+	if err != nil {
+		return nil, fmt.Errorf("get by id: %s: %w", id.V(), err)
+	}
+	return reseller, nil
+}
+
+func (rs PgResellerStore) GetByExternalID(ctx context.Context, externalID core.ResellerExternalID) (*core.Reseller, error) {
+	reseller, err := rs.getBy(ctx, "external_id", externalID.V())
+	if err != nil {
+		return nil, fmt.Errorf("get by external id: %s: %w", externalID.V(), err)
+	}
+	return reseller, nil
+}
+
+// Idempotency
+
+const (
+	outcomePending      = "pending"
+	outcomeSuccess      = "success"
+	outcomeRequestParse = "request_parse"
+	outcomeConflict     = "conflict"
+	outcomeNotFound     = "not_found"
+	outcomeDataStale    = "data_stale"
+	outcomeDomain       = "domain"
+)
+
+type idempotencyRecord struct {
+	Fingerprint string
+	Outcome     string
+	Error       []byte
+}
+
+// replay reconstructs the recorded outcome as the typed error the command
+// originally returned, or nil on success.
+func (r idempotencyRecord) replay() error {
+	var target error
+	switch r.Outcome {
+	case outcomeSuccess:
+		return nil
+	case outcomeRequestParse:
+		target = &core.RequestParseCollector{}
+	case outcomeConflict:
+		target = &core.ConflictError{}
+	case outcomeNotFound:
+		target = &core.NotFoundError{}
+	case outcomeDataStale:
+		target = &core.DataStaleError{}
+	case outcomeDomain:
+		target = &core.DomainError{}
+	default:
+		panic(fmt.Sprintf("unhandled outcome: %s", r.Outcome))
+	}
+	if err := json.Unmarshal(r.Error, target); err != nil {
+		return fmt.Errorf("unmarshal %s outcome: %w", r.Outcome, err)
+	}
+	return target
+}
+
+// classifyOutcome maps a command's error to a recordable outcome. Unexpected
+// errors, such as a lost database connection, aren't recordable because a
+// retry may succeed. Neither is stale data unless the command carried an
+// expected version: without one, the aggregate changed between read and write
+// by a concurrent command, and a retry reads the aggregate anew.
+func classifyOutcome(err error, expectsVersion bool) (outcome string, payload []byte, ok bool) {
+	if err == nil {
+		return outcomeSuccess, nil, true
+	}
+
+	var (
+		parse    *core.RequestParseCollector
+		conflict *core.ConflictError
+		notFound *core.NotFoundError
+		stale    *core.DataStaleError
+		domain   *core.DomainError
+		target   any
+	)
+	switch {
+	case errors.As(err, &parse):
+		outcome, target = outcomeRequestParse, parse
+	case errors.As(err, &conflict):
+		outcome, target = outcomeConflict, conflict
+	case errors.As(err, &notFound):
+		outcome, target = outcomeNotFound, notFound
+	case errors.As(err, &stale) && expectsVersion:
+		outcome, target = outcomeDataStale, stale
+	case errors.As(err, &domain):
+		outcome, target = outcomeDomain, domain
+	default:
+		// Includes stale data without an expected version.
+		return "", nil, false
+	}
+
+	b, mErr := json.Marshal(target)
+	if mErr != nil {
+		return "", nil, false
+	}
+	return outcome, b, true
+}
+
+type PgIdempotencyStore struct {
+	Pool *pgxpool.Pool
+}
+
+const (
+	// idempotencyReservationTTL bounds how long a reservation stays pending.
+	// It's well beyond how long a command runs, so a reservation pending for
+	// longer was left behind by a process that died before completing or
+	// releasing it.
+	idempotencyReservationTTL = 5 * time.Minute
+
+	// idempotencyKeyTTL bounds how long a completed key replays its outcome.
+	// It's well beyond how long clients retry a request, after which the key
+	// is as if never used.
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+// Reserve claims key for a command execution. If the key was already claimed,
+// the existing record is returned instead, unless the claim is pending past
+// its TTL for the same request, or completed past its TTL for any request, in
+// which case it's taken over. The reservation is committed immediately so a
+// concurrent retry sees the key as pending.
+func (s PgIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, now time.Time) (*idempotencyRecord, bool, error) {
+	q := `
+		INSERT INTO idempotency_key (key, fingerprint, outcome, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = excluded.fingerprint,
+		    outcome = excluded.outcome,
+		    error = NULL,
+		    created_at = excluded.created_at,
+		    completed_at = NULL
+		WHERE (idempotency_key.outcome = $3
+		       AND idempotency_key.fingerprint = excluded.fingerprint
+		       AND idempotency_key.created_at < $5)
+		   OR (idempotency_key.outcome <> $3
+		       AND idempotency_key.completed_at < $6)`
+	tag, err := s.Pool.Exec(ctx, q, key, fingerprint, outcomePending, now, now.Add(-idempotencyReservationTTL), now.Add(-idempotencyKeyTTL))
+	if err != nil {
+		return nil, false, fmt.Errorf("reserve idempotency key: %s: %w", key, err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	var r idempotencyRecord
+	q = "SELECT fingerprint, outcome, error FROM idempotency_key WHERE key = $1"
+	if err := s.Pool.QueryRow(ctx, q, key).Scan(&r.Fingerprint, &r.Outcome, &r.Error); err != nil {
+		return nil, false, fmt.Errorf("get idempotency key: %s: %w", key, err)
+	}
+	return &r, false, nil
+}
+
+func (s PgIdempotencyStore) Complete(ctx context.Context, key, outcome string, payload []byte, now time.Time) error {
+	q := `
+		UPDATE idempotency_key
+		SET outcome = $1, error = $2, completed_at = $3
+		WHERE key = $4 AND outcome = $5`
+	tag, err := s.Pool.Exec(ctx, q, outcome, payload, now, key, outcomePending)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %s: %w", key, err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("complete idempotency key: %s: unexpected row count: %d", key, tag.RowsAffected())
+	}
+	return nil
+}
+
+// Purge deletes keys past their TTL, which would otherwise accumulate as most
+// keys are never reused.
+func (s PgIdempotencyStore) Purge(ctx context.Context, now time.Time) (int64, error) {
+	q := `
+		DELETE FROM idempotency_key
+		WHERE (outcome = $1 AND created_at < $2)
+		   OR (outcome <> $1 AND completed_at < $3)`
+	tag, err := s.Pool.Exec(ctx, q, outcomePending, now.Add(-idempotencyReservationTTL), now.Add(-idempotencyKeyTTL))
+	if err != nil {
+		return 0, fmt.Errorf("purge idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Release removes a pending reservation so a retry re-executes the command.
+func (s PgIdempotencyStore) Release(ctx context.Context, key string) error {
+	q := "DELETE FROM idempotency_key WHERE key = $1 AND outcome = $2"
+	if _, err := s.Pool.Exec(ctx, q, key, outcomePending); err != nil {
+		return fmt.Errorf("release idempotency key: %s: %w", key, err)
+	}
+	return nil
 }
 
 type PgStoreProjector struct {
@@ -242,6 +1219,13 @@ func (sp PgStoreProjector) withTx(ctx context.Context, fn func(pgx.Tx) error) (e
 var entityTableMap = map[reflect.Type]string{
 	reflect.TypeFor[*core.Currency]():     "currency",
 	reflect.TypeFor[*core.TierDiscount](): "tier_discount",
+	reflect.TypeFor[*core.Product]():      "product",
+	reflect.TypeFor[*core.ProductGroup](): "product_group",
+	reflect.TypeFor[*core.Reseller]():     "reseller",
+	reflect.TypeFor[*core.Cluster]():      "cluster",
+	reflect.TypeFor[*core.RevenueGroup](): "revenue_group",
+	reflect.TypeFor[*core.Tiering]():      "tiering",
+	reflect.TypeFor[*core.Rollover]():     "rollover",
 }
 
 func (sp PgStoreProjector) enforceOptimisticLock(ctx context.Context, tx pgx.Tx, aggregate core.Aggregate) error {
@@ -395,11 +1379,210 @@ func (sp PgStoreProjector) project(ctx context.Context, tx pgx.Tx, event core.Do
             UPDATE tier_discount 
             SET authorized = $1, advanced = $2, premier = $3, "from" = $4, updated_at = $5 
             WHERE id = $6`
-		tag, err := tx.Exec(ctx, q, e.Authorized, e.Advanced, e.Premier, e.From, e.ID, e.OccurredAt)
+		tag, err := tx.Exec(ctx, q, e.Authorized, e.Advanced, e.Premier, e.From, e.OccurredAt, e.ID)
 		return sp.checkExec(err, tag, e, e.ID)
 	case core.TierDiscountRemovedEvent:
 		tag, err := tx.Exec(ctx, "DELETE FROM tier_discount WHERE id = $1", e.ID)
 		return sp.checkExec(err, tag, e, e.ID)
+
+	// Product
+	case core.ProductCreatedEvent:
+		q := `INSERT INTO product (id, code, version, created_at) VALUES ($1, $2, $3, $4)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.Code, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ProductGroupAssignedEvent:
+		q := `UPDATE product SET product_group_id = $1, updated_at = $2 WHERE id = $3`
+		tag, err := tx.Exec(ctx, q, e.ProductGroupID, e.OccurredAt, e.ProductID)
+		return sp.checkExec(err, tag, e, e.ProductID)
+	case core.ProductGroupUnassignedEvent:
+		q := `UPDATE product SET product_group_id = NULL, updated_at = $1 WHERE id = $2`
+		tag, err := tx.Exec(ctx, q, e.OccurredAt, e.ProductID)
+		return sp.checkExec(err, tag, e, e.ProductID)
+	case core.ProductRemovedEvent:
+		tag, err := tx.Exec(ctx, "DELETE FROM product WHERE id = $1", e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+
+	// ProductGroup
+	case core.ProductGroupCreatedEvent:
+		q := `INSERT INTO product_group (id, code, version, created_at) VALUES ($1, $2, $3, $4)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.Code, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ProductGroupRemovedEvent:
+		tag, err := tx.Exec(ctx, "DELETE FROM product_group WHERE id = $1", e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ProductGroupWeightAddedEvent:
+		q := `INSERT INTO product_group_weight (id, product_group_id, percentage, "from", created_at) VALUES ($1, $2, $3, $4, $5)`
+		tag, err := tx.Exec(ctx, q, e.ProductGroupWeightID, e.ProductGroupID, e.Percentage, e.From, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ProductGroupWeightID)
+	case core.ProductGroupWeightUpdatedEvent:
+		q := `
+            UPDATE product_group_weight
+            SET percentage = $1, "from" = $2, updated_at = $3
+            WHERE id = $4 AND product_group_id = $5`
+		tag, err := tx.Exec(ctx, q, e.Percentage, e.From, e.OccurredAt, e.ProductGroupWeightID, e.ProductGroupID)
+		return sp.checkExec(err, tag, e, e.ProductGroupWeightID)
+	case core.ProductGroupWeightRemovedEvent:
+		q := `DELETE FROM product_group_weight WHERE id = $1 AND product_group_id = $2`
+		tag, err := tx.Exec(ctx, q, e.ProductGroupWeightID, e.ProductGroupID)
+		return sp.checkExec(err, tag, e, e.ProductGroupWeightID)
+
+	// RevenueGroup
+	case core.RevenueGroupCreatedEvent:
+		q := `INSERT INTO revenue_group (id, country_code, currency_code, version, created_at) VALUES ($1, $2, $3, $4, $5)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.CountryCode, e.CurrencyCode, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.RevenueGroupLimitAddedEvent:
+		q := `
+            INSERT INTO revenue_group_limit (id, revenue_group_id, currency_code, authorized, advanced, premier, "from", created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		tag, err := tx.Exec(ctx, q, e.RevenueGroupLimitID, e.RevenueGroupID, e.CurrencyCode, e.Authorized, e.Advanced, e.Premier, e.From, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.RevenueGroupLimitID)
+	case core.RevenueGroupLimitUpdatedEvent:
+		q := `
+            UPDATE revenue_group_limit
+            SET authorized = $1, advanced = $2, premier = $3, "from" = $4, updated_at = $5
+            WHERE id = $6 AND revenue_group_id = $7`
+		tag, err := tx.Exec(ctx, q, e.Authorized, e.Advanced, e.Premier, e.From, e.OccurredAt, e.RevenueGroupLimitID, e.RevenueGroupID)
+		return sp.checkExec(err, tag, e, e.RevenueGroupLimitID)
+	case core.RevenueGroupLimitRemovedEvent:
+		q := `DELETE FROM revenue_group_limit WHERE id = $1 AND revenue_group_id = $2`
+		tag, err := tx.Exec(ctx, q, e.RevenueGroupLimitID, e.RevenueGroupID)
+		return sp.checkExec(err, tag, e, e.RevenueGroupLimitID)
+
+	// Reseller
+	case core.ResellerEnrolledEvent:
+		q := `
+            INSERT INTO reseller (id, external_id, country_code, currency_code, role, enrolled_at, fiscal_year, version, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.ExternalID, e.CountryCode, e.CurrencyCode, e.Role, e.EnrolledAt, e.FiscalYear, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ResellerRoleChangedEvent:
+		q := `UPDATE reseller SET role = $1, updated_at = $2 WHERE id = $3`
+		tag, err := tx.Exec(ctx, q, e.Role, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ResellerCurrencyChangedEvent:
+		q := `
+            UPDATE reseller
+            SET currency_code = $1, calculated_net_revenue_year_to_date = NULL,
+                calculated_net_revenue_last_year = NULL, updated_at = $2
+            WHERE id = $3`
+		tag, err := tx.Exec(ctx, q, e.CurrencyCode, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ResellerJoinedClusterEvent:
+		q := `UPDATE reseller SET cluster_id = $1, role = $2, updated_at = $3 WHERE id = $4`
+		tag, err := tx.Exec(ctx, q, e.ClusterID, e.Role, e.OccurredAt, e.ResellerID)
+		return sp.checkExec(err, tag, e, e.ResellerID)
+	case core.ResellerLeftClusterEvent:
+		q := `UPDATE reseller SET cluster_id = NULL, role = $1, updated_at = $2 WHERE id = $3 AND cluster_id = $4`
+		tag, err := tx.Exec(ctx, q, core.ResellerRoleOrphan, e.OccurredAt, e.ResellerID, e.ClusterID)
+		return sp.checkExec(err, tag, e, e.ResellerID)
+	case core.ResellerBillingRecordedEvent:
+		q := `
+            INSERT INTO billing (id, reseller_id, document_number, booked_at, billing_kind, currency_code, calculated_net_revenue, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		tag, err := tx.Exec(ctx, q, e.BillingID, e.ResellerID, e.DocumentNumber, e.BookedAt, e.Kind, e.CurrencyCode, e.CalculatedNetRevenue, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.BillingID)
+	case core.ResellerBillingItemRecordedEvent:
+		// Items refer to products by code in the domain but by id in the
+		// database.
+		q := `
+            INSERT INTO billing_item (id, billing_id, product_id, currency_code, gross_revenue, calculated_net_revenue, created_at)
+            SELECT $1, $2, p.id, $3, $4, $5, $6 FROM product p WHERE p.code = $7`
+		tag, err := tx.Exec(ctx, q, e.BillingItemID, e.BillingID, e.CurrencyCode, e.GrossRevenue, e.CalculatedNetRevenue, e.OccurredAt, e.ProductCode)
+		return sp.checkExec(err, tag, e, e.BillingItemID)
+	case core.ResellerNetRevenueChangedEvent:
+		q := `
+            UPDATE reseller
+            SET calculated_net_revenue_year_to_date = $1, calculated_net_revenue_last_year = $2, updated_at = $3
+            WHERE id = $4`
+		tag, err := tx.Exec(ctx, q, e.CalculatedNetRevenueYearToDate, e.CalculatedNetRevenueLastYear, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ResellerRolledOverEvent:
+		q := `
+            UPDATE reseller
+            SET fiscal_year = $1, calculated_net_revenue_year_to_date = NULL, calculated_net_revenue_last_year = $2,
+                updated_at = $3
+            WHERE id = $4`
+		tag, err := tx.Exec(ctx, q, e.FiscalYear, e.CalculatedNetRevenueLastYear, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ResellerUnenrolledEvent:
+		// Billings outlive the reseller for reporting, so they're detached
+		// rather than deleted.
+		if _, err := tx.Exec(ctx, "UPDATE billing SET reseller_id = NULL WHERE reseller_id = $1", e.ID); err != nil {
+			return fmt.Errorf("project %s (id=%s) execution failed: %w", sp.typeName(e), e.ID, err)
+		}
+		tag, err := tx.Exec(ctx, "DELETE FROM reseller WHERE id = $1", e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+
+	// Cluster
+	case core.ClusterCreatedEvent:
+		q := `INSERT INTO cluster (id, external_id, revenue_group_id, fiscal_year, version, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.ExternalID, e.RevenueGroupID, e.FiscalYear, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
+	// Membership is projected from the reseller's reaction to member events.
+	case core.ClusterMemberAddedEvent:
+		tag, err := tx.Exec(ctx, "UPDATE cluster SET updated_at = $1 WHERE id = $2", e.OccurredAt, e.ClusterID)
+		return sp.checkExec(err, tag, e, e.ClusterID)
+	case core.ClusterMemberRemovedEvent:
+		tag, err := tx.Exec(ctx, "UPDATE cluster SET updated_at = $1 WHERE id = $2", e.OccurredAt, e.ClusterID)
+		return sp.checkExec(err, tag, e, e.ClusterID)
+	case core.ClusterHeadChangedEvent:
+		tag, err := tx.Exec(ctx, "UPDATE cluster SET updated_at = $1 WHERE id = $2", e.OccurredAt, e.ClusterID)
+		return sp.checkExec(err, tag, e, e.ClusterID)
+	// Tiers of the cluster are deleted with it.
+	case core.ClusterRemovedEvent:
+		tag, err := tx.Exec(ctx, "DELETE FROM cluster WHERE id = $1", e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	// A tiering on January 1 replaces the tiers of the rollover on that date.
+	case core.ClusterTieredEvent:
+		q := `
+            WITH c AS (
+                UPDATE cluster
+                SET calculated_reseller_tier_year_to_date = $1, calculated_net_revenue_year_to_date = $2,
+                    calculated_reseller_tier_minimum = $3, last_tiered_at = $4, updated_at = $5
+                WHERE id = $6
+                RETURNING id)
+            INSERT INTO cluster_tier (cluster_id, tier_at, calculated_reseller_tier_year_to_date, calculated_reseller_tier_minimum)
+            SELECT c.id, $4, $1, $3 FROM c
+            ON CONFLICT (cluster_id, tier_at) DO UPDATE
+            SET calculated_reseller_tier_year_to_date = EXCLUDED.calculated_reseller_tier_year_to_date,
+                calculated_reseller_tier_minimum = EXCLUDED.calculated_reseller_tier_minimum`
+		tag, err := tx.Exec(ctx, q, e.CalculatedResellerTierYearToDate, e.CalculatedNetRevenueYearToDate,
+			e.CalculatedResellerTierMinimum, e.TierAt, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+
+	case core.ClusterTierProjectedEvent:
+		q := `
+            UPDATE cluster
+            SET calculated_reseller_tier_projected = $1, calculated_net_revenue_projected = $2, updated_at = $3
+            WHERE id = $4`
+		tag, err := tx.Exec(ctx, q, e.CalculatedResellerTierProjected, e.CalculatedNetRevenueProjected, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+	case core.ClusterRolledOverEvent:
+		q := `
+            WITH c AS (
+                UPDATE cluster
+                SET fiscal_year = $1, calculated_net_revenue_last_year = $2, calculated_reseller_tier_minimum = $3,
+                    calculated_net_revenue_year_to_date = NULL, calculated_reseller_tier_year_to_date = NULL,
+                    calculated_net_revenue_projected = NULL, calculated_reseller_tier_projected = NULL, updated_at = $4
+                WHERE id = $5
+                RETURNING id)
+            INSERT INTO cluster_tier (cluster_id, tier_at, calculated_reseller_tier_year_to_date, calculated_reseller_tier_minimum)
+            SELECT c.id, make_date($1, 1, 1), NULL, $3 FROM c`
+		tag, err := tx.Exec(ctx, q, e.FiscalYear, e.CalculatedNetRevenueLastYear, e.CalculatedResellerTierMinimum, e.OccurredAt, e.ID)
+		return sp.checkExec(err, tag, e, e.ID)
+
+	// Tiering
+	case core.TieringRecordedEvent:
+		q := `INSERT INTO tiering (id, tier_at, start, "end", version, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.TierAt, e.Start, e.End, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
+
+	// Rollover
+	case core.RolloverRecordedEvent:
+		q := `INSERT INTO rollover (id, fiscal_year, start, "end", version, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+		tag, err := tx.Exec(ctx, q, e.ID, e.FiscalYear, e.Start, e.End, 1, e.OccurredAt)
+		return sp.checkExec(err, tag, e, e.ID)
 	default:
 		panic(fmt.Sprintf("unhandled type: %T", e))
 	}
@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- idempotency_key
+--
+-- Records the first outcome of a command submitted with an Idempotency-Key
+-- header so that a retry replays the outcome rather than re-executing the
+-- command. Unexpected (infrastructure) errors aren't recorded, allowing a
+-- retry to re-execute.
+
+CREATE TABLE IF NOT EXISTS public.idempotency_key
+(
+    key character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    -- SHA-256 of request type and payload. A key reused with a different
+    -- fingerprint is rejected.
+    fingerprint character(64) COLLATE pg_catalog."default" NOT NULL,
+    -- pending, success, request_parse, conflict, not_found, data_stale, or
+    -- domain.
+    outcome character varying(20) COLLATE pg_catalog."default" NOT NULL,
+    error jsonb,
+    created_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone,
+    CONSTRAINT pk_idempotency_key_key PRIMARY KEY (key)
+);
+
+ALTER TABLE IF EXISTS public.idempotency_key
+    OWNER to postgres;
+
+-- +goose Down
+
+DROP TABLE IF EXISTS public.idempotency_key;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- exchange_rate
+--
+-- From is unique per currency rather than across currencies. Converting money
+-- between two currencies on a date requires both to have a rate from the same
+-- date, e.g., when rates are published daily for every currency.
+
+ALTER TABLE IF EXISTS public.exchange_rate
+    DROP CONSTRAINT IF EXISTS uq_exchange_rate_from;
+
+ALTER TABLE IF EXISTS public.exchange_rate
+    ADD CONSTRAINT uq_exchange_rate_currency_id_from UNIQUE (currency_id, "from");
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.exchange_rate
+    DROP CONSTRAINT IF EXISTS uq_exchange_rate_currency_id_from;
+
+ALTER TABLE IF EXISTS public.exchange_rate
+    ADD CONSTRAINT uq_exchange_rate_from UNIQUE ("from");
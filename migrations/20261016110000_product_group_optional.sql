@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- product
+--
+-- A product is created before it's assigned a product group, and may be
+-- unassigned again, so the product group is optional.
+
+ALTER TABLE IF EXISTS public.product
+    ALTER COLUMN product_group_id DROP NOT NULL;
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.product
+    ALTER COLUMN product_group_id SET NOT NULL;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- product_group_weight
+--
+-- From is unique per product group rather than across product groups. Weights
+-- of different product groups commonly change on the same date, e.g., at the
+-- start of a fiscal year.
+
+ALTER TABLE IF EXISTS public.product_group_weight
+    DROP CONSTRAINT IF EXISTS uq_product_group_from;
+
+ALTER TABLE IF EXISTS public.product_group_weight
+    ADD CONSTRAINT uq_product_group_weight_product_group_id_from UNIQUE (product_group_id, "from");
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.product_group_weight
+    DROP CONSTRAINT IF EXISTS uq_product_group_weight_product_group_id_from;
+
+ALTER TABLE IF EXISTS public.product_group_weight
+    ADD CONSTRAINT uq_product_group_from UNIQUE ("from");
@@ -0,0 +1,21 @@
+-- +goose Up
+
+-- revenue_group_limit
+--
+-- From is unique per revenue group rather than across revenue groups. Limits
+-- of different countries commonly change on the same date, e.g., at the start
+-- of a fiscal year.
+
+ALTER TABLE IF EXISTS public.revenue_group_limit
+    DROP CONSTRAINT IF EXISTS uq_revenue_group_limit_from;
+
+ALTER TABLE IF EXISTS public.revenue_group_limit
+    ADD CONSTRAINT uq_revenue_group_limit_revenue_group_id_from UNIQUE (revenue_group_id, "from");
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.revenue_group_limit
+    DROP CONSTRAINT IF EXISTS uq_revenue_group_limit_revenue_group_id_from;
+
+ALTER TABLE IF EXISTS public.revenue_group_limit
+    ADD CONSTRAINT uq_revenue_group_limit_from UNIQUE ("from");
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- cluster
+--
+-- The projected tier is derived from projected net revenue, which is kept for
+-- account managers to explain the projection.
+
+ALTER TABLE IF EXISTS public.cluster
+    ADD COLUMN IF NOT EXISTS calculated_net_revenue_projected numeric(12,2);
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.cluster
+    DROP COLUMN IF EXISTS calculated_net_revenue_projected;
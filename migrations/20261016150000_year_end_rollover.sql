@@ -0,0 +1,56 @@
+-- +goose Up
+
+-- reseller and cluster
+--
+-- The fiscal year is the year of year-to-date net revenue. It tells a year-end
+-- rollover which resellers and clusters are yet to be rolled over. Existing
+-- net revenue was calculated relative to the year it was last updated in.
+
+ALTER TABLE IF EXISTS public.reseller
+    ADD COLUMN IF NOT EXISTS fiscal_year int;
+
+UPDATE public.reseller
+SET fiscal_year = EXTRACT(YEAR FROM COALESCE(updated_at, created_at) AT TIME ZONE 'UTC');
+
+ALTER TABLE IF EXISTS public.reseller
+    ALTER COLUMN fiscal_year SET NOT NULL;
+
+ALTER TABLE IF EXISTS public.cluster
+    ADD COLUMN IF NOT EXISTS fiscal_year int;
+
+UPDATE public.cluster
+SET fiscal_year = EXTRACT(YEAR FROM COALESCE(updated_at, created_at) AT TIME ZONE 'UTC');
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN fiscal_year SET NOT NULL;
+
+-- rollover
+--
+-- Records that every reseller and cluster was rolled over from a fiscal year,
+-- like tiering records that every cluster was tiered on a date.
+
+CREATE TABLE IF NOT EXISTS public.rollover
+(
+    id uuid NOT NULL,
+    fiscal_year int NOT NULL,
+    start timestamp with time zone NOT NULL,
+    "end" timestamp with time zone NOT NULL,
+    version int NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone,
+    CONSTRAINT pk_rollover_id PRIMARY KEY (id),
+    CONSTRAINT uq_rollover_fiscal_year UNIQUE (fiscal_year)
+);
+
+ALTER TABLE IF EXISTS public.rollover
+    OWNER to postgres;
+
+-- +goose Down
+
+DROP TABLE IF EXISTS public.rollover;
+
+ALTER TABLE IF EXISTS public.cluster
+    DROP COLUMN IF EXISTS fiscal_year;
+
+ALTER TABLE IF EXISTS public.reseller
+    DROP COLUMN IF EXISTS fiscal_year;
@@ -0,0 +1,33 @@
+-- +goose Up
+
+-- cluster_tier
+--
+-- The tiers of a cluster from each tiering and rollover, so that the tier in
+-- force on a date is that of the latest row on or before it. A rollover starts
+-- the fiscal year on January 1 with the minimum tier alone. Existing clusters
+-- are assumed to have held their current tiers since last tiered.
+
+CREATE TABLE IF NOT EXISTS public.cluster_tier
+(
+    cluster_id uuid NOT NULL,
+    tier_at date NOT NULL,
+    calculated_reseller_tier_year_to_date character varying(10) COLLATE pg_catalog."default",
+    calculated_reseller_tier_minimum character varying(10) COLLATE pg_catalog."default",
+    CONSTRAINT pk_cluster_tier_cluster_id_tier_at PRIMARY KEY (cluster_id, tier_at),
+    CONSTRAINT fk_cluster_id FOREIGN KEY (cluster_id)
+        REFERENCES public.cluster (id) MATCH SIMPLE
+        ON UPDATE NO ACTION
+        ON DELETE CASCADE
+);
+
+ALTER TABLE IF EXISTS public.cluster_tier
+    OWNER to postgres;
+
+INSERT INTO public.cluster_tier (cluster_id, tier_at, calculated_reseller_tier_year_to_date, calculated_reseller_tier_minimum)
+SELECT id, last_tiered_at, calculated_reseller_tier_year_to_date, calculated_reseller_tier_minimum
+FROM public.cluster
+WHERE last_tiered_at IS NOT NULL;
+
+-- +goose Down
+
+DROP TABLE IF EXISTS public.cluster_tier;
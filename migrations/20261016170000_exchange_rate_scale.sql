@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- exchange_rate
+--
+-- A rate is the value of one unit of its currency in the reporting currency.
+-- Currencies like JPY are worth a fraction of a unit of currencies like EUR,
+-- and currencies like KWD many units, so rates need both more decimal places
+-- and more integer digits.
+
+ALTER TABLE IF EXISTS public.exchange_rate
+    ALTER COLUMN rate TYPE numeric(16,10);
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.exchange_rate
+    ALTER COLUMN rate TYPE numeric(10,6);
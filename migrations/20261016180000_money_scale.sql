@@ -0,0 +1,40 @@
+-- +goose Up
+
+-- billing, billing_item, reseller, cluster
+--
+-- Amounts have at most the minor units of their currency, which is three for
+-- currencies like KWD and BHD, so money columns need three decimal places.
+
+ALTER TABLE IF EXISTS public.billing_item
+    ALTER COLUMN gross_revenue TYPE numeric(13,3),
+    ALTER COLUMN calculated_net_revenue TYPE numeric(13,3);
+
+ALTER TABLE IF EXISTS public.billing
+    ALTER COLUMN calculated_net_revenue TYPE numeric(13,3);
+
+ALTER TABLE IF EXISTS public.reseller
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(13,3),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(13,3);
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(13,3),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(13,3),
+    ALTER COLUMN calculated_net_revenue_projected TYPE numeric(13,3);
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(12,2),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(12,2),
+    ALTER COLUMN calculated_net_revenue_projected TYPE numeric(12,2);
+
+ALTER TABLE IF EXISTS public.reseller
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(12,2),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(12,2);
+
+ALTER TABLE IF EXISTS public.billing
+    ALTER COLUMN calculated_net_revenue TYPE numeric(12,2);
+
+ALTER TABLE IF EXISTS public.billing_item
+    ALTER COLUMN gross_revenue TYPE numeric(12,2),
+    ALTER COLUMN calculated_net_revenue TYPE numeric(12,2);
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- idempotency_key
+--
+-- Completed keys past their TTL are purged by completed_at.
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_completed_at
+    ON public.idempotency_key USING btree
+    (completed_at ASC NULLS LAST)
+    WITH (deduplicate_items=True)
+    TABLESPACE pg_default;
+
+-- +goose Down
+
+DROP INDEX IF EXISTS public.idx_idempotency_key_completed_at;
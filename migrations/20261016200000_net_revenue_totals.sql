@@ -0,0 +1,25 @@
+-- +goose Up
+
+-- reseller, cluster
+--
+-- Year-to-date and last-year net revenue accumulate billings without bound, and
+-- in low-value currencies like JPY quickly outgrow a single billing's
+-- numeric(13,3).
+
+ALTER TABLE IF EXISTS public.reseller
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(20,3),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(20,3);
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(20,3),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(20,3);
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(13,3),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(13,3);
+
+ALTER TABLE IF EXISTS public.reseller
+    ALTER COLUMN calculated_net_revenue_year_to_date TYPE numeric(13,3),
+    ALTER COLUMN calculated_net_revenue_last_year TYPE numeric(13,3);
@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- cluster
+--
+-- A linear projection early in the year multiplies year-to-date net revenue by
+-- up to 366, so projected net revenue is sized like year to date.
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN calculated_net_revenue_projected TYPE numeric(20,3);
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.cluster
+    ALTER COLUMN calculated_net_revenue_projected TYPE numeric(13,3);
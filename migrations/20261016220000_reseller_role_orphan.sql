@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- reseller
+--
+-- A reseller's role follows from cluster membership, so a reseller outside a
+-- cluster is an orphan. Resellers given a role before then are made orphans,
+-- or they couldn't be unenrolled.
+
+UPDATE public.reseller
+SET role = 'Orphan'
+WHERE cluster_id IS NULL AND role <> 'Orphan';
+
+-- +goose Down
+
+-- The roles replaced aren't kept, so there's nothing to restore.
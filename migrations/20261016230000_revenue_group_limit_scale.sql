@@ -0,0 +1,24 @@
+-- +goose Up
+
+-- revenue_group_limit
+--
+-- Limits have at most the minor units of the revenue group's currency, up to
+-- three for currencies like KWD and BHD, and limits in low-value currencies
+-- like JPY need more integer digits. Existing limits in currencies without
+-- minor units are rounded to whole units, or they would no longer parse.
+
+ALTER TABLE IF EXISTS public.revenue_group_limit
+    ALTER COLUMN authorized TYPE numeric(16,3),
+    ALTER COLUMN advanced TYPE numeric(16,3),
+    ALTER COLUMN premier TYPE numeric(16,3);
+
+UPDATE public.revenue_group_limit
+SET authorized = round(authorized), advanced = round(advanced), premier = round(premier)
+WHERE currency_code IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'VND', 'VUV', 'XAF', 'XOF', 'XPF');
+
+-- +goose Down
+
+ALTER TABLE IF EXISTS public.revenue_group_limit
+    ALTER COLUMN authorized TYPE numeric(12,2),
+    ALTER COLUMN advanced TYPE numeric(12,2),
+    ALTER COLUMN premier TYPE numeric(12,2);
@@ -0,0 +1,245 @@
+package cluster_test
+
+import (
+	"context"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type ClusterTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (ct *ClusterTests) SetupSuite() {
+	ct.ctx = context.Background()
+	ct.config = testutil.LoadConfig()
+	ct.clock = &testutil.SwitchableClock{}
+	ct.dispatcher = infrastructure.NewDispatcher(ct.ctx, *testutil.Config, infrastructure.WithClock(ct.clock))
+}
+
+func (ct *ClusterTests) TearDownSuite() {
+	ct.dispatcher.Close()
+}
+
+func (ct *ClusterTests) cleanUp() {
+	testutil.ResetDB(ct.ctx, ct.dispatcher.PgxPool)
+}
+
+// setupResellers creates what a cluster depends on.
+func (ct *ClusterTests) setupResellers(t *rapid.T, fx CreateClusterValidFixture) {
+	ct.clock.Current = fx.Clock
+	_, err := ct.dispatcher.CreateCurrency(ct.ctx, core.CreateCurrencyCommand{ID: uuid.New(), Code: fx.CurrencyCode})
+	require.NoError(t, err)
+	createRevenueGroup := core.CreateRevenueGroupCommand{
+		ID:           fx.CreateCluster.RevenueGroupID,
+		CountryCode:  fx.CountryCode,
+		CurrencyCode: fx.CurrencyCode,
+	}
+	_, err = ct.dispatcher.CreateRevenueGroup(ct.ctx, createRevenueGroup)
+	require.NoError(t, err)
+	for _, enroll := range fx.EnrollResellers {
+		_, err := ct.dispatcher.EnrollReseller(ct.ctx, enroll)
+		require.NoError(t, err)
+	}
+}
+
+func (ct *ClusterTests) setup(t *rapid.T, fx CreateClusterValidFixture) {
+	ct.setupResellers(t, fx)
+	_, err := ct.dispatcher.CreateCluster(ct.ctx, fx.CreateCluster)
+	require.NoError(t, err)
+}
+
+func (ct *ClusterTests) role(t *rapid.T, resellerID uuid.UUID) core.ResellerRole {
+	r, err := ct.dispatcher.GetReseller(ct.ctx, core.GetResellerQuery{ID: resellerID})
+	require.NoError(t, err)
+	return r.Role
+}
+
+func (ct *ClusterTests) TestCreateClusterValid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genCreateClusterValid().Draw(t, "fx")
+
+		ct.setup(t, fx)
+
+		c, err := ct.dispatcher.GetCluster(ct.ctx, fx.GetCluster)
+		require.NoError(t, err)
+		assert.Equal(t, fx.CreateCluster.ExternalID, c.ExternalID)
+		assert.Equal(t, fx.CreateCluster.RevenueGroupID, c.RevenueGroupID)
+		require.Len(t, c.Members, 1)
+		assert.Equal(t, core.ClusterMemberResponse{ResellerID: fx.CreateCluster.HeadResellerID, Role: core.ResellerRoleHead}, c.Members[0])
+		assert.Equal(t, core.ResellerRoleHead, ct.role(t, fx.CreateCluster.HeadResellerID))
+	})
+}
+
+func (ct *ClusterTests) TestCreateClusterUnknownRevenueGroupInvalid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genCreateClusterValid().Draw(t, "fx")
+		ct.setupResellers(t, fx)
+		fx.CreateCluster.RevenueGroupID = uuid.New()
+
+		_, err := ct.dispatcher.CreateCluster(ct.ctx, fx.CreateCluster)
+
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "RevenueGroup", e.Entity)
+	})
+}
+
+func (ct *ClusterTests) TestAddClusterMemberValid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddClusterMember().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+
+		_, err := ct.dispatcher.AddClusterMember(ct.ctx, fx.AddClusterMember)
+		require.NoError(t, err)
+
+		c, err := ct.dispatcher.GetCluster(ct.ctx, fx.Base.GetCluster)
+		require.NoError(t, err)
+		require.Len(t, c.Members, 2)
+		assert.Equal(t, core.ResellerRoleHead, c.Members[0].Role)
+		assert.Equal(t, core.ClusterMemberResponse{ResellerID: fx.AddClusterMember.ResellerID, Role: core.ResellerRoleMember}, c.Members[1])
+		assert.Equal(t, core.ResellerRoleMember, ct.role(t, fx.AddClusterMember.ResellerID))
+	})
+}
+
+func (ct *ClusterTests) TestAddClusterMemberOfOtherClusterInvalid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddClusterMember().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+		other := fx.Base.CreateCluster
+		other.ID = uuid.New()
+		other.ExternalID = uuid.New()
+		other.HeadResellerID = fx.AddClusterMember.ResellerID
+		_, err := ct.dispatcher.CreateCluster(ct.ctx, other)
+		require.NoError(t, err)
+
+		_, err = ct.dispatcher.AddClusterMember(ct.ctx, fx.AddClusterMember)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ResellerExpectedNoCluster, e.Code)
+	})
+}
+
+func (ct *ClusterTests) TestRemoveClusterMemberValid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddClusterMember().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+		_, err := ct.dispatcher.AddClusterMember(ct.ctx, fx.AddClusterMember)
+		require.NoError(t, err)
+
+		remove := core.RemoveClusterMemberCommand{ClusterID: fx.AddClusterMember.ClusterID, ResellerID: fx.AddClusterMember.ResellerID}
+		_, err = ct.dispatcher.RemoveClusterMember(ct.ctx, remove)
+		require.NoError(t, err)
+
+		c, err := ct.dispatcher.GetCluster(ct.ctx, fx.Base.GetCluster)
+		require.NoError(t, err)
+		assert.Len(t, c.Members, 1)
+		assert.Equal(t, core.ResellerRoleOrphan, ct.role(t, fx.AddClusterMember.ResellerID))
+	})
+}
+
+func (ct *ClusterTests) TestRemoveClusterMemberHeadInvalid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genCreateClusterValid().Draw(t, "fx")
+		ct.setup(t, fx)
+
+		remove := core.RemoveClusterMemberCommand{ClusterID: fx.CreateCluster.ID, ResellerID: fx.CreateCluster.HeadResellerID}
+		_, err := ct.dispatcher.RemoveClusterMember(ct.ctx, remove)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ClusterExpectedNonHeadForRemoval, e.Code)
+	})
+}
+
+func (ct *ClusterTests) TestChangeClusterHeadValid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddClusterMember().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+		_, err := ct.dispatcher.AddClusterMember(ct.ctx, fx.AddClusterMember)
+		require.NoError(t, err)
+		headID := fx.Base.CreateCluster.HeadResellerID
+		memberID := fx.AddClusterMember.ResellerID
+
+		change := core.ChangeClusterHeadCommand{ClusterID: fx.AddClusterMember.ClusterID, ResellerID: memberID}
+		_, err = ct.dispatcher.ChangeClusterHead(ct.ctx, change)
+		require.NoError(t, err)
+
+		c, err := ct.dispatcher.GetCluster(ct.ctx, fx.Base.GetCluster)
+		require.NoError(t, err)
+		require.Len(t, c.Members, 2)
+		assert.Equal(t, core.ClusterMemberResponse{ResellerID: memberID, Role: core.ResellerRoleHead}, c.Members[0])
+		assert.Equal(t, core.ClusterMemberResponse{ResellerID: headID, Role: core.ResellerRoleMember}, c.Members[1])
+		assert.Equal(t, core.ResellerRoleHead, ct.role(t, memberID))
+		assert.Equal(t, core.ResellerRoleMember, ct.role(t, headID))
+
+		// The previous head may now leave the cluster and be unenrolled.
+		remove := core.RemoveClusterMemberCommand{ClusterID: fx.AddClusterMember.ClusterID, ResellerID: headID}
+		_, err = ct.dispatcher.RemoveClusterMember(ct.ctx, remove)
+		require.NoError(t, err)
+		_, err = ct.dispatcher.UnenrollReseller(ct.ctx, core.UnenrollResellerCommand{ID: headID})
+		require.NoError(t, err)
+	})
+}
+
+func (ct *ClusterTests) TestChangeClusterHeadSameInvalid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genCreateClusterValid().Draw(t, "fx")
+		ct.setup(t, fx)
+
+		change := core.ChangeClusterHeadCommand{ClusterID: fx.CreateCluster.ID, ResellerID: fx.CreateCluster.HeadResellerID}
+		_, err := ct.dispatcher.ChangeClusterHead(ct.ctx, change)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ClusterExpectedNonHeadForHead, e.Code)
+	})
+}
+
+func (ct *ClusterTests) TestRemoveClusterValid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddClusterMember().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+		_, err := ct.dispatcher.AddClusterMember(ct.ctx, fx.AddClusterMember)
+		require.NoError(t, err)
+
+		_, err = ct.dispatcher.RemoveCluster(ct.ctx, core.RemoveClusterCommand{ID: fx.Base.CreateCluster.ID})
+		require.NoError(t, err)
+
+		_, err = ct.dispatcher.GetCluster(ct.ctx, fx.Base.GetCluster)
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+		for _, id := range []uuid.UUID{fx.Base.CreateCluster.HeadResellerID, fx.AddClusterMember.ResellerID} {
+			assert.Equal(t, core.ResellerRoleOrphan, ct.role(t, id))
+		}
+		// The head, which couldn't leave the cluster, may now be unenrolled.
+		_, err = ct.dispatcher.UnenrollReseller(ct.ctx, core.UnenrollResellerCommand{ID: fx.Base.CreateCluster.HeadResellerID})
+		require.NoError(t, err)
+	})
+}
+
+func TestCluster(t *testing.T) {
+	suite.Run(t, new(ClusterTests))
+}
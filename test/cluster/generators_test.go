@@ -0,0 +1,81 @@
+package cluster_test
+
+import (
+	"strings"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genCountryCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CountryCodes, strings.Compare)
+}
+
+func genCurrencyCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CurrencyCodes, strings.Compare)
+}
+
+type CreateClusterValidFixture struct {
+	Clock           core.Clock
+	CountryCode     string
+	CurrencyCode    string
+	EnrollResellers []core.EnrollResellerCommand
+	CreateCluster   core.CreateClusterCommand
+	GetCluster      core.GetClusterQuery
+}
+
+// genCreateClusterValid enrolls a head and a few resellers which tests may
+// add as members.
+func genCreateClusterValid() *rapid.Generator[CreateClusterValidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateClusterValidFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		countryCode := genCountryCode().Draw(t, "country_code")
+		currencyCode := genCurrencyCode().Draw(t, "currency_code")
+		ids := rapid.SliceOfNDistinct(testutil.GenUUID(), 2, 4, rapid.ID).Draw(t, "reseller_ids")
+		enrolls := make([]core.EnrollResellerCommand, len(ids))
+		for i, id := range ids {
+			enrolls[i] = core.EnrollResellerCommand{
+				ID:           id,
+				ExternalID:   testutil.GenUUID().Draw(t, "external_id"),
+				CountryCode:  countryCode,
+				CurrencyCode: currencyCode,
+				EnrolledAt:   testutil.GenDateBetween(core.ResellerEnrolledAtMin, core.ResellerEnrolledAtMax).Draw(t, "enrolled_at"),
+			}
+		}
+		create := core.CreateClusterCommand{
+			ID:             testutil.GenUUID().Draw(t, "id"),
+			ExternalID:     testutil.GenUUID().Draw(t, "external_id"),
+			RevenueGroupID: testutil.GenUUID().Draw(t, "revenue_group_id"),
+			HeadResellerID: enrolls[0].ID,
+		}
+		return CreateClusterValidFixture{
+			Clock:           clock,
+			CountryCode:     countryCode,
+			CurrencyCode:    currencyCode,
+			EnrollResellers: enrolls,
+			CreateCluster:   create,
+			GetCluster:      core.GetClusterQuery{ID: create.ID},
+		}
+	})
+}
+
+type AddClusterMemberFixture struct {
+	Base             CreateClusterValidFixture
+	AddClusterMember core.AddClusterMemberCommand
+}
+
+func genAddClusterMember() *rapid.Generator[AddClusterMemberFixture] {
+	return rapid.Custom(func(t *rapid.T) AddClusterMemberFixture {
+		base := genCreateClusterValid().Draw(t, "base")
+		member := rapid.SampledFrom(base.EnrollResellers[1:]).Draw(t, "member")
+		add := core.AddClusterMemberCommand{
+			ClusterID:  base.CreateCluster.ID,
+			ResellerID: member.ID,
+		}
+		return AddClusterMemberFixture{
+			Base:             base,
+			AddClusterMember: add,
+		}
+	})
+}
@@ -2,7 +2,12 @@ package currency_test
 
 import (
 	"context"
+	"errors"
+	"os"
+	"slices"
 	"testing"
+	"time"
+	"uuid"
 
 	"github.com/ronnieholm/resellerloyalty/internal/core"
 	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
@@ -59,6 +64,38 @@ func (ct *CurrencyTests) TestCreateCurrencyValid() {
 	})
 }
 
+func (ct *CurrencyTests) TestListCurrenciesPagination() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genListCurrencies().Draw(t, "fx")
+		ct.clock.Current = fx.Clock
+		var expected []string
+		for _, create := range fx.CreateCurrencies {
+			_, err := ct.dispatcher.CreateCurrency(ct.ctx, create)
+			require.NoError(t, err)
+			expected = append(expected, create.Code)
+		}
+		slices.Sort(expected)
+
+		var actual []string
+		qry := core.ListCurrenciesQuery{Limit: fx.Limit}
+		for {
+			res, err := ct.dispatcher.ListCurrencies(ct.ctx, qry)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(res.Items), fx.Limit)
+			for _, c := range res.Items {
+				actual = append(actual, c.Code)
+			}
+			if res.NextCursor == "" {
+				break
+			}
+			qry.Cursor = res.NextCursor
+		}
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
 func (ct *CurrencyTests) TestCreateCurrencyDuplicateIDInvalid() {
 	rapid.Check(ct.T(), func(t *rapid.T) {
 		ct.cleanUp()
@@ -116,6 +153,118 @@ func (ct *CurrencyTests) TestAddExchangeRateFromDateBoundary() {
 	})
 }
 
+func (ct *CurrencyTests) TestAddExchangeRateExpectedVersion() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddExchangeRateExpectedVersion().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+
+		_, err := ct.dispatcher.AddExchangeRate(ct.ctx, fx.AddExchangeRate)
+
+		if fx.ShouldPass {
+			require.NoError(t, err)
+			c, err := ct.dispatcher.GetCurrency(ct.ctx, fx.Base.GetCurrecy)
+			require.NoError(t, err)
+			assert.Equal(t, *fx.AddExchangeRate.ExpectedVersion+1, c.Version)
+		} else {
+			var e *core.DataStaleError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, "Currency", e.Aggregate)
+			assert.Equal(t, fx.Base.CreateCurrency.ID, e.ID)
+		}
+	})
+}
+
+func (ct *CurrencyTests) TestAddExchangeRateIdempotencyKeyReplay() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddExchangeRateIdempotencyKey().Draw(t, "fx")
+		ct.setup(t, fx.Boundary.Base)
+		ctx := infrastructure.WithIdempotencyKey(ct.ctx, fx.Key)
+		_, first := ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+
+		_, second := ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+
+		// Without replay, the second call would fail on a duplicate ID.
+		assert.Equal(t, first, second)
+		c, err := ct.dispatcher.GetCurrency(ct.ctx, fx.Boundary.Base.GetCurrecy)
+		require.NoError(t, err)
+		if fx.Boundary.ShouldPass {
+			assert.Len(t, c.ExchangeRates, 1)
+		} else {
+			assert.Len(t, c.ExchangeRates, 0)
+		}
+	})
+}
+
+func (ct *CurrencyTests) TestAddExchangeRateIdempotencyKeyReusedInvalid() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddExchangeRateIdempotencyKey().Draw(t, "fx")
+		ct.setup(t, fx.Boundary.Base)
+		ctx := infrastructure.WithIdempotencyKey(ct.ctx, fx.Key)
+		_, _ = ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+
+		_, err := ct.dispatcher.AddExchangeRate(ctx, fx.Other)
+
+		var e *infrastructure.IdempotencyKeyReusedError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, fx.Key, e.Key)
+	})
+}
+
+func (ct *CurrencyTests) TestAddExchangeRateIdempotencyKeyPendingTakeover() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddExchangeRateIdempotencyKey().Draw(t, "fx")
+		ct.setup(t, fx.Boundary.Base)
+		ctx := infrastructure.WithIdempotencyKey(ct.ctx, fx.Key)
+		_, _ = ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+
+		// As if the process died before recording the outcome.
+		reserved := ct.clock.NowUTC()
+		q := "UPDATE idempotency_key SET outcome = 'pending', error = NULL, completed_at = NULL, created_at = $1 WHERE key = $2"
+		_, err := ct.dispatcher.PgxPool.Exec(ct.ctx, q, reserved, fx.Key)
+		require.NoError(t, err)
+
+		_, err = ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "IdempotencyKey", e.Entity)
+
+		ct.clock.Current = &testutil.FakeClock{Now: reserved.Add(time.Hour)}
+		_, err = ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+		if errors.As(err, &e) {
+			assert.NotEqual(t, "IdempotencyKey", e.Entity)
+		}
+		var outcome string
+		q = "SELECT outcome FROM idempotency_key WHERE key = $1"
+		require.NoError(t, ct.dispatcher.PgxPool.QueryRow(ct.ctx, q, fx.Key).Scan(&outcome))
+		assert.NotEqual(t, "pending", outcome)
+	})
+}
+
+func (ct *CurrencyTests) TestAddExchangeRateIdempotencyKeyExpired() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genAddExchangeRateIdempotencyKey().Draw(t, "fx")
+		ct.setup(t, fx.Boundary.Base)
+		ctx := infrastructure.WithIdempotencyKey(ct.ctx, fx.Key)
+		_, _ = ct.dispatcher.AddExchangeRate(ctx, fx.Boundary.AddExchangeRate)
+
+		// A day and more later, the key is as if never used.
+		ct.clock.Current = &testutil.FakeClock{Now: ct.clock.NowUTC().Add(25 * time.Hour)}
+		_, err := ct.dispatcher.AddExchangeRate(ctx, fx.Other)
+		var e *infrastructure.IdempotencyKeyReusedError
+		assert.False(t, errors.As(err, &e))
+
+		ct.clock.Current = &testutil.FakeClock{Now: ct.clock.NowUTC().Add(25 * time.Hour)}
+		n, err := ct.dispatcher.PurgeIdempotencyKeys(ct.ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+}
+
 func (ct *CurrencyTests) TestAddExchangeRateDuplicateFromInvalid() {
 	rapid.Check(ct.T(), func(t *rapid.T) {
 		ct.cleanUp()
@@ -312,6 +461,163 @@ func (ct *CurrencyTests) TestRemoveExchangeRateIDInvalid() {
 	})
 }
 
+func (ct *CurrencyTests) TestGetEffectiveExchangeRate() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genGetEffectiveExchangeRate().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+		var expected *core.AddExchangeRateCommand
+		for _, add := range fx.AddExchangeRates {
+			_, err := ct.dispatcher.AddExchangeRate(ct.ctx, add)
+			require.NoError(t, err)
+			if !add.From.After(fx.On) && (expected == nil || add.From.After(expected.From)) {
+				expected = &add
+			}
+		}
+
+		qry := core.GetEffectiveExchangeRateQuery{Code: fx.Base.CreateCurrency.Code, On: fx.On}
+		e, err := ct.dispatcher.GetEffectiveExchangeRate(ct.ctx, qry)
+
+		if expected == nil {
+			var noRate *core.NoExchangeRateError
+			require.ErrorAs(t, err, &noRate)
+			assert.Equal(t, qry.Code, noRate.Code)
+			assert.Equal(t, qry.On, noRate.On)
+			return
+		}
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID, e.ID)
+		assert.Equal(t, expected.Rate, e.Rate)
+		assert.True(t, expected.From.Equal(e.From))
+	})
+}
+
+func (ct *CurrencyTests) TestReplaceFutureExchangeRates() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genReplaceFutureExchangeRates().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+		for _, add := range fx.AddExchangeRates {
+			_, err := ct.dispatcher.AddExchangeRate(ct.ctx, add)
+			require.NoError(t, err)
+		}
+
+		_, err := ct.dispatcher.ReplaceFutureExchangeRates(ct.ctx, fx.Replace)
+
+		require.NoError(t, err)
+		c, err := ct.dispatcher.GetCurrency(ct.ctx, fx.Base.GetCurrecy)
+		require.NoError(t, err)
+		expected := map[string]core.Decimal{}
+		for _, e := range fx.Replace.ExchangeRates {
+			expected[e.From.String()] = e.Rate
+		}
+		actual := map[string]core.Decimal{}
+		for _, e := range c.ExchangeRates {
+			actual[e.From.String()] = e.Rate
+		}
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func (ct *CurrencyTests) TestImportExchangeRates() {
+	rapid.Check(ct.T(), func(t *rapid.T) {
+		ct.cleanUp()
+		fx := genImportExchangeRates().Draw(t, "fx")
+		ct.setup(t, fx.Base)
+
+		report, err := ct.dispatcher.ImportExchangeRates(ct.ctx, fx.Import)
+
+		require.NoError(t, err)
+		require.Len(t, report.Accepted, 1)
+		assert.Equal(t, fx.Valid.Line, report.Accepted[0].Line)
+		assert.Len(t, report.Rejected, 2)
+		c, err := ct.dispatcher.GetCurrency(ct.ctx, fx.Base.GetCurrecy)
+		require.NoError(t, err)
+		if fx.Import.DryRun {
+			assert.Empty(t, c.ExchangeRates)
+			return
+		}
+		require.Len(t, c.ExchangeRates, 1)
+		assert.Equal(t, fx.Valid.ID, c.ExchangeRates[0].ID)
+	})
+}
+
+func (ct *CurrencyTests) TestImportExchangeRatesECB() {
+	ct.cleanUp()
+	t := ct.T()
+	// The daily file is published the afternoon of the day its rates are from
+	// and imported the day after at the latest.
+	ct.clock.Current = &testutil.FakeClock{Now: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)}
+	for _, code := range []string{"GBP", "JPY"} {
+		_, err := ct.dispatcher.CreateCurrency(ct.ctx, core.CreateCurrencyCommand{ID: uuid.NewV7(), Code: code})
+		require.NoError(t, err)
+	}
+	f, err := os.Open("testdata/eurofxref-daily.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := infrastructure.ReadECBExchangeRates(f)
+	require.NoError(t, err)
+
+	report, err := ct.dispatcher.ImportExchangeRates(ct.ctx, core.ImportExchangeRatesCommand{Rows: rows})
+
+	// Rows of currencies not created are rejected.
+	require.NoError(t, err)
+	require.Len(t, report.Accepted, 2)
+	assert.Len(t, report.Rejected, len(rows)-2)
+	on := core.NewDate(2026, 10, 15)
+	gbp, err := ct.dispatcher.GetEffectiveExchangeRate(ct.ctx, core.GetEffectiveExchangeRateQuery{Code: "GBP", On: on})
+	require.NoError(t, err)
+	assert.Equal(t, core.MustParseDecimal("1.1746050391"), gbp.Rate)
+	jpy, err := ct.dispatcher.GetEffectiveExchangeRate(ct.ctx, core.GetEffectiveExchangeRateQuery{Code: "JPY", On: on})
+	require.NoError(t, err)
+	assert.Equal(t, core.MustParseDecimal("0.0061950192"), jpy.Rate)
+
+	// 100 GBP at 161.42 JPY and 0.85135 GBP per EUR is about 18960.47 JPY.
+	m, err := ct.dispatcher.ConvertMoney(ct.ctx, core.ConvertMoneyQuery{
+		Amount:   core.NewDecimalFromInt(100),
+		FromCode: "GBP",
+		ToCode:   "JPY",
+		On:       on,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, core.Money{Amount: core.NewDecimalFromInt(18960), Code: "JPY"}, *m)
+}
+
+func (ct *CurrencyTests) TestImportExchangeRatesHistorical() {
+	ct.cleanUp()
+	t := ct.T()
+	ct.clock.Current = &testutil.FakeClock{Now: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)}
+	_, err := ct.dispatcher.CreateCurrency(ct.ctx, core.CreateCurrencyCommand{ID: uuid.NewV7(), Code: "GBP"})
+	require.NoError(t, err)
+	row := func(line int, rate string, from string) core.ExchangeRateImportRow {
+		return core.ExchangeRateImportRow{Line: line, ID: uuid.NewV7(), Code: "GBP", Rate: rate, From: from}
+	}
+
+	// Like a historical file, the latest rate is listed first.
+	report, err := ct.dispatcher.ImportExchangeRates(ct.ctx, core.ImportExchangeRatesCommand{Rows: []core.ExchangeRateImportRow{
+		row(1, "1.17", "2026-10-15"),
+		row(2, "1.16", "2026-10-01"),
+	}})
+	require.NoError(t, err)
+	require.Len(t, report.Accepted, 2)
+	assert.Equal(t, 1, report.Accepted[0].Line)
+	assert.Empty(t, report.Rejected)
+
+	// A rate between existing rates would change the rate in effect on
+	// days already converted at the earlier rate.
+	report, err = ct.dispatcher.ImportExchangeRates(ct.ctx, core.ImportExchangeRatesCommand{Rows: []core.ExchangeRateImportRow{
+		row(1, "1.18", "2026-10-08"),
+	}})
+	require.NoError(t, err)
+	assert.Empty(t, report.Accepted)
+	require.Len(t, report.Rejected, 1)
+	assert.Contains(t, report.Rejected[0].FieldErrors, "Row")
+	on := core.NewDate(2026, 10, 8)
+	gbp, err := ct.dispatcher.GetEffectiveExchangeRate(ct.ctx, core.GetEffectiveExchangeRateQuery{Code: "GBP", On: on})
+	require.NoError(t, err)
+	assert.Equal(t, core.MustParseDecimal("1.16"), gbp.Rate)
+}
+
 func TestCurrency(t *testing.T) {
 	suite.Run(t, new(CurrencyTests))
 }
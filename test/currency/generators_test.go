@@ -1,7 +1,6 @@
 package currency_test
 
 import (
-	"math"
 	"strings"
 	"uuid"
 
@@ -44,6 +43,29 @@ func genCreateCurrencyValid() *rapid.Generator[CreateCurrencyValidFixture] {
 	})
 }
 
+type ListCurrenciesFixture struct {
+	Clock            core.Clock
+	CreateCurrencies []core.CreateCurrencyCommand
+	Limit            int
+}
+
+func genListCurrencies() *rapid.Generator[ListCurrenciesFixture] {
+	return rapid.Custom(func(t *rapid.T) ListCurrenciesFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		codes := rapid.SliceOfNDistinct(genCurrencyCode(), 1, 3, rapid.ID).Draw(t, "codes")
+		creates := make([]core.CreateCurrencyCommand, len(codes))
+		for i, code := range codes {
+			creates[i] = genCreateCurrencyCommand().Draw(t, "create_currency")
+			creates[i].Code = code
+		}
+		return ListCurrenciesFixture{
+			Clock:            clock,
+			CreateCurrencies: creates,
+			Limit:            rapid.IntRange(core.PageLimitMin, len(codes)).Draw(t, "limit"),
+		}
+	})
+}
+
 type CreateCurrencyDuplicateIDInvalidFixture struct {
 	Base           CreateCurrencyValidFixture
 	CreateCurrency core.CreateCurrencyCommand
@@ -73,15 +95,8 @@ func genCreateCurrencyDuplicateCodeInvalid() *rapid.Generator[CreateCurrencyDupl
 	})
 }
 
-func genExchangeRateRate() *rapid.Generator[float64] {
-	return rapid.Custom(func(t *rapid.T) float64 {
-		decimalPlaces := rapid.
-			IntRange(core.ExchangeRateDecimalPlacesMin, core.ExchangeRateDecimalPlacesMax).
-			Draw(t, "decimal_places")
-		rate := rapid.Float64Range(core.ExchangeRateMin, core.ExchangeRateMax).Draw(t, "rate")
-		ratio := math.Pow10(decimalPlaces)
-		return math.Round(rate*ratio) / ratio
-	})
+func genExchangeRateRate() *rapid.Generator[core.Decimal] {
+	return testutil.GenDecimalBetween(core.ExchangeRateMin, core.ExchangeRateMax, core.ExchangeRateDecimalPlacesMax)
 }
 
 func genExchangeRateFrom() *rapid.Generator[core.Date] {
@@ -140,6 +155,59 @@ func genAddExchangeRateFromDateBoundary() *rapid.Generator[AddExchangeRateFromDa
 	})
 }
 
+type AddExchangeRateExpectedVersionFixture = struct {
+	Base            CreateCurrencyValidFixture
+	AddExchangeRate core.AddExchangeRateCommand
+	ShouldPass      bool
+}
+
+func genAddExchangeRateExpectedVersion() *rapid.Generator[AddExchangeRateExpectedVersionFixture] {
+	return rapid.Custom(func(t *rapid.T) AddExchangeRateExpectedVersionFixture {
+		base := genCreateCurrencyValid().Draw(t, "base")
+		today := base.Clock.Today()
+
+		add := genAddExchangeRateCommand().Draw(t, "add_exchange_rate")
+		add.Code = base.CreateCurrency.Code
+		add.From = genExchangeRateFromAfter(today).Draw(t, "from")
+
+		// A newly created currency is at version 1.
+		version := rapid.Int32Range(0, 3).Draw(t, "expected_version")
+		add.ExpectedVersion = &version
+
+		return AddExchangeRateExpectedVersionFixture{
+			Base:            base,
+			AddExchangeRate: add,
+			ShouldPass:      version == 1,
+		}
+	})
+}
+
+func genIdempotencyKey() *rapid.Generator[string] {
+	return rapid.StringMatching(`[a-zA-Z0-9-]{1,64}`)
+}
+
+type AddExchangeRateIdempotencyKeyFixture = struct {
+	Boundary AddExchangeRateFromDateBoundaryFixture
+	Key      string
+	// A different request submitted with the same key.
+	Other core.AddExchangeRateCommand
+}
+
+func genAddExchangeRateIdempotencyKey() *rapid.Generator[AddExchangeRateIdempotencyKeyFixture] {
+	return rapid.Custom(func(t *rapid.T) AddExchangeRateIdempotencyKeyFixture {
+		boundary := genAddExchangeRateFromDateBoundary().Draw(t, "boundary")
+		other := boundary.AddExchangeRate
+		other.Rate = genExchangeRateRate().
+			Filter(func(r core.Decimal) bool { return r != boundary.AddExchangeRate.Rate }).
+			Draw(t, "other_rate")
+		return AddExchangeRateIdempotencyKeyFixture{
+			Boundary: boundary,
+			Key:      genIdempotencyKey().Draw(t, "key"),
+			Other:    other,
+		}
+	})
+}
+
 type AddExchangeRateDuplicateFromInvalidFixture = struct {
 	Base             CreateCurrencyValidFixture
 	AddExchangeRate1 core.AddExchangeRateCommand
@@ -170,6 +238,117 @@ func genAddExchangeRateDuplicateFromInvalid() *rapid.Generator[AddExchangeRateDu
 	})
 }
 
+type GetEffectiveExchangeRateFixture struct {
+	Base             CreateCurrencyValidFixture
+	AddExchangeRates []core.AddExchangeRateCommand
+	On               core.Date
+}
+
+func genGetEffectiveExchangeRate() *rapid.Generator[GetEffectiveExchangeRateFixture] {
+	return rapid.Custom(func(t *rapid.T) GetEffectiveExchangeRateFixture {
+		base := genCreateCurrencyValid().Draw(t, "base")
+		froms := rapid.SliceOfNDistinct(genExchangeRateFromAfter(base.Clock.Today()), 0, 3, core.Date.String).
+			Draw(t, "froms")
+
+		adds := make([]core.AddExchangeRateCommand, len(froms))
+		for i, from := range froms {
+			adds[i] = genAddExchangeRateCommand().Draw(t, "add_exchange_rate")
+			adds[i].Code = base.CreateCurrency.Code
+			adds[i].From = from
+		}
+
+		return GetEffectiveExchangeRateFixture{
+			Base:             base,
+			AddExchangeRates: adds,
+			On:               genExchangeRateFrom().Draw(t, "on"),
+		}
+	})
+}
+
+type ReplaceFutureExchangeRatesFixture struct {
+	Base             CreateCurrencyValidFixture
+	AddExchangeRates []core.AddExchangeRateCommand
+	Replace          core.ReplaceFutureExchangeRatesCommand
+}
+
+func genReplaceFutureExchangeRates() *rapid.Generator[ReplaceFutureExchangeRatesFixture] {
+	return rapid.Custom(func(t *rapid.T) ReplaceFutureExchangeRatesFixture {
+		base := genCreateCurrencyValid().Draw(t, "base")
+		// Draw from a small pool of dates so that the schedule overlaps with
+		// existing rates, exercising updates alongside adds and removes.
+		pool := rapid.SliceOfNDistinct(genExchangeRateFromAfter(base.Clock.Today()), 1, 4, core.Date.String).
+			Draw(t, "pool")
+		existing := rapid.SliceOfNDistinct(rapid.SampledFrom(pool), 0, len(pool), core.Date.String).
+			Draw(t, "existing")
+		scheduled := rapid.SliceOfNDistinct(rapid.SampledFrom(pool), 0, len(pool), core.Date.String).
+			Draw(t, "scheduled")
+
+		adds := make([]core.AddExchangeRateCommand, len(existing))
+		for i, from := range existing {
+			adds[i] = genAddExchangeRateCommand().Draw(t, "add_exchange_rate")
+			adds[i].Code = base.CreateCurrency.Code
+			adds[i].From = from
+		}
+
+		replace := core.ReplaceFutureExchangeRatesCommand{
+			Code:          base.CreateCurrency.Code,
+			ExchangeRates: make([]core.ScheduledExchangeRate, len(scheduled)),
+		}
+		for i, from := range scheduled {
+			replace.ExchangeRates[i] = core.ScheduledExchangeRate{
+				ID:   testutil.GenUUID().Draw(t, "id"),
+				Rate: genExchangeRateRate().Draw(t, "rate"),
+				From: from,
+			}
+		}
+
+		return ReplaceFutureExchangeRatesFixture{
+			Base:             base,
+			AddExchangeRates: adds,
+			Replace:          replace,
+		}
+	})
+}
+
+type ImportExchangeRatesFixture struct {
+	Base   CreateCurrencyValidFixture
+	Import core.ImportExchangeRatesCommand
+	// Valid is the one row expected to be accepted. The remaining rows are
+	// expected to be rejected.
+	Valid core.ExchangeRateImportRow
+}
+
+func genImportExchangeRates() *rapid.Generator[ImportExchangeRatesFixture] {
+	return rapid.Custom(func(t *rapid.T) ImportExchangeRatesFixture {
+		base := genCreateCurrencyValid().Draw(t, "base")
+		code := base.CreateCurrency.Code
+		rate := genExchangeRateRate().Draw(t, "rate").String()
+
+		valid := core.ExchangeRateImportRow{
+			Line: 1,
+			ID:   testutil.GenUUID().Draw(t, "id"),
+			Code: code,
+			Rate: rate,
+			From: genExchangeRateFrom().Draw(t, "from").String(),
+		}
+		unknown := valid
+		unknown.Line, unknown.ID = 2, testutil.GenUUID().Draw(t, "unknown_id")
+		unknown.Code = genCurrencyCode().Filter(func(c string) bool { return c != code }).Draw(t, "unknown_code")
+		malformed := valid
+		malformed.Line, malformed.ID, malformed.Rate = 3, testutil.GenUUID().Draw(t, "malformed_id"), "abc"
+
+		rows := []core.ExchangeRateImportRow{valid, unknown, malformed}
+		return ImportExchangeRatesFixture{
+			Base: base,
+			Import: core.ImportExchangeRatesCommand{
+				Rows:   rapid.Permutation(rows).Draw(t, "rows"),
+				DryRun: rapid.Bool().Draw(t, "dry_run"),
+			},
+			Valid: valid,
+		}
+	})
+}
+
 func genUpdateExchangeRateCommand() *rapid.Generator[core.UpdateExchangeRateCommand] {
 	return rapid.Custom(func(t *rapid.T) core.UpdateExchangeRateCommand {
 		return core.UpdateExchangeRateCommand{
@@ -0,0 +1,217 @@
+package discountQuote_test
+
+import (
+	"context"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type DiscountQuoteTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (dt *DiscountQuoteTests) SetupSuite() {
+	dt.ctx = context.Background()
+	dt.config = testutil.LoadConfig()
+	dt.clock = &testutil.SwitchableClock{}
+	dt.dispatcher = infrastructure.NewDispatcher(dt.ctx, *testutil.Config, infrastructure.WithClock(dt.clock))
+}
+
+func (dt *DiscountQuoteTests) TearDownSuite() {
+	dt.dispatcher.Close()
+}
+
+func (dt *DiscountQuoteTests) cleanUp() {
+	testutil.ResetDB(dt.ctx, dt.dispatcher.PgxPool)
+}
+
+// setup leaves the clock at the tiering clock with the cluster tiered.
+// Without a tier discount, none is in effect on any date.
+func (dt *DiscountQuoteTests) setup(t *rapid.T, fx DiscountQuoteFixture, addTierDiscount bool) {
+	dt.clock.Current = fx.Clock
+	_, err := dt.dispatcher.CreateCurrency(dt.ctx, core.CreateCurrencyCommand{ID: uuid.New(), Code: fx.CurrencyCode})
+	require.NoError(t, err)
+	_, err = dt.dispatcher.CreateRevenueGroup(dt.ctx, fx.CreateRevenueGroup)
+	require.NoError(t, err)
+	_, err = dt.dispatcher.AddRevenueGroupLimit(dt.ctx, fx.AddRevenueGroupLimit)
+	require.NoError(t, err)
+
+	_, err = dt.dispatcher.CreateProductGroup(dt.ctx, fx.CreateProductGroup)
+	require.NoError(t, err)
+	_, err = dt.dispatcher.AddProductGroupWeight(dt.ctx, fx.AddProductGroupWeight)
+	require.NoError(t, err)
+	_, err = dt.dispatcher.CreateProduct(dt.ctx, fx.CreateProduct)
+	require.NoError(t, err)
+	assign := core.AssignProductGroupCommand{Code: fx.CreateProduct.Code, ProductGroupCode: fx.CreateProductGroup.Code}
+	_, err = dt.dispatcher.AssignProductGroup(dt.ctx, assign)
+	require.NoError(t, err)
+	if addTierDiscount {
+		_, err = dt.dispatcher.CreateTierDiscount(dt.ctx, fx.CreateTierDiscount)
+		require.NoError(t, err)
+	}
+
+	for _, enroll := range fx.EnrollResellers {
+		_, err := dt.dispatcher.EnrollReseller(dt.ctx, enroll)
+		require.NoError(t, err)
+	}
+	_, err = dt.dispatcher.CreateCluster(dt.ctx, fx.CreateCluster)
+	require.NoError(t, err)
+	for _, enroll := range fx.EnrollResellers[1:] {
+		add := core.AddClusterMemberCommand{ClusterID: fx.CreateCluster.ID, ResellerID: enroll.ID}
+		_, err := dt.dispatcher.AddClusterMember(dt.ctx, add)
+		require.NoError(t, err)
+	}
+
+	dt.clock.Current = fx.TieringClock
+	for _, record := range fx.RecordBillings {
+		_, err := dt.dispatcher.RecordBilling(dt.ctx, record)
+		require.NoError(t, err)
+	}
+	_, err = dt.dispatcher.TierClusters(dt.ctx, core.TierClustersCommand{ID: uuid.New()})
+	require.NoError(t, err)
+}
+
+func expectedTier(limits core.RevenueLimitsInput, netRevenue core.Decimal) *core.ResellerTier {
+	var tier core.ResellerTier
+	switch {
+	case netRevenue.Cmp(limits.Premier) >= 0:
+		tier = core.ResellerTierPremier
+	case netRevenue.Cmp(limits.Advanced) >= 0:
+		tier = core.ResellerTierAdvanced
+	case netRevenue.Cmp(limits.Authorized) >= 0:
+		tier = core.ResellerTierAuthorized
+	default:
+		return nil
+	}
+	return &tier
+}
+
+func expectedPercentage(percentages core.DiscountPercentagesInput, tier *core.ResellerTier) core.Decimal {
+	if tier == nil {
+		return core.NewDecimalFromInt(0)
+	}
+	switch *tier {
+	case core.ResellerTierPremier:
+		return percentages.Premier
+	case core.ResellerTierAdvanced:
+		return percentages.Advanced
+	default:
+		return percentages.Authorized
+	}
+}
+
+func (dt *DiscountQuoteTests) TestGetDiscountQuoteValid() {
+	rapid.Check(dt.T(), func(t *rapid.T) {
+		dt.cleanUp()
+		fx := genDiscountQuote().Draw(t, "fx")
+		dt.setup(t, fx, true)
+
+		netRevenue := core.NewDecimalFromInt(0)
+		for _, record := range fx.RecordBillings {
+			netRevenue = netRevenue.Add(record.Items[0].GrossRevenue)
+		}
+		tier := expectedTier(fx.AddRevenueGroupLimit.Limits, netRevenue)
+		tierAt := fx.TieringClock.Today()
+		member := fx.EnrollResellers[len(fx.EnrollResellers)-1]
+
+		q, err := dt.dispatcher.GetDiscountQuote(dt.ctx, core.GetDiscountQuoteQuery{
+			ResellerExternalID: member.ExternalID,
+			ProductCode:        fx.CreateProduct.Code,
+			On:                 tierAt,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tier, q.ResellerTier)
+		assert.Zero(t, expectedPercentage(fx.CreateTierDiscount.Percentages, tier).Cmp(q.Percentage))
+		assert.Equal(t, member.ID, q.Breakdown.ResellerID)
+		require.NotNil(t, q.Breakdown.ClusterID)
+		assert.Equal(t, fx.CreateCluster.ID, *q.Breakdown.ClusterID)
+		assert.Equal(t, fx.CreateProductGroup.Code, q.Breakdown.ProductGroupCode)
+		require.NotNil(t, q.Breakdown.TierAt)
+		assert.Equal(t, tierAt, *q.Breakdown.TierAt)
+		require.NotNil(t, q.Breakdown.ResellerTierBasis)
+		assert.Equal(t, core.TierBasisYearToDate, *q.Breakdown.ResellerTierBasis)
+		assert.Equal(t, fx.CreateTierDiscount.ID, q.Breakdown.TierDiscountID)
+		assert.Equal(t, fx.CreateTierDiscount.From, q.Breakdown.TierDiscountFrom)
+
+		// Before the cluster was first tiered, the reseller had no tier.
+		before := tierAt.AddDate(0, 0, -1)
+		if before.Before(fx.CreateTierDiscount.From) {
+			return
+		}
+		q, err = dt.dispatcher.GetDiscountQuote(dt.ctx, core.GetDiscountQuoteQuery{
+			ResellerExternalID: member.ExternalID,
+			ProductCode:        fx.CreateProduct.Code,
+			On:                 before,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, q.ResellerTier)
+		assert.Zero(t, core.NewDecimalFromInt(0).Cmp(q.Percentage))
+		assert.Nil(t, q.Breakdown.TierAt)
+	})
+}
+
+func (dt *DiscountQuoteTests) TestGetDiscountQuotesValid() {
+	rapid.Check(dt.T(), func(t *rapid.T) {
+		dt.cleanUp()
+		fx := genDiscountQuote().Draw(t, "fx")
+		dt.setup(t, fx, true)
+		unknownCode := testutil.GenCode(core.ProductCodeLengthMin, core.ProductCodeLengthMax).
+			Filter(func(c string) bool { return c != fx.CreateProduct.Code }).
+			Draw(t, "unknown_product_code")
+
+		tierAt := fx.TieringClock.Today()
+		var lines []core.DiscountQuoteLineInput
+		for _, enroll := range fx.EnrollResellers {
+			lines = append(lines, core.DiscountQuoteLineInput{ResellerExternalID: enroll.ExternalID, ProductCode: fx.CreateProduct.Code, On: tierAt})
+		}
+		lines = append(lines, core.DiscountQuoteLineInput{ResellerExternalID: fx.EnrollResellers[0].ExternalID, ProductCode: unknownCode, On: tierAt})
+
+		res, err := dt.dispatcher.GetDiscountQuotes(dt.ctx, core.GetDiscountQuotesQuery{Lines: lines})
+		require.NoError(t, err)
+
+		// Every member of the cluster gets the cluster's tier.
+		require.Len(t, res.Quotes, len(lines))
+		for i, enroll := range fx.EnrollResellers {
+			require.NotNil(t, res.Quotes[i])
+			assert.Equal(t, enroll.ID, res.Quotes[i].Breakdown.ResellerID)
+			assert.Equal(t, res.Quotes[0].ResellerTier, res.Quotes[i].ResellerTier)
+		}
+		assert.Nil(t, res.Quotes[len(lines)-1])
+		require.Len(t, res.Failed, 1)
+		assert.Equal(t, len(lines)-1, res.Failed[0].Line)
+	})
+}
+
+func (dt *DiscountQuoteTests) TestGetDiscountQuoteWithoutTierDiscountInvalid() {
+	rapid.Check(dt.T(), func(t *rapid.T) {
+		dt.cleanUp()
+		fx := genDiscountQuote().Draw(t, "fx")
+		dt.setup(t, fx, false)
+
+		_, err := dt.dispatcher.GetDiscountQuote(dt.ctx, core.GetDiscountQuoteQuery{
+			ResellerExternalID: fx.EnrollResellers[0].ExternalID,
+			ProductCode:        fx.CreateProduct.Code,
+			On:                 fx.TieringClock.Today(),
+		})
+
+		var e *core.NoTierDiscountError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, fx.TieringClock.Today(), e.On)
+	})
+}
+
+func TestDiscountQuote(t *testing.T) {
+	suite.Run(t, new(DiscountQuoteTests))
+}
@@ -0,0 +1,176 @@
+package discountQuote_test
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genCountryCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CountryCodes, strings.Compare)
+}
+
+func genCurrencyCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CurrencyCodes, strings.Compare)
+}
+
+// genRevenueLimits draws limits in the range of the cluster's net revenue so
+// that every tier, and no tier, is reached. Limits have at most the minor
+// units of the revenue group's currency.
+func genRevenueLimits(currencyCode string) *rapid.Generator[core.RevenueLimitsInput] {
+	places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+	return rapid.Custom(func(t *rapid.T) core.RevenueLimitsInput {
+		limit := testutil.GenDecimalBetween(core.NewDecimalFromInt(0), core.NewDecimalFromInt(300_000), places)
+		limits := rapid.SliceOfNDistinct(limit, 3, 3, core.Decimal.String).Draw(t, "limits")
+		slices.SortFunc(limits, core.Decimal.Cmp)
+		return core.RevenueLimitsInput{
+			Authorized: limits[0],
+			Advanced:   limits[1],
+			Premier:    limits[2],
+		}
+	})
+}
+
+func genDiscountPercentages() *rapid.Generator[core.DiscountPercentagesInput] {
+	return rapid.Custom(func(t *rapid.T) core.DiscountPercentagesInput {
+		p := rapid.SliceOfNDistinct(
+			testutil.GenDecimalBetween(
+				core.TierDiscountPercentageMin,
+				core.TierDiscountPercentageMax,
+				core.TierDiscountPercentageDecimalPlacesMax),
+			3, 3,
+			func(d core.Decimal) any { return d },
+		).Draw(t, "percentages")
+		slices.SortFunc(p, core.Decimal.Cmp)
+		return core.DiscountPercentagesInput{
+			Authorized: p[0],
+			Advanced:   p[1],
+			Premier:    p[2],
+		}
+	})
+}
+
+func beforeYearEnd(c core.Clock) bool {
+	today := c.Today()
+	return today.Month() != 12 || today.Day() != 31
+}
+
+type DiscountQuoteFixture struct {
+	Clock                 core.Clock
+	CurrencyCode          string
+	CreateRevenueGroup    core.CreateRevenueGroupCommand
+	AddRevenueGroupLimit  core.AddRevenueGroupLimitCommand
+	CreateProductGroup    core.CreateProductGroupCommand
+	AddProductGroupWeight core.AddProductGroupWeightCommand
+	CreateProduct         core.CreateProductCommand
+	CreateTierDiscount    core.CreateTierDiscountCommand
+	EnrollResellers       []core.EnrollResellerCommand
+	CreateCluster         core.CreateClusterCommand
+	TieringClock          core.Clock
+	RecordBillings        []core.RecordBillingCommand
+}
+
+// genDiscountQuote sets up a cluster of resellers billed in the revenue
+// group's currency like tiering does, and a tier discount in effect from the
+// day limits and weights are.
+func genDiscountQuote() *rapid.Generator[DiscountQuoteFixture] {
+	return rapid.Custom(func(t *rapid.T) DiscountQuoteFixture {
+		clock := testutil.GenFakeClock().Filter(beforeYearEnd).Draw(t, "clock")
+		from := clock.Today().AddDate(0, 0, 1)
+		countryCode := genCountryCode().Draw(t, "country_code")
+		currencyCode := genCurrencyCode().Draw(t, "currency_code")
+
+		createRevenueGroup := core.CreateRevenueGroupCommand{
+			ID:           testutil.GenUUID().Draw(t, "revenue_group_id"),
+			CountryCode:  countryCode,
+			CurrencyCode: currencyCode,
+		}
+		addLimit := core.AddRevenueGroupLimitCommand{
+			ID:          testutil.GenUUID().Draw(t, "revenue_group_limit_id"),
+			CountryCode: countryCode,
+			Limits:      genRevenueLimits(currencyCode).Draw(t, "limits"),
+			From:        from,
+		}
+
+		createProductGroup := core.CreateProductGroupCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_group_id"),
+			Code: testutil.GenCode(core.ProductGroupCodeLengthMin, core.ProductGroupCodeLengthMax).Draw(t, "product_group_code"),
+		}
+		addWeight := core.AddProductGroupWeightCommand{
+			ID:         testutil.GenUUID().Draw(t, "product_group_weight_id"),
+			Code:       createProductGroup.Code,
+			Percentage: core.NewDecimalFromInt(100),
+			From:       from,
+		}
+		createProduct := core.CreateProductCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_id"),
+			Code: testutil.GenCode(core.ProductCodeLengthMin, core.ProductCodeLengthMax).Draw(t, "product_code"),
+		}
+		createTierDiscount := core.CreateTierDiscountCommand{
+			ID:          testutil.GenUUID().Draw(t, "tier_discount_id"),
+			Percentages: genDiscountPercentages().Draw(t, "percentages"),
+			From:        from,
+		}
+
+		ids := rapid.SliceOfNDistinct(testutil.GenUUID(), 1, 3, rapid.ID).Draw(t, "reseller_ids")
+		enrolls := make([]core.EnrollResellerCommand, len(ids))
+		for i, id := range ids {
+			enrolls[i] = core.EnrollResellerCommand{
+				ID:           id,
+				ExternalID:   testutil.GenUUID().Draw(t, "external_id"),
+				CountryCode:  countryCode,
+				CurrencyCode: currencyCode,
+				EnrolledAt:   testutil.GenDateBetween(core.ResellerEnrolledAtMin, core.ResellerEnrolledAtMax).Draw(t, "enrolled_at"),
+			}
+		}
+		createCluster := core.CreateClusterCommand{
+			ID:             testutil.GenUUID().Draw(t, "cluster_id"),
+			ExternalID:     testutil.GenUUID().Draw(t, "cluster_external_id"),
+			RevenueGroupID: createRevenueGroup.ID,
+			HeadResellerID: enrolls[0].ID,
+		}
+
+		yearEnd := core.NewDate(from.Year(), 12, 31)
+		tierAt := from.AddDate(0, 0, rapid.IntRange(0, from.DaysBetween(yearEnd)).Draw(t, "tier_after"))
+		tieringClock := &testutil.FakeClock{Now: tierAt.Time.Add(12 * time.Hour)}
+
+		places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+		documentNumbers := rapid.SliceOfNDistinct(testutil.GenCode(core.DocumentNumberLengthMin, core.DocumentNumberLengthMax), len(enrolls), len(enrolls), rapid.ID).
+			Draw(t, "document_numbers")
+		records := make([]core.RecordBillingCommand, len(enrolls))
+		for i, enroll := range enrolls {
+			records[i] = core.RecordBillingCommand{
+				ID:                 testutil.GenUUID().Draw(t, "billing_id"),
+				ResellerExternalID: enroll.ExternalID,
+				DocumentNumber:     documentNumbers[i],
+				BookedAt:           testutil.GenDateBetween(from, tierAt).Draw(t, "booked_at"),
+				Kind:               string(core.ResellerBillingKindInvoice),
+				CurrencyCode:       currencyCode,
+				Items: []core.RecordBillingItemInput{{
+					ID:           testutil.GenUUID().Draw(t, "item_id"),
+					ProductCode:  createProduct.Code,
+					GrossRevenue: testutil.GenDecimalBetween(core.ResellerBillingGrossRevenueMin, core.NewDecimalFromInt(150_000), places).Draw(t, "gross_revenue"),
+				}},
+			}
+		}
+
+		return DiscountQuoteFixture{
+			Clock:                 clock,
+			CurrencyCode:          currencyCode,
+			CreateRevenueGroup:    createRevenueGroup,
+			AddRevenueGroupLimit:  addLimit,
+			CreateProductGroup:    createProductGroup,
+			AddProductGroupWeight: addWeight,
+			CreateProduct:         createProduct,
+			CreateTierDiscount:    createTierDiscount,
+			EnrollResellers:       enrolls,
+			CreateCluster:         createCluster,
+			TieringClock:          tieringClock,
+			RecordBillings:        records,
+		}
+	})
+}
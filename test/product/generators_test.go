@@ -0,0 +1,119 @@
+package product_test
+
+import (
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genProductCode() *rapid.Generator[string] {
+	return testutil.GenCode(core.ProductCodeLengthMin, core.ProductCodeLengthMax)
+}
+
+func genProductGroupCode() *rapid.Generator[string] {
+	return testutil.GenCode(core.ProductGroupCodeLengthMin, core.ProductGroupCodeLengthMax)
+}
+
+func genCreateProductCommand() *rapid.Generator[core.CreateProductCommand] {
+	return rapid.Custom(func(t *rapid.T) core.CreateProductCommand {
+		return core.CreateProductCommand{
+			ID:   testutil.GenUUID().Draw(t, "id"),
+			Code: genProductCode().Draw(t, "code"),
+		}
+	})
+}
+
+type CreateProductValidFixture struct {
+	Clock         core.Clock
+	CreateProduct core.CreateProductCommand
+	GetProduct    core.GetProductQuery
+}
+
+func genCreateProductValid() *rapid.Generator[CreateProductValidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateProductValidFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		create := genCreateProductCommand().Draw(t, "create_product")
+		get := core.GetProductQuery{
+			Code: create.Code,
+		}
+		return CreateProductValidFixture{
+			Clock:         clock,
+			CreateProduct: create,
+			GetProduct:    get,
+		}
+	})
+}
+
+type CreateProductDuplicateInvalidFixture struct {
+	Base          CreateProductValidFixture
+	CreateProduct core.CreateProductCommand
+}
+
+func genCreateProductDuplicateIDInvalid() *rapid.Generator[CreateProductDuplicateInvalidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateProductDuplicateInvalidFixture {
+		base := genCreateProductValid().Draw(t, "base")
+		create := genCreateProductCommand().Draw(t, "create_product")
+		create.ID = base.CreateProduct.ID
+		return CreateProductDuplicateInvalidFixture{
+			Base:          base,
+			CreateProduct: create,
+		}
+	})
+}
+
+func genCreateProductDuplicateCodeInvalid() *rapid.Generator[CreateProductDuplicateInvalidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateProductDuplicateInvalidFixture {
+		base := genCreateProductValid().Draw(t, "base")
+		create := genCreateProductCommand().Draw(t, "create_product")
+		create.Code = base.CreateProduct.Code
+		return CreateProductDuplicateInvalidFixture{
+			Base:          base,
+			CreateProduct: create,
+		}
+	})
+}
+
+type AssignProductGroupFixture struct {
+	Base               CreateProductValidFixture
+	ProductGroupCodes  []string
+	AssignProductGroup core.AssignProductGroupCommand
+}
+
+func genAssignProductGroup() *rapid.Generator[AssignProductGroupFixture] {
+	return rapid.Custom(func(t *rapid.T) AssignProductGroupFixture {
+		base := genCreateProductValid().Draw(t, "base")
+		codes := rapid.SliceOfNDistinct(genProductGroupCode(), 1, 3, rapid.ID).Draw(t, "product_group_codes")
+		assign := core.AssignProductGroupCommand{
+			Code:             base.CreateProduct.Code,
+			ProductGroupCode: rapid.SampledFrom(codes).Draw(t, "product_group_code"),
+		}
+		return AssignProductGroupFixture{
+			Base:               base,
+			ProductGroupCodes:  codes,
+			AssignProductGroup: assign,
+		}
+	})
+}
+
+type ListProductsFixture struct {
+	Clock          core.Clock
+	CreateProducts []core.CreateProductCommand
+	Limit          int
+}
+
+func genListProducts() *rapid.Generator[ListProductsFixture] {
+	return rapid.Custom(func(t *rapid.T) ListProductsFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		codes := rapid.SliceOfNDistinct(genProductCode(), 1, 5, rapid.ID).Draw(t, "codes")
+		creates := make([]core.CreateProductCommand, len(codes))
+		for i, code := range codes {
+			creates[i] = genCreateProductCommand().Draw(t, "create_product")
+			creates[i].Code = code
+		}
+		return ListProductsFixture{
+			Clock:          clock,
+			CreateProducts: creates,
+			Limit:          rapid.IntRange(core.PageLimitMin, len(codes)).Draw(t, "limit"),
+		}
+	})
+}
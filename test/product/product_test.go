@@ -0,0 +1,235 @@
+package product_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type ProductTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (pt *ProductTests) SetupSuite() {
+	pt.ctx = context.Background()
+	pt.config = testutil.LoadConfig()
+	pt.clock = &testutil.SwitchableClock{}
+	pt.dispatcher = infrastructure.NewDispatcher(pt.ctx, *testutil.Config, infrastructure.WithClock(pt.clock))
+}
+
+func (pt *ProductTests) TearDownSuite() {
+	pt.dispatcher.Close()
+}
+
+func (pt *ProductTests) cleanUp() {
+	testutil.ResetDB(pt.ctx, pt.dispatcher.PgxPool)
+}
+
+func (pt *ProductTests) setup(t *rapid.T, fx CreateProductValidFixture) {
+	pt.clock.Current = fx.Clock
+	_, err := pt.dispatcher.CreateProduct(pt.ctx, fx.CreateProduct)
+	require.NoError(t, err)
+}
+
+func (pt *ProductTests) setupProductGroups(t *rapid.T, codes []string) {
+	for _, code := range codes {
+		create := core.CreateProductGroupCommand{ID: uuid.New(), Code: code}
+		_, err := pt.dispatcher.CreateProductGroup(pt.ctx, create)
+		require.NoError(t, err)
+	}
+}
+
+func (pt *ProductTests) TestCreateProductValid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductValid().Draw(t, "fx")
+
+		pt.setup(t, fx)
+
+		p, err := pt.dispatcher.GetProduct(pt.ctx, fx.GetProduct)
+		require.NoError(t, err)
+		assert.Equal(t, fx.CreateProduct.ID, p.ID)
+		assert.Equal(t, fx.CreateProduct.Code, p.Code)
+		assert.Nil(t, p.ProductGroupCode)
+	})
+}
+
+func (pt *ProductTests) TestCreateProductDuplicateIDInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductDuplicateIDInvalid().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+
+		_, err := pt.dispatcher.CreateProduct(pt.ctx, fx.CreateProduct)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Product", e.Entity)
+		assert.Equal(t, fx.CreateProduct.ID.String(), e.FieldValues["ID"])
+	})
+}
+
+func (pt *ProductTests) TestCreateProductDuplicateCodeInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductDuplicateCodeInvalid().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+
+		_, err := pt.dispatcher.CreateProduct(pt.ctx, fx.CreateProduct)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Product", e.Entity)
+		assert.Equal(t, fx.CreateProduct.Code, e.FieldValues["Code"])
+	})
+}
+
+func (pt *ProductTests) TestListProductsPagination() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genListProducts().Draw(t, "fx")
+		pt.clock.Current = fx.Clock
+		var expected []string
+		for _, create := range fx.CreateProducts {
+			_, err := pt.dispatcher.CreateProduct(pt.ctx, create)
+			require.NoError(t, err)
+			expected = append(expected, create.Code)
+		}
+		slices.Sort(expected)
+
+		var actual []string
+		qry := core.ListProductsQuery{Limit: fx.Limit}
+		for {
+			res, err := pt.dispatcher.ListProducts(pt.ctx, qry)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(res.Items), fx.Limit)
+			for _, p := range res.Items {
+				actual = append(actual, p.Code)
+			}
+			if res.NextCursor == "" {
+				break
+			}
+			qry.Cursor = res.NextCursor
+		}
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func (pt *ProductTests) TestAssignProductGroupValid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genAssignProductGroup().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+		pt.setupProductGroups(t, fx.ProductGroupCodes)
+
+		_, err := pt.dispatcher.AssignProductGroup(pt.ctx, fx.AssignProductGroup)
+		require.NoError(t, err)
+
+		p, err := pt.dispatcher.GetProduct(pt.ctx, fx.Base.GetProduct)
+		require.NoError(t, err)
+		require.NotNil(t, p.ProductGroupCode)
+		assert.Equal(t, fx.AssignProductGroup.ProductGroupCode, *p.ProductGroupCode)
+
+		code := fx.AssignProductGroup.ProductGroupCode
+		res, err := pt.dispatcher.ListProducts(pt.ctx, core.ListProductsQuery{ProductGroupCode: &code})
+		require.NoError(t, err)
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, fx.Base.CreateProduct.Code, res.Items[0].Code)
+	})
+}
+
+func (pt *ProductTests) TestAssignProductGroupSameInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genAssignProductGroup().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+		pt.setupProductGroups(t, fx.ProductGroupCodes)
+		_, err := pt.dispatcher.AssignProductGroup(pt.ctx, fx.AssignProductGroup)
+		require.NoError(t, err)
+
+		_, err = pt.dispatcher.AssignProductGroup(pt.ctx, fx.AssignProductGroup)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ProductExpectedDifferentProductGroup, e.Code)
+	})
+}
+
+func (pt *ProductTests) TestAssignProductGroupUnknownInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genAssignProductGroup().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+
+		_, err := pt.dispatcher.AssignProductGroup(pt.ctx, fx.AssignProductGroup)
+
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "ProductGroup", e.Entity)
+	})
+}
+
+func (pt *ProductTests) TestUnassignProductGroupValid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genAssignProductGroup().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+		pt.setupProductGroups(t, fx.ProductGroupCodes)
+		_, err := pt.dispatcher.AssignProductGroup(pt.ctx, fx.AssignProductGroup)
+		require.NoError(t, err)
+
+		_, err = pt.dispatcher.UnassignProductGroup(pt.ctx, core.UnassignProductGroupCommand{Code: fx.Base.CreateProduct.Code})
+		require.NoError(t, err)
+
+		p, err := pt.dispatcher.GetProduct(pt.ctx, fx.Base.GetProduct)
+		require.NoError(t, err)
+		assert.Nil(t, p.ProductGroupCode)
+	})
+}
+
+func (pt *ProductTests) TestUnassignProductGroupUnsetInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductValid().Draw(t, "fx")
+		pt.setup(t, fx)
+
+		_, err := pt.dispatcher.UnassignProductGroup(pt.ctx, core.UnassignProductGroupCommand{Code: fx.CreateProduct.Code})
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ProductExpectedProductGroupSet, e.Code)
+	})
+}
+
+func (pt *ProductTests) TestRemoveProductValid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductValid().Draw(t, "fx")
+		pt.setup(t, fx)
+
+		_, err := pt.dispatcher.RemoveProduct(pt.ctx, core.RemoveProductCommand{Code: fx.CreateProduct.Code})
+		require.NoError(t, err)
+
+		_, err = pt.dispatcher.GetProduct(pt.ctx, fx.GetProduct)
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+	})
+}
+
+func TestProduct(t *testing.T) {
+	suite.Run(t, new(ProductTests))
+}
@@ -0,0 +1,203 @@
+package productGroup_test
+
+import (
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genProductGroupCode() *rapid.Generator[string] {
+	return testutil.GenCode(core.ProductGroupCodeLengthMin, core.ProductGroupCodeLengthMax)
+}
+
+func genCreateProductGroupCommand() *rapid.Generator[core.CreateProductGroupCommand] {
+	return rapid.Custom(func(t *rapid.T) core.CreateProductGroupCommand {
+		return core.CreateProductGroupCommand{
+			ID:   testutil.GenUUID().Draw(t, "id"),
+			Code: genProductGroupCode().Draw(t, "code"),
+		}
+	})
+}
+
+type CreateProductGroupValidFixture struct {
+	Clock              core.Clock
+	CreateProductGroup core.CreateProductGroupCommand
+	GetProductGroup    core.GetProductGroupQuery
+}
+
+func genCreateProductGroupValid() *rapid.Generator[CreateProductGroupValidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateProductGroupValidFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		create := genCreateProductGroupCommand().Draw(t, "create_product_group")
+		get := core.GetProductGroupQuery{
+			Code: create.Code,
+		}
+		return CreateProductGroupValidFixture{
+			Clock:              clock,
+			CreateProductGroup: create,
+			GetProductGroup:    get,
+		}
+	})
+}
+
+type CreateProductGroupDuplicateInvalidFixture struct {
+	Base               CreateProductGroupValidFixture
+	CreateProductGroup core.CreateProductGroupCommand
+}
+
+func genCreateProductGroupDuplicateCodeInvalid() *rapid.Generator[CreateProductGroupDuplicateInvalidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateProductGroupDuplicateInvalidFixture {
+		base := genCreateProductGroupValid().Draw(t, "base")
+		create := genCreateProductGroupCommand().Draw(t, "create_product_group")
+		create.Code = base.CreateProductGroup.Code
+		return CreateProductGroupDuplicateInvalidFixture{
+			Base:               base,
+			CreateProductGroup: create,
+		}
+	})
+}
+
+type ListProductGroupsFixture struct {
+	Clock               core.Clock
+	CreateProductGroups []core.CreateProductGroupCommand
+	Limit               int
+}
+
+func genListProductGroups() *rapid.Generator[ListProductGroupsFixture] {
+	return rapid.Custom(func(t *rapid.T) ListProductGroupsFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		codes := rapid.SliceOfNDistinct(genProductGroupCode(), 1, 5, rapid.ID).Draw(t, "codes")
+		creates := make([]core.CreateProductGroupCommand, len(codes))
+		for i, code := range codes {
+			creates[i] = genCreateProductGroupCommand().Draw(t, "create_product_group")
+			creates[i].Code = code
+		}
+		return ListProductGroupsFixture{
+			Clock:               clock,
+			CreateProductGroups: creates,
+			Limit:               rapid.IntRange(core.PageLimitMin, len(codes)).Draw(t, "limit"),
+		}
+	})
+}
+
+func genWeight() *rapid.Generator[core.Decimal] {
+	return testutil.GenDecimalBetween(core.ProductGroupWeightMin, core.ProductGroupWeightMax, core.ProductGroupWeightDecimalPlacesMax)
+}
+
+func genProductGroupWeightFrom() *rapid.Generator[core.Date] {
+	return rapid.Custom(func(t *rapid.T) core.Date {
+		return testutil.GenDateBetween(core.ProductGroupWeightFromMin, core.ProductGroupWeightFromMax).Draw(t, "from")
+	})
+}
+
+func genProductGroupWeightFromAfter(after core.Date) *rapid.Generator[core.Date] {
+	if after.After(core.ProductGroupWeightFromMax) {
+		panic("after must be after from max")
+	}
+	d := after.AddDate(0, 0, 1)
+	return rapid.Custom(func(t *rapid.T) core.Date {
+		return testutil.GenDateBetween(d, core.ProductGroupWeightFromMax).Draw(t, "from")
+	})
+}
+
+func genAddProductGroupWeightCommand() *rapid.Generator[core.AddProductGroupWeightCommand] {
+	return rapid.Custom(func(t *rapid.T) core.AddProductGroupWeightCommand {
+		return core.AddProductGroupWeightCommand{
+			ID:         testutil.GenUUID().Draw(t, "id"),
+			Code:       genProductGroupCode().Draw(t, "code"),
+			Percentage: genWeight().Draw(t, "percentage"),
+			From:       genProductGroupWeightFrom().Draw(t, "from"),
+		}
+	})
+}
+
+type AddProductGroupWeightFromDateBoundaryFixture struct {
+	Base                  CreateProductGroupValidFixture
+	AddProductGroupWeight core.AddProductGroupWeightCommand
+	ShouldPass            bool
+}
+
+func genAddProductGroupWeightFromDateBoundary() *rapid.Generator[AddProductGroupWeightFromDateBoundaryFixture] {
+	return rapid.Custom(func(t *rapid.T) AddProductGroupWeightFromDateBoundaryFixture {
+		base := genCreateProductGroupValid().Draw(t, "base")
+		today := base.Clock.Today()
+
+		min := today.DaysBetween(core.ProductGroupWeightFromMin)
+		max := today.DaysBetween(core.ProductGroupWeightFromMax)
+		offset := rapid.IntRange(-min, max).Draw(t, "offset")
+
+		add := genAddProductGroupWeightCommand().Draw(t, "add_product_group_weight")
+		add.Code = base.CreateProductGroup.Code
+		add.From = today.AddDate(0, 0, offset)
+
+		return AddProductGroupWeightFromDateBoundaryFixture{
+			Base:                  base,
+			AddProductGroupWeight: add,
+			ShouldPass:            offset > 0,
+		}
+	})
+}
+
+type UpdateProductGroupWeightFixture struct {
+	Base                     CreateProductGroupValidFixture
+	AddProductGroupWeight    core.AddProductGroupWeightCommand
+	UpdateProductGroupWeight core.UpdateProductGroupWeightCommand
+	ShouldPass               bool
+}
+
+func genUpdateProductGroupWeightFromDateBoundary() *rapid.Generator[UpdateProductGroupWeightFixture] {
+	return rapid.Custom(func(t *rapid.T) UpdateProductGroupWeightFixture {
+		base := genCreateProductGroupValid().Draw(t, "base")
+		today := base.Clock.Today()
+
+		add := genAddProductGroupWeightCommand().Draw(t, "add_product_group_weight")
+		add.Code = base.CreateProductGroup.Code
+		add.From = genProductGroupWeightFromAfter(today).Draw(t, "from")
+
+		min := today.DaysBetween(core.ProductGroupWeightFromMin)
+		max := today.DaysBetween(core.ProductGroupWeightFromMax)
+		offset := rapid.IntRange(-min, max).Draw(t, "offset")
+		update := core.UpdateProductGroupWeightCommand{
+			ID:   add.ID,
+			Code: add.Code,
+			Percentage: genWeight().
+				Filter(func(p core.Decimal) bool { return p != add.Percentage }).
+				Draw(t, "percentage"),
+			From: today.AddDate(0, 0, offset),
+		}
+
+		return UpdateProductGroupWeightFixture{
+			Base:                     base,
+			AddProductGroupWeight:    add,
+			UpdateProductGroupWeight: update,
+			ShouldPass:               offset > 0,
+		}
+	})
+}
+
+type GetEffectiveProductGroupWeightFixture struct {
+	Base                   CreateProductGroupValidFixture
+	AddProductGroupWeights []core.AddProductGroupWeightCommand
+	On                     core.Date
+}
+
+func genGetEffectiveProductGroupWeight() *rapid.Generator[GetEffectiveProductGroupWeightFixture] {
+	return rapid.Custom(func(t *rapid.T) GetEffectiveProductGroupWeightFixture {
+		base := genCreateProductGroupValid().Draw(t, "base")
+		froms := rapid.SliceOfNDistinct(genProductGroupWeightFromAfter(base.Clock.Today()), 0, 3, core.Date.String).
+			Draw(t, "froms")
+
+		adds := make([]core.AddProductGroupWeightCommand, len(froms))
+		for i, from := range froms {
+			adds[i] = genAddProductGroupWeightCommand().Draw(t, "add_product_group_weight")
+			adds[i].Code = base.CreateProductGroup.Code
+			adds[i].From = from
+		}
+
+		return GetEffectiveProductGroupWeightFixture{
+			Base:                   base,
+			AddProductGroupWeights: adds,
+			On:                     genProductGroupWeightFrom().Draw(t, "on"),
+		}
+	})
+}
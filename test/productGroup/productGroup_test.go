@@ -0,0 +1,227 @@
+package productGroup_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type ProductGroupTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (pt *ProductGroupTests) SetupSuite() {
+	pt.ctx = context.Background()
+	pt.config = testutil.LoadConfig()
+	pt.clock = &testutil.SwitchableClock{}
+	pt.dispatcher = infrastructure.NewDispatcher(pt.ctx, *testutil.Config, infrastructure.WithClock(pt.clock))
+}
+
+func (pt *ProductGroupTests) TearDownSuite() {
+	pt.dispatcher.Close()
+}
+
+func (pt *ProductGroupTests) cleanUp() {
+	testutil.ResetDB(pt.ctx, pt.dispatcher.PgxPool)
+}
+
+func (pt *ProductGroupTests) setup(t *rapid.T, fx CreateProductGroupValidFixture) {
+	pt.clock.Current = fx.Clock
+	_, err := pt.dispatcher.CreateProductGroup(pt.ctx, fx.CreateProductGroup)
+	require.NoError(t, err)
+}
+
+func (pt *ProductGroupTests) TestCreateProductGroupValid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductGroupValid().Draw(t, "fx")
+
+		pt.setup(t, fx)
+
+		pg, err := pt.dispatcher.GetProductGroup(pt.ctx, fx.GetProductGroup)
+		require.NoError(t, err)
+		assert.Equal(t, fx.CreateProductGroup.ID, pg.ID)
+		assert.Equal(t, fx.CreateProductGroup.Code, pg.Code)
+		assert.Empty(t, pg.Weights)
+	})
+}
+
+func (pt *ProductGroupTests) TestCreateProductGroupDuplicateCodeInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductGroupDuplicateCodeInvalid().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+
+		_, err := pt.dispatcher.CreateProductGroup(pt.ctx, fx.CreateProductGroup)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "ProductGroup", e.Entity)
+		assert.Equal(t, fx.CreateProductGroup.Code, e.FieldValues["Code"])
+	})
+}
+
+func (pt *ProductGroupTests) TestListProductGroupsPagination() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genListProductGroups().Draw(t, "fx")
+		pt.clock.Current = fx.Clock
+		var expected []string
+		for _, create := range fx.CreateProductGroups {
+			_, err := pt.dispatcher.CreateProductGroup(pt.ctx, create)
+			require.NoError(t, err)
+			expected = append(expected, create.Code)
+		}
+		slices.Sort(expected)
+
+		var actual []string
+		qry := core.ListProductGroupsQuery{Limit: fx.Limit}
+		for {
+			res, err := pt.dispatcher.ListProductGroups(pt.ctx, qry)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(res.Items), fx.Limit)
+			for _, pg := range res.Items {
+				actual = append(actual, pg.Code)
+			}
+			if res.NextCursor == "" {
+				break
+			}
+			qry.Cursor = res.NextCursor
+		}
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func (pt *ProductGroupTests) TestAddProductGroupWeightFromDateBoundary() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genAddProductGroupWeightFromDateBoundary().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+
+		_, err := pt.dispatcher.AddProductGroupWeight(pt.ctx, fx.AddProductGroupWeight)
+
+		if fx.ShouldPass {
+			require.NoError(t, err)
+			pg, err := pt.dispatcher.GetProductGroup(pt.ctx, fx.Base.GetProductGroup)
+			require.NoError(t, err)
+			require.Len(t, pg.Weights, 1)
+			w := pg.Weights[0]
+			assert.Equal(t, fx.AddProductGroupWeight.ID, w.ID)
+			assert.Equal(t, fx.AddProductGroupWeight.Percentage, w.Percentage)
+			assert.Equal(t, fx.AddProductGroupWeight.From, w.From)
+		} else {
+			var e *core.DomainError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, core.ProductGroupCodeExpectedFutureFromForAdd, e.Code)
+		}
+	})
+}
+
+func (pt *ProductGroupTests) TestUpdateProductGroupWeightFromDateBoundary() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genUpdateProductGroupWeightFromDateBoundary().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+		_, err := pt.dispatcher.AddProductGroupWeight(pt.ctx, fx.AddProductGroupWeight)
+		require.NoError(t, err)
+
+		_, err = pt.dispatcher.UpdateProductGroupWeight(pt.ctx, fx.UpdateProductGroupWeight)
+
+		if fx.ShouldPass {
+			require.NoError(t, err)
+			pg, err := pt.dispatcher.GetProductGroup(pt.ctx, fx.Base.GetProductGroup)
+			require.NoError(t, err)
+			require.Len(t, pg.Weights, 1)
+			assert.Equal(t, fx.UpdateProductGroupWeight.Percentage, pg.Weights[0].Percentage)
+			assert.Equal(t, fx.UpdateProductGroupWeight.From, pg.Weights[0].From)
+		} else {
+			var e *core.DomainError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, core.ProductGroupCodeExpectedFutureFromForUpdate, e.Code)
+		}
+	})
+}
+
+func (pt *ProductGroupTests) TestGetEffectiveProductGroupWeight() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genGetEffectiveProductGroupWeight().Draw(t, "fx")
+		pt.setup(t, fx.Base)
+		var expected *core.AddProductGroupWeightCommand
+		for _, add := range fx.AddProductGroupWeights {
+			_, err := pt.dispatcher.AddProductGroupWeight(pt.ctx, add)
+			require.NoError(t, err)
+			if !add.From.After(fx.On) && (expected == nil || add.From.After(expected.From)) {
+				expected = &add
+			}
+		}
+
+		qry := core.GetEffectiveProductGroupWeightQuery{Code: fx.Base.CreateProductGroup.Code, On: fx.On}
+		w, err := pt.dispatcher.GetEffectiveProductGroupWeight(pt.ctx, qry)
+
+		if expected == nil {
+			var noWeight *core.NoProductGroupWeightError
+			require.ErrorAs(t, err, &noWeight)
+			assert.Equal(t, qry.Code, noWeight.Code)
+			assert.Equal(t, qry.On, noWeight.On)
+			return
+		}
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID, w.ID)
+		assert.Equal(t, expected.Percentage, w.Percentage)
+		assert.True(t, expected.From.Equal(w.From))
+	})
+}
+
+func (pt *ProductGroupTests) TestRemoveProductGroupWithProductsInvalid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductGroupValid().Draw(t, "fx")
+		pt.setup(t, fx)
+		product := core.CreateProductCommand{ID: uuid.New(), Code: "P-1"}
+		_, err := pt.dispatcher.CreateProduct(pt.ctx, product)
+		require.NoError(t, err)
+		assign := core.AssignProductGroupCommand{Code: product.Code, ProductGroupCode: fx.CreateProductGroup.Code}
+		_, err = pt.dispatcher.AssignProductGroup(pt.ctx, assign)
+		require.NoError(t, err)
+
+		_, err = pt.dispatcher.RemoveProductGroup(pt.ctx, core.RemoveProductGroupCommand{Code: fx.CreateProductGroup.Code})
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ProductGroupCodeExpectedNoProductsForRemoval, e.Code)
+	})
+}
+
+func (pt *ProductGroupTests) TestRemoveProductGroupValid() {
+	rapid.Check(pt.T(), func(t *rapid.T) {
+		pt.cleanUp()
+		fx := genCreateProductGroupValid().Draw(t, "fx")
+		pt.setup(t, fx)
+
+		_, err := pt.dispatcher.RemoveProductGroup(pt.ctx, core.RemoveProductGroupCommand{Code: fx.CreateProductGroup.Code})
+		require.NoError(t, err)
+
+		_, err = pt.dispatcher.GetProductGroup(pt.ctx, fx.GetProductGroup)
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+	})
+}
+
+func TestProductGroup(t *testing.T) {
+	suite.Run(t, new(ProductGroupTests))
+}
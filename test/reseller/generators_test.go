@@ -0,0 +1,174 @@
+package reseller_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genCountryCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CountryCodes, strings.Compare)
+}
+
+func genCurrencyCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CurrencyCodes, strings.Compare)
+}
+
+func genEnrollResellerCommand() *rapid.Generator[core.EnrollResellerCommand] {
+	return rapid.Custom(func(t *rapid.T) core.EnrollResellerCommand {
+		return core.EnrollResellerCommand{
+			ID:           testutil.GenUUID().Draw(t, "id"),
+			ExternalID:   testutil.GenUUID().Draw(t, "external_id"),
+			CountryCode:  genCountryCode().Draw(t, "country_code"),
+			CurrencyCode: genCurrencyCode().Draw(t, "currency_code"),
+			EnrolledAt:   testutil.GenDateBetween(core.ResellerEnrolledAtMin, core.ResellerEnrolledAtMax).Draw(t, "enrolled_at"),
+		}
+	})
+}
+
+type EnrollResellerValidFixture struct {
+	Clock          core.Clock
+	EnrollReseller core.EnrollResellerCommand
+	GetReseller    core.GetResellerQuery
+}
+
+func genEnrollResellerValid() *rapid.Generator[EnrollResellerValidFixture] {
+	return rapid.Custom(func(t *rapid.T) EnrollResellerValidFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		enroll := genEnrollResellerCommand().Draw(t, "enroll_reseller")
+		get := core.GetResellerQuery{
+			ID: enroll.ID,
+		}
+		return EnrollResellerValidFixture{
+			Clock:          clock,
+			EnrollReseller: enroll,
+			GetReseller:    get,
+		}
+	})
+}
+
+type EnrollResellerDuplicateInvalidFixture struct {
+	Base           EnrollResellerValidFixture
+	EnrollReseller core.EnrollResellerCommand
+}
+
+func genEnrollResellerDuplicateIDInvalid() *rapid.Generator[EnrollResellerDuplicateInvalidFixture] {
+	return rapid.Custom(func(t *rapid.T) EnrollResellerDuplicateInvalidFixture {
+		base := genEnrollResellerValid().Draw(t, "base")
+		enroll := genEnrollResellerCommand().Draw(t, "enroll_reseller")
+		enroll.ID = base.EnrollReseller.ID
+		enroll.CurrencyCode = base.EnrollReseller.CurrencyCode
+		return EnrollResellerDuplicateInvalidFixture{
+			Base:           base,
+			EnrollReseller: enroll,
+		}
+	})
+}
+
+func genEnrollResellerDuplicateExternalIDInvalid() *rapid.Generator[EnrollResellerDuplicateInvalidFixture] {
+	return rapid.Custom(func(t *rapid.T) EnrollResellerDuplicateInvalidFixture {
+		base := genEnrollResellerValid().Draw(t, "base")
+		enroll := genEnrollResellerCommand().Draw(t, "enroll_reseller")
+		enroll.ExternalID = base.EnrollReseller.ExternalID
+		enroll.CurrencyCode = base.EnrollReseller.CurrencyCode
+		return EnrollResellerDuplicateInvalidFixture{
+			Base:           base,
+			EnrollReseller: enroll,
+		}
+	})
+}
+
+type ChangeResellerCurrencyFixture struct {
+	Base                   EnrollResellerValidFixture
+	ChangeResellerCurrency core.ChangeResellerCurrencyCommand
+}
+
+func genChangeResellerCurrency() *rapid.Generator[ChangeResellerCurrencyFixture] {
+	return rapid.Custom(func(t *rapid.T) ChangeResellerCurrencyFixture {
+		base := genEnrollResellerValid().Draw(t, "base")
+		code := genCurrencyCode().
+			Filter(func(c string) bool { return c != base.EnrollReseller.CurrencyCode }).
+			Draw(t, "currency_code")
+		change := core.ChangeResellerCurrencyCommand{
+			ID:           base.EnrollReseller.ID,
+			CurrencyCode: code,
+		}
+		return ChangeResellerCurrencyFixture{
+			Base:                   base,
+			ChangeResellerCurrency: change,
+		}
+	})
+}
+
+type RecordBillingFixture struct {
+	Base                  EnrollResellerValidFixture
+	CreateProductGroup    core.CreateProductGroupCommand
+	AddProductGroupWeight core.AddProductGroupWeightCommand
+	CreateProducts        []core.CreateProductCommand
+	BillingClock          core.Clock
+	RecordBilling         core.RecordBillingCommand
+}
+
+// genRecordBilling sets up a product group weighing 100 percent so that net
+// revenue equals gross revenue. As a weight must be from after today, the
+// billing is recorded on a later clock, within the year it's booked.
+func genRecordBilling() *rapid.Generator[RecordBillingFixture] {
+	return rapid.Custom(func(t *rapid.T) RecordBillingFixture {
+		base := genEnrollResellerValid().Draw(t, "base")
+		createProductGroup := core.CreateProductGroupCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_group_id"),
+			Code: testutil.GenCode(core.ProductGroupCodeLengthMin, core.ProductGroupCodeLengthMax).Draw(t, "product_group_code"),
+		}
+		bookedAt := base.Clock.Today().AddDate(0, 0, 1)
+		addWeight := core.AddProductGroupWeightCommand{
+			ID:         testutil.GenUUID().Draw(t, "product_group_weight_id"),
+			Code:       createProductGroup.Code,
+			Percentage: core.NewDecimalFromInt(100),
+			From:       bookedAt,
+		}
+
+		codes := rapid.SliceOfNDistinct(testutil.GenCode(core.ProductCodeLengthMin, core.ProductCodeLengthMax), 1, 3, rapid.ID).
+			Draw(t, "product_codes")
+		createProducts := make([]core.CreateProductCommand, len(codes))
+		for i, code := range codes {
+			createProducts[i] = core.CreateProductCommand{ID: testutil.GenUUID().Draw(t, "product_id"), Code: code}
+		}
+
+		yearEnd := core.NewDate(bookedAt.Year(), 12, 31)
+		recordedAt := bookedAt.AddDate(0, 0, rapid.IntRange(0, bookedAt.DaysBetween(yearEnd)).Draw(t, "recorded_after"))
+		billingClock := &testutil.FakeClock{Now: recordedAt.Time.Add(12 * time.Hour)}
+
+		currencyCode := core.MustParseCurrencyCode(base.EnrollReseller.CurrencyCode)
+		places := currencyCode.MinorUnits()
+		n := rapid.IntRange(core.ResellerBillingItemsMin, 5).Draw(t, "item_count")
+		items := make([]core.RecordBillingItemInput, n)
+		for i := range items {
+			items[i] = core.RecordBillingItemInput{
+				ID:           testutil.GenUUID().Draw(t, "item_id"),
+				ProductCode:  rapid.SampledFrom(codes).Draw(t, "product_code"),
+				GrossRevenue: testutil.GenDecimalBetween(core.ResellerBillingGrossRevenueMin, core.NewDecimalFromInt(100_000), places).Draw(t, "gross_revenue"),
+			}
+		}
+		record := core.RecordBillingCommand{
+			ID:                 testutil.GenUUID().Draw(t, "billing_id"),
+			ResellerExternalID: base.EnrollReseller.ExternalID,
+			DocumentNumber:     testutil.GenCode(core.DocumentNumberLengthMin, core.DocumentNumberLengthMax).Draw(t, "document_number"),
+			BookedAt:           bookedAt,
+			Kind:               string(testutil.GenMapKey(core.ResellerBillingKinds, func(a, b core.ResellerBillingKind) int { return strings.Compare(string(a), string(b)) }).Draw(t, "kind")),
+			CurrencyCode:       base.EnrollReseller.CurrencyCode,
+			Items:              items,
+		}
+
+		return RecordBillingFixture{
+			Base:                  base,
+			CreateProductGroup:    createProductGroup,
+			AddProductGroupWeight: addWeight,
+			CreateProducts:        createProducts,
+			BillingClock:          billingClock,
+			RecordBilling:         record,
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package reseller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type ResellerTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (rt *ResellerTests) SetupSuite() {
+	rt.ctx = context.Background()
+	rt.config = testutil.LoadConfig()
+	rt.clock = &testutil.SwitchableClock{}
+	rt.dispatcher = infrastructure.NewDispatcher(rt.ctx, *testutil.Config, infrastructure.WithClock(rt.clock))
+}
+
+func (rt *ResellerTests) TearDownSuite() {
+	rt.dispatcher.Close()
+}
+
+func (rt *ResellerTests) cleanUp() {
+	testutil.ResetDB(rt.ctx, rt.dispatcher.PgxPool)
+}
+
+func (rt *ResellerTests) setup(t *rapid.T, fx EnrollResellerValidFixture) {
+	rt.clock.Current = fx.Clock
+	rt.setupCurrency(t, fx.EnrollReseller.CurrencyCode)
+	_, err := rt.dispatcher.EnrollReseller(rt.ctx, fx.EnrollReseller)
+	require.NoError(t, err)
+}
+
+func (rt *ResellerTests) setupCurrency(t *rapid.T, code string) {
+	create := core.CreateCurrencyCommand{ID: uuid.New(), Code: code}
+	_, err := rt.dispatcher.CreateCurrency(rt.ctx, create)
+	require.NoError(t, err)
+}
+
+func (rt *ResellerTests) TestEnrollResellerValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genEnrollResellerValid().Draw(t, "fx")
+
+		rt.setup(t, fx)
+
+		r, err := rt.dispatcher.GetReseller(rt.ctx, fx.GetReseller)
+		require.NoError(t, err)
+		assert.Equal(t, fx.EnrollReseller.ID, r.ID)
+		assert.Equal(t, fx.EnrollReseller.ExternalID, r.ExternalID)
+		assert.Equal(t, fx.EnrollReseller.CountryCode, r.CountryCode)
+		assert.Equal(t, fx.EnrollReseller.CurrencyCode, r.CurrencyCode)
+		assert.Equal(t, fx.EnrollReseller.EnrolledAt, r.EnrolledAt)
+		assert.Equal(t, core.ResellerRoleOrphan, r.Role)
+		assert.Nil(t, r.CalculatedNetRevenueYearToDate)
+
+		byExternalID, err := rt.dispatcher.GetResellerByExternalID(rt.ctx, core.GetResellerByExternalIDQuery{ExternalID: fx.EnrollReseller.ExternalID})
+		require.NoError(t, err)
+		assert.Equal(t, r, byExternalID)
+	})
+}
+
+func (rt *ResellerTests) TestEnrollResellerDuplicateIDInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genEnrollResellerDuplicateIDInvalid().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+
+		_, err := rt.dispatcher.EnrollReseller(rt.ctx, fx.EnrollReseller)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Reseller", e.Entity)
+		assert.Contains(t, e.FieldValues, "ID")
+	})
+}
+
+func (rt *ResellerTests) TestEnrollResellerDuplicateExternalIDInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genEnrollResellerDuplicateExternalIDInvalid().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+
+		_, err := rt.dispatcher.EnrollReseller(rt.ctx, fx.EnrollReseller)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Reseller", e.Entity)
+		assert.Contains(t, e.FieldValues, "ExternalID")
+	})
+}
+
+func (rt *ResellerTests) TestEnrollResellerUnknownCurrencyInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genEnrollResellerValid().Draw(t, "fx")
+		rt.clock.Current = fx.Clock
+
+		_, err := rt.dispatcher.EnrollReseller(rt.ctx, fx.EnrollReseller)
+
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Currency", e.Entity)
+	})
+}
+
+// setupRole gives the reseller a role as if it had joined a cluster, without
+// setting up the cluster.
+func (rt *ResellerTests) setupRole(t *rapid.T, id uuid.UUID, role core.ResellerRole) {
+	q := "UPDATE reseller SET role = $1 WHERE id = $2"
+	_, err := rt.dispatcher.PgxPool.Exec(rt.ctx, q, string(role), id)
+	require.NoError(t, err)
+}
+
+func (rt *ResellerTests) TestChangeResellerCurrencyValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genChangeResellerCurrency().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+		rt.setupCurrency(t, fx.ChangeResellerCurrency.CurrencyCode)
+
+		_, err := rt.dispatcher.ChangeResellerCurrency(rt.ctx, fx.ChangeResellerCurrency)
+		require.NoError(t, err)
+
+		r, err := rt.dispatcher.GetReseller(rt.ctx, fx.Base.GetReseller)
+		require.NoError(t, err)
+		assert.Equal(t, fx.ChangeResellerCurrency.CurrencyCode, r.CurrencyCode)
+	})
+}
+
+func (rt *ResellerTests) TestChangeResellerCurrencyUnknownInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genChangeResellerCurrency().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+
+		_, err := rt.dispatcher.ChangeResellerCurrency(rt.ctx, fx.ChangeResellerCurrency)
+
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Currency", e.Entity)
+	})
+}
+
+func (rt *ResellerTests) TestUnenrollResellerValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genEnrollResellerValid().Draw(t, "fx")
+		rt.setup(t, fx)
+
+		_, err := rt.dispatcher.UnenrollReseller(rt.ctx, core.UnenrollResellerCommand{ID: fx.EnrollReseller.ID})
+		require.NoError(t, err)
+
+		_, err = rt.dispatcher.GetReseller(rt.ctx, fx.GetReseller)
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+	})
+}
+
+func (rt *ResellerTests) TestUnenrollResellerNotOrphanInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genEnrollResellerValid().Draw(t, "fx")
+		rt.setup(t, fx)
+		rt.setupRole(t, fx.EnrollReseller.ID, core.ResellerRoleHead)
+
+		_, err := rt.dispatcher.UnenrollReseller(rt.ctx, core.UnenrollResellerCommand{ID: fx.EnrollReseller.ID})
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ExpectedOrphanResellerRole, e.Code)
+	})
+}
+
+// setupBilling sets up what recording the fixture's billing depends on and
+// switches to the billing clock.
+func (rt *ResellerTests) setupBilling(t *rapid.T, fx RecordBillingFixture) {
+	rt.setup(t, fx.Base)
+	_, err := rt.dispatcher.CreateProductGroup(rt.ctx, fx.CreateProductGroup)
+	require.NoError(t, err)
+	_, err = rt.dispatcher.AddProductGroupWeight(rt.ctx, fx.AddProductGroupWeight)
+	require.NoError(t, err)
+	for _, create := range fx.CreateProducts {
+		_, err := rt.dispatcher.CreateProduct(rt.ctx, create)
+		require.NoError(t, err)
+		assign := core.AssignProductGroupCommand{Code: create.Code, ProductGroupCode: fx.CreateProductGroup.Code}
+		_, err = rt.dispatcher.AssignProductGroup(rt.ctx, assign)
+		require.NoError(t, err)
+	}
+	rt.clock.Current = fx.BillingClock
+}
+
+func (rt *ResellerTests) TestRecordBillingValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRecordBilling().Draw(t, "fx")
+		rt.setupBilling(t, fx)
+
+		_, err := rt.dispatcher.RecordBilling(rt.ctx, fx.RecordBilling)
+		require.NoError(t, err)
+
+		expected := core.NewDecimalFromInt(0)
+		for _, item := range fx.RecordBilling.Items {
+			expected = expected.Add(item.GrossRevenue)
+		}
+		if fx.RecordBilling.Kind == string(core.ResellerBillingKindCreditMemo) {
+			expected = expected.Neg()
+		}
+		r, err := rt.dispatcher.GetReseller(rt.ctx, fx.Base.GetReseller)
+		require.NoError(t, err)
+		require.NotNil(t, r.CalculatedNetRevenueYearToDate)
+		assert.Equal(t, expected, r.CalculatedNetRevenueYearToDate.Amount)
+
+		var items int
+		q := "SELECT count(*) FROM billing_item WHERE billing_id = $1"
+		require.NoError(t, rt.dispatcher.PgxPool.QueryRow(rt.ctx, q, fx.RecordBilling.ID).Scan(&items))
+		assert.Equal(t, len(fx.RecordBilling.Items), items)
+	})
+}
+
+func (rt *ResellerTests) TestRecordBillingDuplicateDocumentNumberInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRecordBilling().Draw(t, "fx")
+		rt.setupBilling(t, fx)
+		_, err := rt.dispatcher.RecordBilling(rt.ctx, fx.RecordBilling)
+		require.NoError(t, err)
+		duplicate := fx.RecordBilling
+		duplicate.ID = uuid.New()
+		duplicate.Items = nil
+		for _, item := range fx.RecordBilling.Items {
+			item.ID = uuid.New()
+			duplicate.Items = append(duplicate.Items, item)
+		}
+
+		_, err = rt.dispatcher.RecordBilling(rt.ctx, duplicate)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "ResellerBilling", e.Entity)
+		assert.Equal(t, duplicate.DocumentNumber, e.FieldValues["DocumentNumber"])
+	})
+}
+
+func (rt *ResellerTests) TestRecordBillingBeforeLastYearInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRecordBilling().Draw(t, "fx")
+		rt.setupBilling(t, fx)
+		rt.clock.Current = &testutil.FakeClock{Now: time.Date(fx.RecordBilling.BookedAt.Year()+2, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+		_, err := rt.dispatcher.RecordBilling(rt.ctx, fx.RecordBilling)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ResellerBillingExpectedRecent, e.Code)
+		var billings int
+		q := "SELECT count(*) FROM billing WHERE id = $1"
+		require.NoError(t, rt.dispatcher.PgxPool.QueryRow(rt.ctx, q, fx.RecordBilling.ID).Scan(&billings))
+		assert.Zero(t, billings)
+	})
+}
+
+func (rt *ResellerTests) TestRemoveBilledProductInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRecordBilling().Draw(t, "fx")
+		rt.setupBilling(t, fx)
+		_, err := rt.dispatcher.RecordBilling(rt.ctx, fx.RecordBilling)
+		require.NoError(t, err)
+
+		remove := core.RemoveProductCommand{Code: fx.RecordBilling.Items[0].ProductCode}
+		_, err = rt.dispatcher.RemoveProduct(rt.ctx, remove)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ProductExpectedNoBillingsForRemoval, e.Code)
+	})
+}
+
+func TestReseller(t *testing.T) {
+	suite.Run(t, new(ResellerTests))
+}
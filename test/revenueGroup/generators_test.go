@@ -0,0 +1,203 @@
+package revenueGroup_test
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genCountryCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CountryCodes, strings.Compare)
+}
+
+func genCurrencyCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CurrencyCodes, strings.Compare)
+}
+
+func genCreateRevenueGroupCommand() *rapid.Generator[core.CreateRevenueGroupCommand] {
+	return rapid.Custom(func(t *rapid.T) core.CreateRevenueGroupCommand {
+		return core.CreateRevenueGroupCommand{
+			ID:           testutil.GenUUID().Draw(t, "id"),
+			CountryCode:  genCountryCode().Draw(t, "country_code"),
+			CurrencyCode: genCurrencyCode().Draw(t, "currency_code"),
+		}
+	})
+}
+
+type CreateRevenueGroupValidFixture struct {
+	Clock              core.Clock
+	CreateRevenueGroup core.CreateRevenueGroupCommand
+	GetRevenueGroup    core.GetRevenueGroupQuery
+}
+
+func genCreateRevenueGroupValid() *rapid.Generator[CreateRevenueGroupValidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateRevenueGroupValidFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		create := genCreateRevenueGroupCommand().Draw(t, "create_revenue_group")
+		get := core.GetRevenueGroupQuery{
+			CountryCode: create.CountryCode,
+		}
+		return CreateRevenueGroupValidFixture{
+			Clock:              clock,
+			CreateRevenueGroup: create,
+			GetRevenueGroup:    get,
+		}
+	})
+}
+
+type CreateRevenueGroupDuplicateInvalidFixture struct {
+	Base               CreateRevenueGroupValidFixture
+	CreateRevenueGroup core.CreateRevenueGroupCommand
+}
+
+func genCreateRevenueGroupDuplicateCountryCodeInvalid() *rapid.Generator[CreateRevenueGroupDuplicateInvalidFixture] {
+	return rapid.Custom(func(t *rapid.T) CreateRevenueGroupDuplicateInvalidFixture {
+		base := genCreateRevenueGroupValid().Draw(t, "base")
+		create := genCreateRevenueGroupCommand().Draw(t, "create_revenue_group")
+		create.CountryCode = base.CreateRevenueGroup.CountryCode
+		create.CurrencyCode = base.CreateRevenueGroup.CurrencyCode
+		return CreateRevenueGroupDuplicateInvalidFixture{
+			Base:               base,
+			CreateRevenueGroup: create,
+		}
+	})
+}
+
+func genLimit(places int) *rapid.Generator[core.Decimal] {
+	return testutil.GenDecimalBetween(core.RevenueLimitMin, core.RevenueLimitMax, places)
+}
+
+// genRevenueLimits draws three distinct limits, with at most the minor units
+// of the revenue group's currency, and assigns them in ascending order, as
+// tiers require.
+func genRevenueLimits(currencyCode string) *rapid.Generator[core.RevenueLimitsInput] {
+	places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+	return rapid.Custom(func(t *rapid.T) core.RevenueLimitsInput {
+		limits := rapid.SliceOfNDistinct(genLimit(places), 3, 3, core.Decimal.String).Draw(t, "limits")
+		slices.SortFunc(limits, core.Decimal.Cmp)
+		return core.RevenueLimitsInput{
+			Authorized: limits[0],
+			Advanced:   limits[1],
+			Premier:    limits[2],
+		}
+	})
+}
+
+func genRevenueGroupLimitFrom() *rapid.Generator[core.Date] {
+	return rapid.Custom(func(t *rapid.T) core.Date {
+		return testutil.GenDateBetween(core.RevenueGroupLimitFromMin, core.RevenueGroupLimitFromMax).Draw(t, "from")
+	})
+}
+
+func genRevenueGroupLimitFromAfter(after core.Date) *rapid.Generator[core.Date] {
+	if after.After(core.RevenueGroupLimitFromMax) {
+		panic("after must be after from max")
+	}
+	d := after.AddDate(0, 0, 1)
+	return rapid.Custom(func(t *rapid.T) core.Date {
+		return testutil.GenDateBetween(d, core.RevenueGroupLimitFromMax).Draw(t, "from")
+	})
+}
+
+// genAddRevenueGroupLimitCommand adds a limit to the revenue group created.
+func genAddRevenueGroupLimitCommand(create core.CreateRevenueGroupCommand) *rapid.Generator[core.AddRevenueGroupLimitCommand] {
+	return rapid.Custom(func(t *rapid.T) core.AddRevenueGroupLimitCommand {
+		return core.AddRevenueGroupLimitCommand{
+			ID:          testutil.GenUUID().Draw(t, "id"),
+			CountryCode: create.CountryCode,
+			Limits:      genRevenueLimits(create.CurrencyCode).Draw(t, "limits"),
+			From:        genRevenueGroupLimitFrom().Draw(t, "from"),
+		}
+	})
+}
+
+type AddRevenueGroupLimitFromDateBoundaryFixture struct {
+	Base                 CreateRevenueGroupValidFixture
+	AddRevenueGroupLimit core.AddRevenueGroupLimitCommand
+	ShouldPass           bool
+}
+
+func genAddRevenueGroupLimitFromDateBoundary() *rapid.Generator[AddRevenueGroupLimitFromDateBoundaryFixture] {
+	return rapid.Custom(func(t *rapid.T) AddRevenueGroupLimitFromDateBoundaryFixture {
+		base := genCreateRevenueGroupValid().Draw(t, "base")
+		today := base.Clock.Today()
+
+		min := today.DaysBetween(core.RevenueGroupLimitFromMin)
+		max := today.DaysBetween(core.RevenueGroupLimitFromMax)
+		offset := rapid.IntRange(-min, max).Draw(t, "offset")
+
+		add := genAddRevenueGroupLimitCommand(base.CreateRevenueGroup).Draw(t, "add_revenue_group_limit")
+		add.From = today.AddDate(0, 0, offset)
+
+		return AddRevenueGroupLimitFromDateBoundaryFixture{
+			Base:                 base,
+			AddRevenueGroupLimit: add,
+			ShouldPass:           offset > 0,
+		}
+	})
+}
+
+type UpdateRevenueGroupLimitFixture struct {
+	Base                    CreateRevenueGroupValidFixture
+	AddRevenueGroupLimit    core.AddRevenueGroupLimitCommand
+	UpdateRevenueGroupLimit core.UpdateRevenueGroupLimitCommand
+	ShouldPass              bool
+}
+
+func genUpdateRevenueGroupLimitFromDateBoundary() *rapid.Generator[UpdateRevenueGroupLimitFixture] {
+	return rapid.Custom(func(t *rapid.T) UpdateRevenueGroupLimitFixture {
+		base := genCreateRevenueGroupValid().Draw(t, "base")
+		today := base.Clock.Today()
+
+		add := genAddRevenueGroupLimitCommand(base.CreateRevenueGroup).Draw(t, "add_revenue_group_limit")
+		add.From = genRevenueGroupLimitFromAfter(today).Draw(t, "from")
+
+		min := today.DaysBetween(core.RevenueGroupLimitFromMin)
+		max := today.DaysBetween(core.RevenueGroupLimitFromMax)
+		offset := rapid.IntRange(-min, max).Draw(t, "offset")
+		update := core.UpdateRevenueGroupLimitCommand{
+			ID:          add.ID,
+			CountryCode: add.CountryCode,
+			Limits: genRevenueLimits(base.CreateRevenueGroup.CurrencyCode).
+				Filter(func(l core.RevenueLimitsInput) bool { return l != add.Limits }).
+				Draw(t, "limits"),
+			From: today.AddDate(0, 0, offset),
+		}
+
+		return UpdateRevenueGroupLimitFixture{
+			Base:                    base,
+			AddRevenueGroupLimit:    add,
+			UpdateRevenueGroupLimit: update,
+			ShouldPass:              offset > 0,
+		}
+	})
+}
+
+type GetEffectiveRevenueGroupLimitFixture struct {
+	Base                  CreateRevenueGroupValidFixture
+	AddRevenueGroupLimits []core.AddRevenueGroupLimitCommand
+	On                    core.Date
+}
+
+func genGetEffectiveRevenueGroupLimit() *rapid.Generator[GetEffectiveRevenueGroupLimitFixture] {
+	return rapid.Custom(func(t *rapid.T) GetEffectiveRevenueGroupLimitFixture {
+		base := genCreateRevenueGroupValid().Draw(t, "base")
+		froms := rapid.SliceOfNDistinct(genRevenueGroupLimitFromAfter(base.Clock.Today()), 0, 3, core.Date.String).
+			Draw(t, "froms")
+
+		adds := make([]core.AddRevenueGroupLimitCommand, len(froms))
+		for i, from := range froms {
+			adds[i] = genAddRevenueGroupLimitCommand(base.CreateRevenueGroup).Draw(t, "add_revenue_group_limit")
+			adds[i].From = from
+		}
+
+		return GetEffectiveRevenueGroupLimitFixture{
+			Base:                  base,
+			AddRevenueGroupLimits: adds,
+			On:                    genRevenueGroupLimitFrom().Draw(t, "on"),
+		}
+	})
+}
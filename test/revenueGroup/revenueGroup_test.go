@@ -0,0 +1,199 @@
+package revenueGroup_test
+
+import (
+	"context"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type RevenueGroupTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (rt *RevenueGroupTests) SetupSuite() {
+	rt.ctx = context.Background()
+	rt.config = testutil.LoadConfig()
+	rt.clock = &testutil.SwitchableClock{}
+	rt.dispatcher = infrastructure.NewDispatcher(rt.ctx, *testutil.Config, infrastructure.WithClock(rt.clock))
+}
+
+func (rt *RevenueGroupTests) TearDownSuite() {
+	rt.dispatcher.Close()
+}
+
+func (rt *RevenueGroupTests) cleanUp() {
+	testutil.ResetDB(rt.ctx, rt.dispatcher.PgxPool)
+}
+
+func (rt *RevenueGroupTests) setup(t *rapid.T, fx CreateRevenueGroupValidFixture) {
+	rt.clock.Current = fx.Clock
+	_, err := rt.dispatcher.CreateCurrency(rt.ctx, core.CreateCurrencyCommand{ID: uuid.New(), Code: fx.CreateRevenueGroup.CurrencyCode})
+	require.NoError(t, err)
+	_, err = rt.dispatcher.CreateRevenueGroup(rt.ctx, fx.CreateRevenueGroup)
+	require.NoError(t, err)
+}
+
+func (rt *RevenueGroupTests) TestCreateRevenueGroupValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genCreateRevenueGroupValid().Draw(t, "fx")
+
+		rt.setup(t, fx)
+
+		rg, err := rt.dispatcher.GetRevenueGroup(rt.ctx, fx.GetRevenueGroup)
+		require.NoError(t, err)
+		assert.Equal(t, fx.CreateRevenueGroup.ID, rg.ID)
+		assert.Equal(t, fx.CreateRevenueGroup.CountryCode, rg.CountryCode)
+		assert.Equal(t, fx.CreateRevenueGroup.CurrencyCode, rg.CurrencyCode)
+		assert.Empty(t, rg.Limits)
+	})
+}
+
+func (rt *RevenueGroupTests) TestCreateRevenueGroupDuplicateCountryCodeInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genCreateRevenueGroupDuplicateCountryCodeInvalid().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+
+		_, err := rt.dispatcher.CreateRevenueGroup(rt.ctx, fx.CreateRevenueGroup)
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "RevenueGroup", e.Entity)
+		assert.Equal(t, fx.CreateRevenueGroup.CountryCode, e.FieldValues["CountryCode"])
+	})
+}
+
+func (rt *RevenueGroupTests) TestCreateRevenueGroupUnknownCurrencyInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genCreateRevenueGroupValid().Draw(t, "fx")
+		rt.clock.Current = fx.Clock
+
+		_, err := rt.dispatcher.CreateRevenueGroup(rt.ctx, fx.CreateRevenueGroup)
+
+		var e *core.NotFoundError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Currency", e.Entity)
+	})
+}
+
+func (rt *RevenueGroupTests) TestAddRevenueGroupLimitFromDateBoundary() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genAddRevenueGroupLimitFromDateBoundary().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+
+		_, err := rt.dispatcher.AddRevenueGroupLimit(rt.ctx, fx.AddRevenueGroupLimit)
+
+		if fx.ShouldPass {
+			require.NoError(t, err)
+			rg, err := rt.dispatcher.GetRevenueGroup(rt.ctx, fx.Base.GetRevenueGroup)
+			require.NoError(t, err)
+			require.Len(t, rg.Limits, 1)
+			l := rg.Limits[0]
+			assert.Equal(t, fx.AddRevenueGroupLimit.ID, l.ID)
+			assert.Equal(t, fx.AddRevenueGroupLimit.Limits.Authorized, l.Authorized.Amount)
+			assert.Equal(t, fx.AddRevenueGroupLimit.Limits.Advanced, l.Advanced.Amount)
+			assert.Equal(t, fx.AddRevenueGroupLimit.Limits.Premier, l.Premier.Amount)
+			assert.Equal(t, fx.Base.CreateRevenueGroup.CurrencyCode, l.Premier.Code)
+			assert.Equal(t, fx.AddRevenueGroupLimit.From, l.From)
+		} else {
+			var e *core.DomainError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, core.RevenueGroupExpectedFutureFromForAdd, e.Code)
+		}
+	})
+}
+
+func (rt *RevenueGroupTests) TestUpdateRevenueGroupLimitFromDateBoundary() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genUpdateRevenueGroupLimitFromDateBoundary().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+		_, err := rt.dispatcher.AddRevenueGroupLimit(rt.ctx, fx.AddRevenueGroupLimit)
+		require.NoError(t, err)
+
+		_, err = rt.dispatcher.UpdateRevenueGroupLimit(rt.ctx, fx.UpdateRevenueGroupLimit)
+
+		if fx.ShouldPass {
+			require.NoError(t, err)
+			rg, err := rt.dispatcher.GetRevenueGroup(rt.ctx, fx.Base.GetRevenueGroup)
+			require.NoError(t, err)
+			require.Len(t, rg.Limits, 1)
+			assert.Equal(t, fx.UpdateRevenueGroupLimit.Limits.Authorized, rg.Limits[0].Authorized.Amount)
+			assert.Equal(t, fx.UpdateRevenueGroupLimit.Limits.Advanced, rg.Limits[0].Advanced.Amount)
+			assert.Equal(t, fx.UpdateRevenueGroupLimit.Limits.Premier, rg.Limits[0].Premier.Amount)
+			assert.Equal(t, fx.UpdateRevenueGroupLimit.From, rg.Limits[0].From)
+		} else {
+			var e *core.DomainError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, core.RevenueGroupExpectedFutureFromForUpdate, e.Code)
+		}
+	})
+}
+
+func (rt *RevenueGroupTests) TestRemoveRevenueGroupLimitValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genUpdateRevenueGroupLimitFromDateBoundary().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+		_, err := rt.dispatcher.AddRevenueGroupLimit(rt.ctx, fx.AddRevenueGroupLimit)
+		require.NoError(t, err)
+
+		cmd := core.RemoveRevenueGroupLimitCommand{ID: fx.AddRevenueGroupLimit.ID, CountryCode: fx.AddRevenueGroupLimit.CountryCode}
+		_, err = rt.dispatcher.RemoveRevenueGroupLimit(rt.ctx, cmd)
+		require.NoError(t, err)
+
+		rg, err := rt.dispatcher.GetRevenueGroup(rt.ctx, fx.Base.GetRevenueGroup)
+		require.NoError(t, err)
+		assert.Empty(t, rg.Limits)
+	})
+}
+
+func (rt *RevenueGroupTests) TestGetEffectiveRevenueGroupLimit() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genGetEffectiveRevenueGroupLimit().Draw(t, "fx")
+		rt.setup(t, fx.Base)
+		var expected *core.AddRevenueGroupLimitCommand
+		for _, add := range fx.AddRevenueGroupLimits {
+			_, err := rt.dispatcher.AddRevenueGroupLimit(rt.ctx, add)
+			require.NoError(t, err)
+			if !add.From.After(fx.On) && (expected == nil || add.From.After(expected.From)) {
+				expected = &add
+			}
+		}
+
+		qry := core.GetEffectiveRevenueGroupLimitQuery{CountryCode: fx.Base.CreateRevenueGroup.CountryCode, On: fx.On}
+		l, err := rt.dispatcher.GetEffectiveRevenueGroupLimit(rt.ctx, qry)
+
+		if expected == nil {
+			var noLimit *core.NoRevenueGroupLimitError
+			require.ErrorAs(t, err, &noLimit)
+			assert.Equal(t, qry.CountryCode, noLimit.CountryCode)
+			assert.Equal(t, qry.On, noLimit.On)
+			return
+		}
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID, l.ID)
+		assert.Equal(t, expected.Limits.Premier, l.Premier.Amount)
+		assert.True(t, expected.From.Equal(l.From))
+	})
+}
+
+func TestRevenueGroup(t *testing.T) {
+	suite.Run(t, new(RevenueGroupTests))
+}
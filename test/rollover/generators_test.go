@@ -0,0 +1,156 @@
+package rollover_test
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genCountryCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CountryCodes, strings.Compare)
+}
+
+func genCurrencyCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CurrencyCodes, strings.Compare)
+}
+
+// genRevenueLimits draws limits in the range of the cluster's net revenue so
+// that every tier, and no tier, is reached. Limits have at most the minor
+// units of the revenue group's currency.
+func genRevenueLimits(currencyCode string) *rapid.Generator[core.RevenueLimitsInput] {
+	places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+	return rapid.Custom(func(t *rapid.T) core.RevenueLimitsInput {
+		limit := testutil.GenDecimalBetween(core.NewDecimalFromInt(0), core.NewDecimalFromInt(300_000), places)
+		limits := rapid.SliceOfNDistinct(limit, 3, 3, core.Decimal.String).Draw(t, "limits")
+		slices.SortFunc(limits, core.Decimal.Cmp)
+		return core.RevenueLimitsInput{
+			Authorized: limits[0],
+			Advanced:   limits[1],
+			Premier:    limits[2],
+		}
+	})
+}
+
+func beforeYearEnd(c core.Clock) bool {
+	today := c.Today()
+	return today.Month() != 12 || today.Day() != 31
+}
+
+type RollOverYearFixture struct {
+	Clock                 core.Clock
+	CurrencyCode          string
+	CreateRevenueGroup    core.CreateRevenueGroupCommand
+	AddRevenueGroupLimit  core.AddRevenueGroupLimitCommand
+	CreateProductGroup    core.CreateProductGroupCommand
+	AddProductGroupWeight core.AddProductGroupWeightCommand
+	CreateProduct         core.CreateProductCommand
+	EnrollResellers       []core.EnrollResellerCommand
+	CreateCluster         core.CreateClusterCommand
+	BillingClock          core.Clock
+	RecordBillings        []core.RecordBillingCommand
+	RolloverClock         core.Clock
+}
+
+// genRollOverYear sets up a cluster of resellers billed in the revenue group's
+// currency. Limits and a product group weighing 100 percent must be from after
+// today, so billings are recorded on a later clock, within the year billings
+// are booked and the cluster is created. The year is rolled over on a clock in
+// the next year.
+func genRollOverYear() *rapid.Generator[RollOverYearFixture] {
+	return rapid.Custom(func(t *rapid.T) RollOverYearFixture {
+		clock := testutil.GenFakeClock().Filter(beforeYearEnd).Draw(t, "clock")
+		from := clock.Today().AddDate(0, 0, 1)
+		countryCode := genCountryCode().Draw(t, "country_code")
+		currencyCode := genCurrencyCode().Draw(t, "currency_code")
+
+		createRevenueGroup := core.CreateRevenueGroupCommand{
+			ID:           testutil.GenUUID().Draw(t, "revenue_group_id"),
+			CountryCode:  countryCode,
+			CurrencyCode: currencyCode,
+		}
+		addLimit := core.AddRevenueGroupLimitCommand{
+			ID:          testutil.GenUUID().Draw(t, "revenue_group_limit_id"),
+			CountryCode: countryCode,
+			Limits:      genRevenueLimits(currencyCode).Draw(t, "limits"),
+			From:        from,
+		}
+
+		createProductGroup := core.CreateProductGroupCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_group_id"),
+			Code: testutil.GenCode(core.ProductGroupCodeLengthMin, core.ProductGroupCodeLengthMax).Draw(t, "product_group_code"),
+		}
+		addWeight := core.AddProductGroupWeightCommand{
+			ID:         testutil.GenUUID().Draw(t, "product_group_weight_id"),
+			Code:       createProductGroup.Code,
+			Percentage: core.NewDecimalFromInt(100),
+			From:       from,
+		}
+		createProduct := core.CreateProductCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_id"),
+			Code: testutil.GenCode(core.ProductCodeLengthMin, core.ProductCodeLengthMax).Draw(t, "product_code"),
+		}
+
+		ids := rapid.SliceOfNDistinct(testutil.GenUUID(), 1, 3, rapid.ID).Draw(t, "reseller_ids")
+		enrolls := make([]core.EnrollResellerCommand, len(ids))
+		for i, id := range ids {
+			enrolls[i] = core.EnrollResellerCommand{
+				ID:           id,
+				ExternalID:   testutil.GenUUID().Draw(t, "external_id"),
+				CountryCode:  countryCode,
+				CurrencyCode: currencyCode,
+				EnrolledAt:   testutil.GenDateBetween(core.ResellerEnrolledAtMin, core.ResellerEnrolledAtMax).Draw(t, "enrolled_at"),
+			}
+		}
+		createCluster := core.CreateClusterCommand{
+			ID:             testutil.GenUUID().Draw(t, "cluster_id"),
+			ExternalID:     testutil.GenUUID().Draw(t, "cluster_external_id"),
+			RevenueGroupID: createRevenueGroup.ID,
+			HeadResellerID: enrolls[0].ID,
+		}
+
+		yearEnd := core.NewDate(from.Year(), 12, 31)
+		recordedAt := from.AddDate(0, 0, rapid.IntRange(0, from.DaysBetween(yearEnd)).Draw(t, "recorded_after"))
+		billingClock := &testutil.FakeClock{Now: recordedAt.Time.Add(12 * time.Hour)}
+		rolloverAt := yearEnd.AddDate(0, 0, rapid.IntRange(1, 365).Draw(t, "rollover_after"))
+		rolloverClock := &testutil.FakeClock{Now: rolloverAt.Time.Add(12 * time.Hour)}
+
+		places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+		documentNumbers := rapid.SliceOfNDistinct(testutil.GenCode(core.DocumentNumberLengthMin, core.DocumentNumberLengthMax), len(enrolls), len(enrolls), rapid.ID).
+			Draw(t, "document_numbers")
+		records := make([]core.RecordBillingCommand, len(enrolls))
+		for i, enroll := range enrolls {
+			records[i] = core.RecordBillingCommand{
+				ID:                 testutil.GenUUID().Draw(t, "billing_id"),
+				ResellerExternalID: enroll.ExternalID,
+				DocumentNumber:     documentNumbers[i],
+				BookedAt:           testutil.GenDateBetween(from, recordedAt).Draw(t, "booked_at"),
+				Kind:               string(core.ResellerBillingKindInvoice),
+				CurrencyCode:       currencyCode,
+				Items: []core.RecordBillingItemInput{{
+					ID:           testutil.GenUUID().Draw(t, "item_id"),
+					ProductCode:  createProduct.Code,
+					GrossRevenue: testutil.GenDecimalBetween(core.ResellerBillingGrossRevenueMin, core.NewDecimalFromInt(150_000), places).Draw(t, "gross_revenue"),
+				}},
+			}
+		}
+
+		return RollOverYearFixture{
+			Clock:                 clock,
+			CurrencyCode:          currencyCode,
+			CreateRevenueGroup:    createRevenueGroup,
+			AddRevenueGroupLimit:  addLimit,
+			CreateProductGroup:    createProductGroup,
+			AddProductGroupWeight: addWeight,
+			CreateProduct:         createProduct,
+			EnrollResellers:       enrolls,
+			CreateCluster:         createCluster,
+			BillingClock:          billingClock,
+			RecordBillings:        records,
+			RolloverClock:         rolloverClock,
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package rollover_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type RolloverTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (rt *RolloverTests) SetupSuite() {
+	rt.ctx = context.Background()
+	rt.config = testutil.LoadConfig()
+	rt.clock = &testutil.SwitchableClock{}
+	rt.dispatcher = infrastructure.NewDispatcher(rt.ctx, *testutil.Config, infrastructure.WithClock(rt.clock))
+}
+
+func (rt *RolloverTests) TearDownSuite() {
+	rt.dispatcher.Close()
+}
+
+func (rt *RolloverTests) cleanUp() {
+	testutil.ResetDB(rt.ctx, rt.dispatcher.PgxPool)
+}
+
+// setup leaves the clock at the rollover clock.
+func (rt *RolloverTests) setup(t *rapid.T, fx RollOverYearFixture) {
+	rt.clock.Current = fx.Clock
+	_, err := rt.dispatcher.CreateCurrency(rt.ctx, core.CreateCurrencyCommand{ID: uuid.New(), Code: fx.CurrencyCode})
+	require.NoError(t, err)
+	_, err = rt.dispatcher.CreateRevenueGroup(rt.ctx, fx.CreateRevenueGroup)
+	require.NoError(t, err)
+	_, err = rt.dispatcher.AddRevenueGroupLimit(rt.ctx, fx.AddRevenueGroupLimit)
+	require.NoError(t, err)
+
+	_, err = rt.dispatcher.CreateProductGroup(rt.ctx, fx.CreateProductGroup)
+	require.NoError(t, err)
+	_, err = rt.dispatcher.AddProductGroupWeight(rt.ctx, fx.AddProductGroupWeight)
+	require.NoError(t, err)
+	_, err = rt.dispatcher.CreateProduct(rt.ctx, fx.CreateProduct)
+	require.NoError(t, err)
+	assign := core.AssignProductGroupCommand{Code: fx.CreateProduct.Code, ProductGroupCode: fx.CreateProductGroup.Code}
+	_, err = rt.dispatcher.AssignProductGroup(rt.ctx, assign)
+	require.NoError(t, err)
+
+	for _, enroll := range fx.EnrollResellers {
+		_, err := rt.dispatcher.EnrollReseller(rt.ctx, enroll)
+		require.NoError(t, err)
+	}
+	_, err = rt.dispatcher.CreateCluster(rt.ctx, fx.CreateCluster)
+	require.NoError(t, err)
+	for _, enroll := range fx.EnrollResellers[1:] {
+		add := core.AddClusterMemberCommand{ClusterID: fx.CreateCluster.ID, ResellerID: enroll.ID}
+		_, err := rt.dispatcher.AddClusterMember(rt.ctx, add)
+		require.NoError(t, err)
+	}
+
+	rt.clock.Current = fx.BillingClock
+	for _, record := range fx.RecordBillings {
+		_, err := rt.dispatcher.RecordBilling(rt.ctx, record)
+		require.NoError(t, err)
+	}
+	rt.clock.Current = fx.RolloverClock
+}
+
+func (rt *RolloverTests) TestRollOverYearValid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRollOverYear().Draw(t, "fx")
+		rt.setup(t, fx)
+
+		report, err := rt.dispatcher.RollOverYear(rt.ctx, core.RollOverYearCommand{ID: uuid.New()})
+		require.NoError(t, err)
+
+		fiscalYear := fx.BillingClock.Today().Year()
+		assert.True(t, report.Recorded)
+		assert.Equal(t, fiscalYear, report.FiscalYear)
+		assert.Equal(t, len(fx.EnrollResellers), report.ResellersRolled)
+		assert.Empty(t, report.ClustersFailed)
+		require.Len(t, report.Clusters, 1)
+
+		netRevenue := core.NewDecimalFromInt(0)
+		for i, record := range fx.RecordBillings {
+			netRevenue = netRevenue.Add(record.Items[0].GrossRevenue)
+			r, err := rt.dispatcher.GetReseller(rt.ctx, core.GetResellerQuery{ID: fx.EnrollResellers[i].ID})
+			require.NoError(t, err)
+			assert.Equal(t, fiscalYear+1, r.FiscalYear)
+			assert.Nil(t, r.CalculatedNetRevenueYearToDate)
+			require.NotNil(t, r.CalculatedNetRevenueLastYear)
+			assert.Zero(t, record.Items[0].GrossRevenue.Cmp(r.CalculatedNetRevenueLastYear.Amount))
+		}
+
+		policy := core.MustParseMinimumTierPolicy(rt.config.MinimumTierDrop)
+		limits := core.MustParseRevenueLimits(core.MustParseCurrencyCode(fx.CurrencyCode), fx.AddRevenueGroupLimit.Limits.Authorized, fx.AddRevenueGroupLimit.Limits.Advanced, fx.AddRevenueGroupLimit.Limits.Premier)
+		minimum := policy.Floor(limits.Tier(netRevenue))
+		c, err := rt.dispatcher.GetCluster(rt.ctx, core.GetClusterQuery{ID: fx.CreateCluster.ID})
+		require.NoError(t, err)
+		assert.Equal(t, fiscalYear+1, c.FiscalYear)
+		assert.Nil(t, c.CalculatedNetRevenueYearToDate)
+		require.NotNil(t, c.CalculatedNetRevenueLastYear)
+		assert.Zero(t, netRevenue.Cmp(c.CalculatedNetRevenueLastYear.Amount))
+		assert.Equal(t, minimum, c.CalculatedResellerTierMinimum)
+		assert.Equal(t, minimum, report.Clusters[0].CalculatedResellerTierMinimum)
+		assert.Equal(t, minimum, c.ResellerTier)
+	})
+}
+
+func (rt *RolloverTests) TestRollOverYearTwiceInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRollOverYear().Draw(t, "fx")
+		rt.setup(t, fx)
+		_, err := rt.dispatcher.RollOverYear(rt.ctx, core.RollOverYearCommand{ID: uuid.New()})
+		require.NoError(t, err)
+
+		_, err = rt.dispatcher.RollOverYear(rt.ctx, core.RollOverYearCommand{ID: uuid.New()})
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Rollover", e.Entity)
+		assert.Equal(t, fmt.Sprint(fx.BillingClock.Today().Year()), e.FieldValues["FiscalYear"])
+	})
+}
+
+func (rt *RolloverTests) TestTierClustersBeforeRollOverNotRecorded() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRollOverYear().Draw(t, "fx")
+		rt.setup(t, fx)
+
+		report, err := rt.dispatcher.TierClusters(rt.ctx, core.TierClustersCommand{ID: uuid.New()})
+		require.NoError(t, err)
+		assert.False(t, report.Recorded)
+		require.Len(t, report.Failed, 1)
+
+		_, err = rt.dispatcher.RollOverYear(rt.ctx, core.RollOverYearCommand{ID: uuid.New()})
+		require.NoError(t, err)
+		report, err = rt.dispatcher.TierClusters(rt.ctx, core.TierClustersCommand{ID: uuid.New()})
+		require.NoError(t, err)
+		assert.True(t, report.Recorded)
+	})
+}
+
+func (rt *RolloverTests) TestRecordBillingLastYearAfterRollOverInvalid() {
+	rapid.Check(rt.T(), func(t *rapid.T) {
+		rt.cleanUp()
+		fx := genRollOverYear().Draw(t, "fx")
+		rt.setup(t, fx)
+		_, err := rt.dispatcher.RollOverYear(rt.ctx, core.RollOverYearCommand{ID: uuid.New()})
+		require.NoError(t, err)
+
+		late := fx.RecordBillings[0]
+		late.ID = uuid.New()
+		late.DocumentNumber = testutil.GenCode(core.DocumentNumberLengthMin, core.DocumentNumberLengthMax).
+			Filter(func(v string) bool {
+				return !slices.ContainsFunc(fx.RecordBillings, func(r core.RecordBillingCommand) bool { return r.DocumentNumber == v })
+			}).
+			Draw(t, "document_number")
+		late.Items = []core.RecordBillingItemInput{{ID: uuid.New(), ProductCode: late.Items[0].ProductCode, GrossRevenue: late.Items[0].GrossRevenue}}
+		_, err = rt.dispatcher.RecordBilling(rt.ctx, late)
+
+		var e *core.DomainError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, core.ClusterExpectedBookedInFiscalYear, e.Code)
+		r, err := rt.dispatcher.GetReseller(rt.ctx, core.GetResellerQuery{ID: fx.EnrollResellers[0].ID})
+		require.NoError(t, err)
+		require.NotNil(t, r.CalculatedNetRevenueLastYear)
+		assert.Zero(t, fx.RecordBillings[0].Items[0].GrossRevenue.Cmp(r.CalculatedNetRevenueLastYear.Amount))
+	})
+}
+
+func TestRollover(t *testing.T) {
+	suite.Run(t, new(RolloverTests))
+}
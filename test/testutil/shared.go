@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"math"
 	"slices"
+	"strconv"
 	"sync"
 	"time"
 	"uuid"
@@ -43,9 +45,22 @@ func LoadConfig() *infrastructure.Config {
 // DELETE statements must come in reverse dependency order.
 var sql = []string{
 	"DELETE FROM domain_event",
+	"DELETE FROM idempotency_key",
+	"DELETE FROM tiering",
+	"DELETE FROM rollover",
 	"DELETE FROM exchange_rate",
 	"DELETE FROM currency",
 	"DELETE FROM tier_discount",
+	"DELETE FROM billing_item",
+	"DELETE FROM billing",
+	"DELETE FROM product",
+	"DELETE FROM product_group_weight",
+	"DELETE FROM product_group",
+	"DELETE FROM reseller",
+	"DELETE FROM cluster_tier",
+	"DELETE FROM cluster",
+	"DELETE FROM revenue_group_limit",
+	"DELETE FROM revenue_group",
 }
 
 func ResetDB(ctx context.Context, pool *pgxpool.Pool) {
@@ -167,3 +182,25 @@ func GenDateBetween(min, max core.Date) *rapid.Generator[core.Date] {
 		return core.DateFromTime(min.Time.AddDate(0, 0, offset))
 	})
 }
+
+// GenDecimalBetween generates a decimal between min and max, inclusive, with
+// up to maxPlaces decimal places. Min and max must be whole numbers.
+func GenDecimalBetween(min, max core.Decimal, maxPlaces int) *rapid.Generator[core.Decimal] {
+	if min.Scale() != 0 || max.Scale() != 0 {
+		panic("min and max must be whole numbers")
+	}
+	return rapid.Custom(func(t *rapid.T) core.Decimal {
+		places := rapid.IntRange(0, maxPlaces).Draw(t, "decimal_places")
+		ratio := core.NewDecimalFromInt(int64(math.Pow10(places)))
+		lo, _ := strconv.ParseInt(min.Mul(ratio).String(), 10, 64)
+		hi, _ := strconv.ParseInt(max.Mul(ratio).String(), 10, 64)
+		coef := rapid.Int64Range(lo, hi).Draw(t, "coef")
+		return core.NewDecimal(coef, int32(places))
+	})
+}
+
+// GenCode generates a code, such as a product code, of between min and max
+// characters, inclusive, satisfying core.ValidateStringCode.
+func GenCode(min, max int) *rapid.Generator[string] {
+	return rapid.StringOfN(rapid.RuneFrom([]rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-")), min, max, -1)
+}
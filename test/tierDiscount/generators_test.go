@@ -1,7 +1,6 @@
 package tierDiscount_test
 
 import (
-	"math"
 	"slices"
 
 	"github.com/ronnieholm/resellerloyalty/internal/core"
@@ -11,21 +10,15 @@ import (
 
 func genDiscountPercentages() *rapid.Generator[core.DiscountPercentagesInput] {
 	return rapid.Custom(func(t *rapid.T) core.DiscountPercentagesInput {
-		dp := rapid.SliceOfN(
-			rapid.IntRange(core.TierDiscountPercentageDecimalPlacesMin, core.TierDiscountPercentageDecimalPlacesMax),
-			3, 3).Draw(t, "decimal_places")
-		ps := rapid.SliceOfNDistinct(
-			rapid.Float64Range(core.TierDiscountPercentageMin, core.TierDiscountPercentageMax),
+		p := rapid.SliceOfNDistinct(
+			testutil.GenDecimalBetween(
+				core.TierDiscountPercentageMin,
+				core.TierDiscountPercentageMax,
+				core.TierDiscountPercentageDecimalPlacesMax),
 			3, 3,
-			func(f float64) any { return f },
+			func(d core.Decimal) any { return d },
 		).Draw(t, "percentages")
-
-		p := [3]float64{}
-		for i := range p {
-			ratio := math.Pow10(dp[i])
-			p[i] = math.Round(ps[i]*ratio) / ratio
-		}
-		slices.Sort(p[:])
+		slices.SortFunc(p, core.Decimal.Cmp)
 		return core.DiscountPercentagesInput{
 			Authorized: p[0],
 			Advanced:   p[1],
@@ -124,3 +117,64 @@ func genCreateTierDiscountDuplicateIDInvalid() *rapid.Generator[CreateTierDiscou
 // UpdateTierDiscountCommand
 // RemoveTierDiscountCommand
 // GetTierDiscountQuery
+
+type ListTierDiscountsFixture struct {
+	Clock               core.Clock
+	CreateTierDiscounts []core.CreateTierDiscountCommand
+	Limit               int
+}
+
+func genListTierDiscounts() *rapid.Generator[ListTierDiscountsFixture] {
+	return rapid.Custom(func(t *rapid.T) ListTierDiscountsFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		froms := rapid.SliceOfNDistinct(
+			genTierDiscountAfter(clock.Today()),
+			/* min */ 1 /* max */, 10,
+			func(d core.Date) any { return d },
+		).Draw(t, "froms")
+
+		creates := make([]core.CreateTierDiscountCommand, len(froms))
+		for i, from := range froms {
+			creates[i] = genCreateTierDiscountCommand().Draw(t, "create")
+			creates[i].From = from
+		}
+
+		return ListTierDiscountsFixture{
+			Clock:               clock,
+			CreateTierDiscounts: creates,
+			Limit:               rapid.IntRange(core.PageLimitMin, len(froms)).Draw(t, "limit"),
+		}
+	})
+}
+
+type TierDiscountTimelineFixture struct {
+	Clock               core.Clock
+	CreateTierDiscounts []core.CreateTierDiscountCommand
+	On                  core.Date
+}
+
+// genTierDiscountTimeline creates tier discounts from after today, and draws
+// a date to ask about anywhere a tier discount may be from, i.e., before, in,
+// or after the periods of the tier discounts.
+func genTierDiscountTimeline() *rapid.Generator[TierDiscountTimelineFixture] {
+	return rapid.Custom(func(t *rapid.T) TierDiscountTimelineFixture {
+		clock := testutil.GenFakeClock().Draw(t, "clock")
+		froms := rapid.SliceOfNDistinct(
+			genTierDiscountAfter(clock.Today()),
+			/* min */ 1 /* max */, 3,
+			func(d core.Date) any { return d },
+		).Draw(t, "froms")
+
+		creates := make([]core.CreateTierDiscountCommand, len(froms))
+		for i, from := range froms {
+			creates[i] = genCreateTierDiscountCommand().Draw(t, "create")
+			creates[i].From = from
+		}
+
+		return TierDiscountTimelineFixture{
+			Clock:               clock,
+			CreateTierDiscounts: creates,
+			On:                  testutil.GenDateBetween(core.TierDiscountFromMin, core.TierDiscountFromMax).Draw(t, "on"),
+		}
+	})
+}
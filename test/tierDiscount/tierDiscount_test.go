@@ -2,8 +2,11 @@ package tierDiscount_test
 
 import (
 	"context"
+	"slices"
 	"testing"
+	"uuid"
 
+	"github.com/ronnieholm/resellerloyalty/internal/core"
 	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
 	"github.com/ronnieholm/resellerloyalty/test/testutil"
 	"github.com/stretchr/testify/assert"
@@ -47,10 +50,10 @@ func (td *TierDiscountTests) TestCreateTierDiscountValid() {
 		t_, err := td.dispatcher.GetTierDiscount(td.ctx, fx.GetTierDiscount)
 		require.NoError(t, err)
 		assert.Equal(t, fx.CreateTierDiscount.ID, t_.ID)
-		assert.Equal(t, fx.CreateTierDiscount.Percentages.Authorized, t_.Percentages.Authorized())
-		assert.Equal(t, fx.CreateTierDiscount.Percentages.Advanced, t_.Percentages.Advanced())
-		assert.Equal(t, fx.CreateTierDiscount.Percentages.Premier, t_.Percentages.Premier())
-		assert.Equal(t, fx.CreateTierDiscount.From, t_.From.V())
+		assert.Equal(t, fx.CreateTierDiscount.Percentages.Authorized, t_.Percentages.Authorized)
+		assert.Equal(t, fx.CreateTierDiscount.Percentages.Advanced, t_.Percentages.Advanced)
+		assert.Equal(t, fx.CreateTierDiscount.Percentages.Premier, t_.Percentages.Premier)
+		assert.Equal(t, fx.CreateTierDiscount.From, t_.From)
 	})
 }
 
@@ -124,6 +127,110 @@ func (td *TierDiscountTests) TestRemoveTierDiscountFromPolicy() {
 	})
 }
 
+func (td *TierDiscountTests) TestListTierDiscountsPagination() {
+	rapid.Check(td.T(), func(t *rapid.T) {
+		td.cleanUp()
+		fx := genListTierDiscounts().Draw(t, "fx")
+		td.clock.Current = fx.Clock
+		for _, create := range fx.CreateTierDiscounts {
+			_, err := td.dispatcher.CreateTierDiscount(td.ctx, create)
+			require.NoError(t, err)
+		}
+		creates := slices.Clone(fx.CreateTierDiscounts)
+		slices.SortFunc(creates, func(a, b core.CreateTierDiscountCommand) int { return a.From.Compare(b.From) })
+		var expected []uuid.UUID
+		for _, create := range creates {
+			expected = append(expected, create.ID)
+		}
+
+		var actual []uuid.UUID
+		qry := core.ListTierDiscountsQuery{Limit: fx.Limit}
+		for {
+			res, err := td.dispatcher.ListTierDiscounts(td.ctx, qry)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(res.Items), fx.Limit)
+			for _, t_ := range res.Items {
+				actual = append(actual, t_.ID)
+			}
+			if res.NextCursor == "" {
+				break
+			}
+			qry.Cursor = res.NextCursor
+		}
+
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func (td *TierDiscountTests) TestGetEffectiveTierDiscount() {
+	rapid.Check(td.T(), func(t *rapid.T) {
+		td.cleanUp()
+		fx := genTierDiscountTimeline().Draw(t, "fx")
+		td.clock.Current = fx.Clock
+		var expected *core.CreateTierDiscountCommand
+		for i, create := range fx.CreateTierDiscounts {
+			_, err := td.dispatcher.CreateTierDiscount(td.ctx, create)
+			require.NoError(t, err)
+			if !create.From.After(fx.On) && (expected == nil || create.From.After(expected.From)) {
+				expected = &fx.CreateTierDiscounts[i]
+			}
+		}
+
+		t_, err := td.dispatcher.GetEffectiveTierDiscount(td.ctx, core.GetEffectiveTierDiscountQuery{On: fx.On})
+
+		if expected == nil {
+			var e *core.NoTierDiscountError
+			require.ErrorAs(t, err, &e)
+			assert.Equal(t, fx.On, e.On)
+			return
+		}
+		require.NoError(t, err)
+		assert.Equal(t, expected.ID, t_.ID)
+		assert.Equal(t, expected.From, t_.From)
+	})
+}
+
+func (td *TierDiscountTests) TestGetTierDiscountTimeline() {
+	rapid.Check(td.T(), func(t *rapid.T) {
+		td.cleanUp()
+		fx := genTierDiscountTimeline().Draw(t, "fx")
+		td.clock.Current = fx.Clock
+		for _, create := range fx.CreateTierDiscounts {
+			_, err := td.dispatcher.CreateTierDiscount(td.ctx, create)
+			require.NoError(t, err)
+		}
+
+		res, err := td.dispatcher.GetTierDiscountTimeline(td.ctx, core.GetTierDiscountTimelineQuery{RequiredFrom: &fx.On})
+		require.NoError(t, err)
+
+		// Every tier discount is from after today, so all periods are future
+		// and contiguous by from.
+		creates := slices.Clone(fx.CreateTierDiscounts)
+		slices.SortFunc(creates, func(a, b core.CreateTierDiscountCommand) int { return a.From.Compare(b.From) })
+		require.Len(t, res.Periods, len(creates))
+		for i, create := range creates {
+			p := res.Periods[i]
+			assert.Equal(t, create.ID, p.TierDiscountID)
+			assert.Equal(t, create.From, p.From)
+			assert.Equal(t, core.PeriodFuture, p.Status)
+			if i < len(creates)-1 {
+				require.NotNil(t, p.To)
+				assert.Equal(t, creates[i+1].From, *p.To)
+			} else {
+				assert.Nil(t, p.To)
+			}
+		}
+
+		if fx.On.Before(creates[0].From) {
+			require.Len(t, res.Gaps, 1)
+			assert.Equal(t, fx.On, res.Gaps[0].From)
+			assert.Equal(t, &creates[0].From, res.Gaps[0].To)
+		} else {
+			assert.Empty(t, res.Gaps)
+		}
+	})
+}
+
 func TestTierDiscount(t *testing.T) {
 	suite.Run(t, new(TierDiscountTests))
 }
@@ -0,0 +1,188 @@
+package tiering_test
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"pgregory.net/rapid"
+)
+
+func genCountryCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CountryCodes, strings.Compare)
+}
+
+func genCurrencyCode() *rapid.Generator[string] {
+	return testutil.GenMapKey(core.CurrencyCodes, strings.Compare)
+}
+
+// genRevenueLimits draws limits in the range of the cluster's net revenue so
+// that every tier, and no tier, is reached. Limits have at most the minor
+// units of the revenue group's currency.
+func genRevenueLimits(currencyCode string) *rapid.Generator[core.RevenueLimitsInput] {
+	places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+	return rapid.Custom(func(t *rapid.T) core.RevenueLimitsInput {
+		limit := testutil.GenDecimalBetween(core.NewDecimalFromInt(0), core.NewDecimalFromInt(300_000), places)
+		limits := rapid.SliceOfNDistinct(limit, 3, 3, core.Decimal.String).Draw(t, "limits")
+		slices.SortFunc(limits, core.Decimal.Cmp)
+		return core.RevenueLimitsInput{
+			Authorized: limits[0],
+			Advanced:   limits[1],
+			Premier:    limits[2],
+		}
+	})
+}
+
+func beforeYearEnd(c core.Clock) bool {
+	today := c.Today()
+	return today.Month() != 12 || today.Day() != 31
+}
+
+type TierClustersFixture struct {
+	Clock                 core.Clock
+	CurrencyCode          string
+	CreateRevenueGroup    core.CreateRevenueGroupCommand
+	AddRevenueGroupLimit  core.AddRevenueGroupLimitCommand
+	CreateProductGroup    core.CreateProductGroupCommand
+	AddProductGroupWeight core.AddProductGroupWeightCommand
+	CreateProduct         core.CreateProductCommand
+	EnrollResellers       []core.EnrollResellerCommand
+	CreateCluster         core.CreateClusterCommand
+	TieringClock          core.Clock
+	RecordBillings        []core.RecordBillingCommand
+}
+
+// genTierClusters sets up a cluster of resellers billed in the revenue group's
+// currency. Limits and a product group weighing 100 percent must be from after
+// today, so billings are recorded and clusters tiered on a later clock, within
+// the year billings are booked and the cluster is created, as a cluster isn't
+// tiered in a year it's yet to be rolled over into.
+func genTierClusters() *rapid.Generator[TierClustersFixture] {
+	return rapid.Custom(func(t *rapid.T) TierClustersFixture {
+		clock := testutil.GenFakeClock().Filter(beforeYearEnd).Draw(t, "clock")
+		from := clock.Today().AddDate(0, 0, 1)
+		countryCode := genCountryCode().Draw(t, "country_code")
+		currencyCode := genCurrencyCode().Draw(t, "currency_code")
+
+		createRevenueGroup := core.CreateRevenueGroupCommand{
+			ID:           testutil.GenUUID().Draw(t, "revenue_group_id"),
+			CountryCode:  countryCode,
+			CurrencyCode: currencyCode,
+		}
+		addLimit := core.AddRevenueGroupLimitCommand{
+			ID:          testutil.GenUUID().Draw(t, "revenue_group_limit_id"),
+			CountryCode: countryCode,
+			Limits:      genRevenueLimits(currencyCode).Draw(t, "limits"),
+			From:        from,
+		}
+
+		createProductGroup := core.CreateProductGroupCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_group_id"),
+			Code: testutil.GenCode(core.ProductGroupCodeLengthMin, core.ProductGroupCodeLengthMax).Draw(t, "product_group_code"),
+		}
+		addWeight := core.AddProductGroupWeightCommand{
+			ID:         testutil.GenUUID().Draw(t, "product_group_weight_id"),
+			Code:       createProductGroup.Code,
+			Percentage: core.NewDecimalFromInt(100),
+			From:       from,
+		}
+		createProduct := core.CreateProductCommand{
+			ID:   testutil.GenUUID().Draw(t, "product_id"),
+			Code: testutil.GenCode(core.ProductCodeLengthMin, core.ProductCodeLengthMax).Draw(t, "product_code"),
+		}
+
+		ids := rapid.SliceOfNDistinct(testutil.GenUUID(), 1, 3, rapid.ID).Draw(t, "reseller_ids")
+		enrolls := make([]core.EnrollResellerCommand, len(ids))
+		for i, id := range ids {
+			enrolls[i] = core.EnrollResellerCommand{
+				ID:           id,
+				ExternalID:   testutil.GenUUID().Draw(t, "external_id"),
+				CountryCode:  countryCode,
+				CurrencyCode: currencyCode,
+				EnrolledAt:   testutil.GenDateBetween(core.ResellerEnrolledAtMin, core.ResellerEnrolledAtMax).Draw(t, "enrolled_at"),
+			}
+		}
+		createCluster := core.CreateClusterCommand{
+			ID:             testutil.GenUUID().Draw(t, "cluster_id"),
+			ExternalID:     testutil.GenUUID().Draw(t, "cluster_external_id"),
+			RevenueGroupID: createRevenueGroup.ID,
+			HeadResellerID: enrolls[0].ID,
+		}
+
+		yearEnd := core.NewDate(from.Year(), 12, 31)
+		tierAt := from.AddDate(0, 0, rapid.IntRange(0, from.DaysBetween(yearEnd)).Draw(t, "tier_after"))
+		tieringClock := &testutil.FakeClock{Now: tierAt.Time.Add(12 * time.Hour)}
+
+		places := core.MustParseCurrencyCode(currencyCode).MinorUnits()
+		documentNumbers := rapid.SliceOfNDistinct(testutil.GenCode(core.DocumentNumberLengthMin, core.DocumentNumberLengthMax), len(enrolls), len(enrolls), rapid.ID).
+			Draw(t, "document_numbers")
+		records := make([]core.RecordBillingCommand, len(enrolls))
+		for i, enroll := range enrolls {
+			records[i] = core.RecordBillingCommand{
+				ID:                 testutil.GenUUID().Draw(t, "billing_id"),
+				ResellerExternalID: enroll.ExternalID,
+				DocumentNumber:     documentNumbers[i],
+				BookedAt:           testutil.GenDateBetween(from, tierAt).Draw(t, "booked_at"),
+				Kind:               string(core.ResellerBillingKindInvoice),
+				CurrencyCode:       currencyCode,
+				Items: []core.RecordBillingItemInput{{
+					ID:           testutil.GenUUID().Draw(t, "item_id"),
+					ProductCode:  createProduct.Code,
+					GrossRevenue: testutil.GenDecimalBetween(core.ResellerBillingGrossRevenueMin, core.NewDecimalFromInt(150_000), places).Draw(t, "gross_revenue"),
+				}},
+			}
+		}
+
+		return TierClustersFixture{
+			Clock:                 clock,
+			CurrencyCode:          currencyCode,
+			CreateRevenueGroup:    createRevenueGroup,
+			AddRevenueGroupLimit:  addLimit,
+			CreateProductGroup:    createProductGroup,
+			AddProductGroupWeight: addWeight,
+			CreateProduct:         createProduct,
+			EnrollResellers:       enrolls,
+			CreateCluster:         createCluster,
+			TieringClock:          tieringClock,
+			RecordBillings:        records,
+		}
+	})
+}
+
+// genTierClustersEarlyInYear moves a fixture to the second day of its year and
+// to JPY, a currency without minor units, and bills as much as accepted. This
+// is where a linear projection multiplies net revenue the most.
+func genTierClustersEarlyInYear() *rapid.Generator[TierClustersFixture] {
+	return rapid.Custom(func(t *rapid.T) TierClustersFixture {
+		fx := genTierClusters().Draw(t, "fx")
+		year := fx.Clock.Today().Year()
+		from := core.NewDate(year, 1, 2)
+		currencyCode := "JPY"
+		fx.Clock = &testutil.FakeClock{Now: core.NewDate(year, 1, 1).Time.Add(12 * time.Hour)}
+		fx.TieringClock = &testutil.FakeClock{Now: from.Time.Add(12 * time.Hour)}
+		fx.CurrencyCode = currencyCode
+		fx.CreateRevenueGroup.CurrencyCode = currencyCode
+		fx.AddRevenueGroupLimit.Limits = genRevenueLimits(currencyCode).Draw(t, "limits")
+		fx.AddRevenueGroupLimit.From = from
+		fx.AddProductGroupWeight.From = from
+		for i := range fx.EnrollResellers {
+			fx.EnrollResellers[i].CurrencyCode = currencyCode
+		}
+		for i := range fx.RecordBillings {
+			items := make([]core.RecordBillingItemInput, core.ResellerBillingItemsMax)
+			for j := range items {
+				items[j] = core.RecordBillingItemInput{
+					ID:           testutil.GenUUID().Draw(t, "item_id"),
+					ProductCode:  fx.CreateProduct.Code,
+					GrossRevenue: core.ResellerBillingGrossRevenueMax,
+				}
+			}
+			fx.RecordBillings[i].BookedAt = from
+			fx.RecordBillings[i].CurrencyCode = currencyCode
+			fx.RecordBillings[i].Items = items
+		}
+		return fx
+	})
+}
@@ -0,0 +1,223 @@
+package tiering_test
+
+import (
+	"context"
+	"testing"
+	"uuid"
+
+	"github.com/ronnieholm/resellerloyalty/internal/core"
+	"github.com/ronnieholm/resellerloyalty/internal/infrastructure"
+	"github.com/ronnieholm/resellerloyalty/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"pgregory.net/rapid"
+)
+
+type TieringTests struct {
+	suite.Suite
+	ctx        context.Context
+	config     *infrastructure.Config
+	clock      *testutil.SwitchableClock
+	dispatcher infrastructure.Dispatcher
+}
+
+func (tt *TieringTests) SetupSuite() {
+	tt.ctx = context.Background()
+	tt.config = testutil.LoadConfig()
+	tt.clock = &testutil.SwitchableClock{}
+	tt.dispatcher = infrastructure.NewDispatcher(tt.ctx, *testutil.Config, infrastructure.WithClock(tt.clock))
+}
+
+func (tt *TieringTests) TearDownSuite() {
+	tt.dispatcher.Close()
+}
+
+func (tt *TieringTests) cleanUp() {
+	testutil.ResetDB(tt.ctx, tt.dispatcher.PgxPool)
+}
+
+// setup leaves the clock at the tiering clock. Without a limit, the cluster's
+// revenue group has no limits in effect when tiering.
+func (tt *TieringTests) setup(t *rapid.T, fx TierClustersFixture, addLimit bool) {
+	tt.clock.Current = fx.Clock
+	_, err := tt.dispatcher.CreateCurrency(tt.ctx, core.CreateCurrencyCommand{ID: uuid.New(), Code: fx.CurrencyCode})
+	require.NoError(t, err)
+	_, err = tt.dispatcher.CreateRevenueGroup(tt.ctx, fx.CreateRevenueGroup)
+	require.NoError(t, err)
+	if addLimit {
+		_, err = tt.dispatcher.AddRevenueGroupLimit(tt.ctx, fx.AddRevenueGroupLimit)
+		require.NoError(t, err)
+	}
+
+	_, err = tt.dispatcher.CreateProductGroup(tt.ctx, fx.CreateProductGroup)
+	require.NoError(t, err)
+	_, err = tt.dispatcher.AddProductGroupWeight(tt.ctx, fx.AddProductGroupWeight)
+	require.NoError(t, err)
+	_, err = tt.dispatcher.CreateProduct(tt.ctx, fx.CreateProduct)
+	require.NoError(t, err)
+	assign := core.AssignProductGroupCommand{Code: fx.CreateProduct.Code, ProductGroupCode: fx.CreateProductGroup.Code}
+	_, err = tt.dispatcher.AssignProductGroup(tt.ctx, assign)
+	require.NoError(t, err)
+
+	for _, enroll := range fx.EnrollResellers {
+		_, err := tt.dispatcher.EnrollReseller(tt.ctx, enroll)
+		require.NoError(t, err)
+	}
+	_, err = tt.dispatcher.CreateCluster(tt.ctx, fx.CreateCluster)
+	require.NoError(t, err)
+	for _, enroll := range fx.EnrollResellers[1:] {
+		add := core.AddClusterMemberCommand{ClusterID: fx.CreateCluster.ID, ResellerID: enroll.ID}
+		_, err := tt.dispatcher.AddClusterMember(tt.ctx, add)
+		require.NoError(t, err)
+	}
+
+	tt.clock.Current = fx.TieringClock
+	for _, record := range fx.RecordBillings {
+		_, err := tt.dispatcher.RecordBilling(tt.ctx, record)
+		require.NoError(t, err)
+	}
+}
+
+func expectedTier(limits core.RevenueLimitsInput, netRevenue core.Decimal) *core.ResellerTier {
+	var tier core.ResellerTier
+	switch {
+	case netRevenue.Cmp(limits.Premier) >= 0:
+		tier = core.ResellerTierPremier
+	case netRevenue.Cmp(limits.Advanced) >= 0:
+		tier = core.ResellerTierAdvanced
+	case netRevenue.Cmp(limits.Authorized) >= 0:
+		tier = core.ResellerTierAuthorized
+	default:
+		return nil
+	}
+	return &tier
+}
+
+func (tt *TieringTests) TestTierClustersValid() {
+	rapid.Check(tt.T(), func(t *rapid.T) {
+		tt.cleanUp()
+		fx := genTierClusters().Draw(t, "fx")
+		tt.setup(t, fx, true)
+
+		report, err := tt.dispatcher.TierClusters(tt.ctx, core.TierClustersCommand{ID: uuid.New()})
+		require.NoError(t, err)
+
+		// The test configuration projects linearly, each reseller on its own.
+		tierAt := fx.TieringClock.Today()
+		currencyCode := core.MustParseCurrencyCode(fx.CurrencyCode)
+		netRevenue := core.NewDecimalFromInt(0)
+		projected := core.NewDecimalFromInt(0)
+		for _, record := range fx.RecordBillings {
+			netRevenue = netRevenue.Add(record.Items[0].GrossRevenue)
+			p := core.ProjectLinear(core.NewMoney(record.Items[0].GrossRevenue, currencyCode), tierAt)
+			projected = projected.Add(p.Amount)
+		}
+		tier := expectedTier(fx.AddRevenueGroupLimit.Limits, netRevenue)
+		projectedTier := expectedTier(fx.AddRevenueGroupLimit.Limits, projected)
+		assert.True(t, report.Recorded)
+		assert.Equal(t, tierAt, report.TierAt)
+		assert.Empty(t, report.Failed)
+		require.Len(t, report.Tiered, 1)
+		assert.Equal(t, fx.CreateCluster.ID, report.Tiered[0].ClusterID)
+		assert.Zero(t, netRevenue.Cmp(report.Tiered[0].CalculatedNetRevenueYearToDate.Amount))
+		assert.Equal(t, tier, report.Tiered[0].CalculatedResellerTierYearToDate)
+		// Without net revenue last year, there's no minimum tier.
+		assert.Nil(t, report.Tiered[0].CalculatedResellerTierMinimum)
+		assert.Equal(t, tier, report.Tiered[0].ResellerTier)
+		assert.Equal(t, core.TierBasisYearToDate, report.Tiered[0].ResellerTierBasis)
+		assert.Zero(t, projected.Cmp(report.Tiered[0].CalculatedNetRevenueProjected.Amount))
+		assert.Equal(t, projectedTier, report.Tiered[0].CalculatedResellerTierProjected)
+
+		c, err := tt.dispatcher.GetCluster(tt.ctx, core.GetClusterQuery{ID: fx.CreateCluster.ID})
+		require.NoError(t, err)
+		require.NotNil(t, c.CalculatedNetRevenueYearToDate)
+		assert.Zero(t, netRevenue.Cmp(c.CalculatedNetRevenueYearToDate.Amount))
+		assert.Equal(t, fx.CurrencyCode, c.CalculatedNetRevenueYearToDate.Code)
+		assert.Equal(t, tier, c.CalculatedResellerTierYearToDate)
+		assert.Equal(t, tier, c.ResellerTier)
+		assert.Equal(t, core.TierBasisYearToDate, c.ResellerTierBasis)
+		require.NotNil(t, c.LastTieredAt)
+		assert.Equal(t, tierAt, *c.LastTieredAt)
+
+		p, err := tt.dispatcher.GetClusterTierProjection(tt.ctx, core.GetClusterTierProjectionQuery{ClusterID: fx.CreateCluster.ID})
+		require.NoError(t, err)
+		require.NotNil(t, p.ProjectedAt)
+		assert.Equal(t, tierAt, *p.ProjectedAt)
+		assert.Equal(t, tier, p.CalculatedResellerTierYearToDate)
+		require.NotNil(t, p.CalculatedNetRevenueProjected)
+		assert.Zero(t, projected.Cmp(p.CalculatedNetRevenueProjected.Amount))
+		assert.Equal(t, projectedTier, p.CalculatedResellerTierProjected)
+	})
+}
+
+func (tt *TieringTests) TestTierClustersEarlyInYearValid() {
+	rapid.Check(tt.T(), func(t *rapid.T) {
+		tt.cleanUp()
+		fx := genTierClustersEarlyInYear().Draw(t, "fx")
+		tt.setup(t, fx, true)
+
+		report, err := tt.dispatcher.TierClusters(tt.ctx, core.TierClustersCommand{ID: uuid.New()})
+		require.NoError(t, err)
+
+		tierAt := fx.TieringClock.Today()
+		currencyCode := core.MustParseCurrencyCode(fx.CurrencyCode)
+		projected := core.NewDecimalFromInt(0)
+		for _, record := range fx.RecordBillings {
+			netRevenue := core.NewDecimalFromInt(0)
+			for _, item := range record.Items {
+				netRevenue = netRevenue.Add(item.GrossRevenue)
+			}
+			projected = projected.Add(core.ProjectLinear(core.NewMoney(netRevenue, currencyCode), tierAt).Amount)
+		}
+		assert.True(t, report.Recorded)
+		assert.Empty(t, report.Failed)
+		p, err := tt.dispatcher.GetClusterTierProjection(tt.ctx, core.GetClusterTierProjectionQuery{ClusterID: fx.CreateCluster.ID})
+		require.NoError(t, err)
+		require.NotNil(t, p.CalculatedNetRevenueProjected)
+		assert.Zero(t, projected.Cmp(p.CalculatedNetRevenueProjected.Amount))
+	})
+}
+
+func (tt *TieringTests) TestTierClustersTwiceOnSameDateInvalid() {
+	rapid.Check(tt.T(), func(t *rapid.T) {
+		tt.cleanUp()
+		fx := genTierClusters().Draw(t, "fx")
+		tt.setup(t, fx, true)
+		_, err := tt.dispatcher.TierClusters(tt.ctx, core.TierClustersCommand{ID: uuid.New()})
+		require.NoError(t, err)
+
+		_, err = tt.dispatcher.TierClusters(tt.ctx, core.TierClustersCommand{ID: uuid.New()})
+
+		var e *core.ConflictError
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, "Tiering", e.Entity)
+		assert.Equal(t, fx.TieringClock.Today().String(), e.FieldValues["TierAt"])
+	})
+}
+
+func (tt *TieringTests) TestTierClustersWithoutLimitNotRecorded() {
+	rapid.Check(tt.T(), func(t *rapid.T) {
+		tt.cleanUp()
+		fx := genTierClusters().Draw(t, "fx")
+		tt.setup(t, fx, false)
+
+		// As the failed tiering isn't recorded, running it again is allowed.
+		for range 2 {
+			report, err := tt.dispatcher.TierClusters(tt.ctx, core.TierClustersCommand{ID: uuid.New()})
+			require.NoError(t, err)
+			assert.False(t, report.Recorded)
+			assert.Empty(t, report.Tiered)
+			require.Len(t, report.Failed, 1)
+			assert.Equal(t, fx.CreateCluster.ID, report.Failed[0].ClusterID)
+		}
+
+		c, err := tt.dispatcher.GetCluster(tt.ctx, core.GetClusterQuery{ID: fx.CreateCluster.ID})
+		require.NoError(t, err)
+		assert.Nil(t, c.LastTieredAt)
+	})
+}
+
+func TestTiering(t *testing.T) {
+	suite.Run(t, new(TieringTests))
+}